@@ -0,0 +1,702 @@
+package root
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	awseventbridge "github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/ses"
+	awssns "github.com/aws/aws-sdk-go-v2/service/sns"
+	awssqs "github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/pkg/errors"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/bus"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/bus/kafka"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/bus/nats"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/bus/redis"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/digest"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/discord"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/email"
+	internalses "github.com/bilalcaliskan/rss-feed-filterer/internal/announce/email/ses"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/email/smtp"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/eventbridge"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/exec"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/file"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/gotify"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/issue"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/issue/github"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/issue/gitlab"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/jira"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/matrix"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/mattermost"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/ntfy"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/opsgenie"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/pagerduty"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/pushover"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/rocketchat"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/slack"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/sns"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/sqs"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/stdout"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/teams"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/telegram"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/webhook"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/config"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/storage/aws"
+)
+
+// announcerFactory creates the announcer of an entry, the templates and the policy of the entry are applied by the
+// caller
+type announcerFactory func(cfg *config.Config, entry config.AnnouncerEntry) (announce.Announcer, error)
+
+// announcerTypes are the factories of the announcer types, keyed by the lowercase type names
+var announcerTypes = map[string]announcerFactory{
+	"slack":       factory(newSlackAnnouncer),
+	"email":       factory(newEmailAnnouncer),
+	"telegram":    factory(newTelegramAnnouncer),
+	"discord":     factory(newDiscordAnnouncer),
+	"teams":       factory(newTeamsAnnouncer),
+	"webhook":     factory(newWebhookAnnouncer),
+	"mattermost":  factory(newMattermostAnnouncer),
+	"rocketchat":  factory(newRocketChatAnnouncer),
+	"matrix":      factory(newMatrixAnnouncer),
+	"ntfy":        factory(newNtfyAnnouncer),
+	"gotify":      factory(newGotifyAnnouncer),
+	"pushover":    factory(newPushoverAnnouncer),
+	"issue":       factory(newIssueAnnouncer),
+	"jira":        factory(newJiraAnnouncer),
+	"pagerduty":   factory(newPagerDutyAnnouncer),
+	"opsgenie":    factory(newOpsgenieAnnouncer),
+	"sns":         factory(newSnsAnnouncer),
+	"sqs":         factory(newSqsAnnouncer),
+	"eventbridge": factory(newEventBridgeAnnouncer),
+	"kafka":       factory(newKafkaAnnouncer),
+	"nats":        factory(newNatsAnnouncer),
+	"redis":       factory(newRedisAnnouncer),
+	"file":        factory(newFileAnnouncer),
+	"stdout":      factory(newStdoutAnnouncer),
+	"exec":        factory(newExecAnnouncer),
+}
+
+// factory decodes the settings of the entry into the config of the announcer type before creating the announcer, so
+// the unknown fields of the entries fail at the startup
+func factory[T any](build func(cfg *config.Config, settings T) (announce.Announcer, error)) announcerFactory {
+	return func(cfg *config.Config, entry config.AnnouncerEntry) (announce.Announcer, error) {
+		var settings T
+		if err := entry.Decode(&settings); err != nil {
+			return nil, err
+		}
+
+		return build(cfg, settings)
+	}
+}
+
+// buildAnnouncers creates the enabled announcers in the config, each wrapped with its own templates and policy. The
+// announcers already created are closed if a later entry fails, they may hold connections to the buses and servers.
+func buildAnnouncers(cfg *config.Config, store digest.Store) ([]announce.Announcer, error) {
+	var announcers []announce.Announcer
+	for i, entry := range cfg.Announcers {
+		announcer, err := buildAnnouncer(cfg, i, entry, store)
+		if err != nil {
+			closeAnnouncers(announcers)
+			return nil, err
+		}
+
+		if announcer != nil {
+			announcers = append(announcers, announcer)
+		}
+	}
+
+	if err := validateSubscriptions(cfg.Repositories, announcers); err != nil {
+		closeAnnouncers(announcers)
+		return nil, err
+	}
+
+	return announcers, nil
+}
+
+// buildAnnouncer creates the announcer of the i-th entry wrapped with its templates and policy, it returns nil if
+// the entry is disabled
+func buildAnnouncer(cfg *config.Config, i int, entry config.AnnouncerEntry, store digest.Store) (announce.Announcer, error) {
+	common, err := entry.Common()
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid announcer %d", i)
+	}
+
+	if !common.Enabled {
+		return nil, nil
+	}
+
+	kind := strings.ToLower(entry.Type)
+	create, ok := announcerTypes[kind]
+	if !ok {
+		return nil, errors.Errorf("announcer %d has unknown type %q", i, entry.Type)
+	}
+
+	name := common.Name
+	if name == "" {
+		name = kind
+	}
+
+	announcer, err := create(cfg, entry)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create %s announcer", name)
+	}
+
+	templated, err := withTemplates(announcer, common.Templates)
+	if err != nil {
+		_ = announce.Close(announcer)
+		return nil, errors.Wrapf(err, "invalid %s announcer templates", name)
+	}
+
+	wrapped, err := withPolicy(templated, kind, name, common.Policy, store)
+	if err != nil {
+		_ = announce.Close(announcer)
+		return nil, errors.Wrapf(err, "invalid %s announcer policy", name)
+	}
+
+	return wrapped, nil
+}
+
+// closeAnnouncers closes the announcers, the errors are ignored since they are only closed on a failure
+func closeAnnouncers(announcers []announce.Announcer) {
+	for _, announcer := range announcers {
+		_ = announce.Close(announcer)
+	}
+}
+
+// newSlackAnnouncer creates the slack announcer of the settings
+func newSlackAnnouncer(cfg *config.Config, settings config.Slack) (announce.Announcer, error) {
+	return slack.NewSlackAnnouncer(settings.WebhookUrl, settings.Username, settings.IconUrl, &slack.SlackService{}), nil
+}
+
+// newEmailAnnouncer creates the email announcer of the settings
+func newEmailAnnouncer(cfg *config.Config, settings config.Email) (announce.Announcer, error) {
+	// type is the sender in the announcer block of the previous versions, the announcer entries use it for their type
+	senderType := settings.Sender
+	if senderType == "" {
+		senderType = settings.Type
+	}
+
+	var sender email.Sender
+	switch senderType {
+	case "ses":
+		awsCfg, err := aws.CreateConfig(settings.AccessKey, settings.SecretKey, settings.Region)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create aws config")
+		}
+
+		sender = internalses.NewSESSender(ses.NewFromConfig(awsCfg))
+	case "smtp":
+		smtpSender, err := smtp.NewSMTPSender(settings.Host, settings.Port, settings.Username, settings.Password,
+			settings.Smtp.Security, settings.Smtp.Auth)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create smtp sender")
+		}
+
+		sender = smtpSender
+	default:
+		return nil, errors.Errorf("unknown email sender %q", senderType)
+	}
+
+	announcer, err := email.NewEmailAnnouncer(sender, settings.From, settings.To, settings.Cc,
+		settings.Bcc, settings.TextTemplate, settings.HtmlTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	return announcer, nil
+}
+
+// newTelegramAnnouncer creates the telegram announcer of the settings
+func newTelegramAnnouncer(cfg *config.Config, settings config.Telegram) (announce.Announcer, error) {
+	var chats []telegram.Chat
+	for _, chat := range settings.Chats {
+		chats = append(chats, telegram.Chat{Id: chat.Id, ThreadId: chat.ThreadId})
+	}
+
+	announcer, err := telegram.NewTelegramAnnouncer(settings.ApiUrl, settings.BotToken, settings.ParseMode, chats)
+	if err != nil {
+		return nil, err
+	}
+
+	return announcer, nil
+}
+
+// newDiscordAnnouncer creates the discord announcer of the settings
+func newDiscordAnnouncer(cfg *config.Config, settings config.Discord) (announce.Announcer, error) {
+	announcer, err := discord.NewDiscordAnnouncer(settings.WebhookUrl, settings.Username, settings.AvatarUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	return announcer, nil
+}
+
+// newTeamsAnnouncer creates the teams announcer of the settings
+func newTeamsAnnouncer(cfg *config.Config, settings config.Teams) (announce.Announcer, error) {
+	announcer, err := teams.NewTeamsAnnouncer(settings.WebhookUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	return announcer, nil
+}
+
+// newWebhookAnnouncer creates the webhook announcer of the settings
+func newWebhookAnnouncer(cfg *config.Config, settings config.Webhook) (announce.Announcer, error) {
+	format, err := announce.ParseEventFormat(settings.Format)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid format")
+	}
+
+	announcer, err := webhook.NewWebhookAnnouncer(settings.Url, settings.Method,
+		settings.Headers, settings.Template, settings.Secret, settings.Timeout,
+		format)
+	if err != nil {
+		return nil, err
+	}
+
+	return announcer, nil
+}
+
+// newMattermostAnnouncer creates the mattermost announcer of the settings
+func newMattermostAnnouncer(cfg *config.Config, settings config.Mattermost) (announce.Announcer, error) {
+	announcer, err := mattermost.NewMattermostAnnouncer(settings.WebhookUrl, settings.Channel,
+		settings.Username, settings.IconUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	return announcer, nil
+}
+
+// newRocketChatAnnouncer creates the rocketchat announcer of the settings
+func newRocketChatAnnouncer(cfg *config.Config, settings config.RocketChat) (announce.Announcer, error) {
+	announcer, err := rocketchat.NewRocketChatAnnouncer(settings.WebhookUrl, settings.Channel,
+		settings.Username, settings.IconUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	return announcer, nil
+}
+
+// newMatrixAnnouncer creates the matrix announcer of the settings
+func newMatrixAnnouncer(cfg *config.Config, settings config.Matrix) (announce.Announcer, error) {
+	announcer, err := matrix.NewMatrixAnnouncer(settings.HomeserverUrl, settings.AccessToken, settings.RoomId)
+	if err != nil {
+		return nil, err
+	}
+
+	return announcer, nil
+}
+
+// newNtfyAnnouncer creates the ntfy announcer of the settings
+func newNtfyAnnouncer(cfg *config.Config, settings config.Ntfy) (announce.Announcer, error) {
+	announcer, err := ntfy.NewNtfyAnnouncer(settings.ServerUrl, settings.Topic, settings.Token,
+		settings.Priority, settings.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	return announcer, nil
+}
+
+// newGotifyAnnouncer creates the gotify announcer of the settings
+func newGotifyAnnouncer(cfg *config.Config, settings config.Gotify) (announce.Announcer, error) {
+	announcer, err := gotify.NewGotifyAnnouncer(settings.ServerUrl, settings.AppToken, settings.Priority)
+	if err != nil {
+		return nil, err
+	}
+
+	return announcer, nil
+}
+
+// newPushoverAnnouncer creates the pushover announcer of the settings
+func newPushoverAnnouncer(cfg *config.Config, settings config.Pushover) (announce.Announcer, error) {
+	announcer, err := pushover.NewPushoverAnnouncer("", settings.AppToken, settings.UserKey, settings.Priority)
+	if err != nil {
+		return nil, err
+	}
+
+	return announcer, nil
+}
+
+// newIssueAnnouncer creates the issue announcer of the settings
+func newIssueAnnouncer(cfg *config.Config, settings config.Issue) (announce.Announcer, error) {
+	var tracker issue.Tracker
+	switch settings.Provider {
+	case "github":
+		token := settings.Token
+		if token == "" {
+			token = cfg.GithubToken
+		}
+
+		githubTracker, err := github.NewGithubTracker(settings.ApiUrl, token, settings.Repository)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create github issue tracker")
+		}
+
+		tracker = githubTracker
+	case "gitlab":
+		gitlabTracker, err := gitlab.NewGitlabTracker(settings.ApiUrl, settings.Token, settings.Repository)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create gitlab issue tracker")
+		}
+
+		tracker = gitlabTracker
+	default:
+		return nil, errors.Errorf("unknown issue provider %q", settings.Provider)
+	}
+
+	announcer, err := issue.NewIssueAnnouncer(tracker, settings.Labels, settings.Assignees, settings.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	return announcer, nil
+}
+
+// newJiraAnnouncer creates the jira announcer of the settings
+func newJiraAnnouncer(cfg *config.Config, settings config.Jira) (announce.Announcer, error) {
+	announcer, err := jira.NewJiraAnnouncer(settings.BaseUrl, settings.Username, settings.Token,
+		settings.ProjectKey, settings.IssueType, settings.Components, settings.Labels,
+		settings.Priorities, settings.Summary, settings.Description, settings.CustomFields)
+	if err != nil {
+		return nil, err
+	}
+
+	return announcer, nil
+}
+
+// newPagerDutyAnnouncer creates the pagerduty announcer of the settings
+func newPagerDutyAnnouncer(cfg *config.Config, settings config.PagerDuty) (announce.Announcer, error) {
+	announcer, err := pagerduty.NewPagerDutyAnnouncer(settings.ApiUrl, settings.RoutingKey,
+		settings.Source, settings.Severities)
+	if err != nil {
+		return nil, err
+	}
+
+	return announcer, nil
+}
+
+// newOpsgenieAnnouncer creates the opsgenie announcer of the settings
+func newOpsgenieAnnouncer(cfg *config.Config, settings config.Opsgenie) (announce.Announcer, error) {
+	announcer, err := opsgenie.NewOpsgenieAnnouncer(settings.ApiUrl, settings.ApiKey,
+		settings.Priorities, settings.Tags, settings.Teams)
+	if err != nil {
+		return nil, err
+	}
+
+	return announcer, nil
+}
+
+// newSnsAnnouncer creates the sns announcer of the settings
+func newSnsAnnouncer(cfg *config.Config, settings config.Sns) (announce.Announcer, error) {
+	format, err := announce.ParseEventFormat(settings.Format)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid format")
+	}
+
+	awsCfg, err := aws.CreateConfig(settings.AccessKey, settings.SecretKey, settings.Region)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create aws config")
+	}
+
+	announcer, err := sns.NewSNSAnnouncer(awssns.NewFromConfig(awsCfg), settings.TopicArn, format)
+	if err != nil {
+		return nil, err
+	}
+
+	return announcer, nil
+}
+
+// newSqsAnnouncer creates the sqs announcer of the settings
+func newSqsAnnouncer(cfg *config.Config, settings config.Sqs) (announce.Announcer, error) {
+	format, err := announce.ParseEventFormat(settings.Format)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid format")
+	}
+
+	awsCfg, err := aws.CreateConfig(settings.AccessKey, settings.SecretKey, settings.Region)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create aws config")
+	}
+
+	announcer, err := sqs.NewSQSAnnouncer(awssqs.NewFromConfig(awsCfg), settings.QueueUrl, format)
+	if err != nil {
+		return nil, err
+	}
+
+	return announcer, nil
+}
+
+// newEventBridgeAnnouncer creates the eventbridge announcer of the settings
+func newEventBridgeAnnouncer(cfg *config.Config, settings config.EventBridge) (announce.Announcer, error) {
+	format, err := announce.ParseEventFormat(settings.Format)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid format")
+	}
+
+	awsCfg, err := aws.CreateConfig(settings.AccessKey, settings.SecretKey, settings.Region)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create aws config")
+	}
+
+	announcer, err := eventbridge.NewEventBridgeAnnouncer(awseventbridge.NewFromConfig(awsCfg),
+		settings.EventBusName, settings.Source, format)
+	if err != nil {
+		return nil, err
+	}
+
+	return announcer, nil
+}
+
+// newKafkaAnnouncer creates the kafka announcer of the settings
+func newKafkaAnnouncer(cfg *config.Config, settings config.Kafka) (announce.Announcer, error) {
+	format, err := announce.ParseEventFormat(settings.Format)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid format")
+	}
+
+	writer, err := kafka.NewKafkaWriter(settings.Brokers, settings.Tls, settings.SaslMechanism,
+		settings.Username, settings.Password)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create kafka writer")
+	}
+
+	announcer, err := bus.NewBusAnnouncer(kafka.NewKafkaPublisher(writer), settings.Topic, format)
+	if err != nil {
+		_ = writer.Close()
+		return nil, err
+	}
+
+	return announcer, nil
+}
+
+// newNatsAnnouncer creates the nats announcer of the settings
+func newNatsAnnouncer(cfg *config.Config, settings config.Nats) (announce.Announcer, error) {
+	format, err := announce.ParseEventFormat(settings.Format)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid format")
+	}
+
+	conn, err := nats.NewNatsConn(settings.Url, settings.Token, settings.Username,
+		settings.Password, settings.CredentialsFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to nats")
+	}
+
+	announcer, err := bus.NewBusAnnouncer(nats.NewNatsPublisher(conn), settings.Subject, format)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return announcer, nil
+}
+
+// newRedisAnnouncer creates the redis announcer of the settings
+func newRedisAnnouncer(cfg *config.Config, settings config.Redis) (announce.Announcer, error) {
+	client := redis.NewRedisClient(settings.Addr, settings.Username, settings.Password, settings.Db, settings.Tls)
+
+	format, err := announce.ParseEventFormat(settings.Format)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid format")
+	}
+
+	announcer, err := bus.NewBusAnnouncer(redis.NewRedisPublisher(client, settings.MaxLen), settings.Stream, format)
+	if err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+
+	return announcer, nil
+}
+
+// newFileAnnouncer creates the file announcer of the settings
+func newFileAnnouncer(cfg *config.Config, settings config.File) (announce.Announcer, error) {
+	format, err := announce.ParseEventFormat(settings.Format)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid format")
+	}
+
+	announcer, err := file.NewFileAnnouncer(settings.Path, format, settings.MaxSizeMb*1024*1024, settings.MaxBackups)
+	if err != nil {
+		return nil, err
+	}
+
+	return announcer, nil
+}
+
+// newStdoutAnnouncer creates the stdout announcer of the settings
+func newStdoutAnnouncer(cfg *config.Config, settings config.Stdout) (announce.Announcer, error) {
+	announcer, err := stdout.NewStdoutAnnouncer(settings.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	return announcer, nil
+}
+
+// newExecAnnouncer creates the exec announcer of the settings
+func newExecAnnouncer(cfg *config.Config, settings config.Exec) (announce.Announcer, error) {
+	format, err := announce.ParseEventFormat(settings.Format)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid format")
+	}
+
+	announcer, err := exec.NewExecAnnouncer(settings.Command, format, settings.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return announcer, nil
+}
+
+// validateSubscriptions checks if the names of the announcers are unique and the repositories reference the enabled
+// announcers, so a typo in a name does not silently drop the releases of a repository
+func validateSubscriptions(repositories []config.Repository, announcers []announce.Announcer) error {
+	names := make(map[string]bool)
+	for _, announcer := range announcers {
+		policy, ok := announcer.(*announce.PolicyAnnouncer)
+		if !ok {
+			continue
+		}
+
+		if names[policy.Name] {
+			return errors.Errorf("announcer name %q is used more than once", policy.Name)
+		}
+
+		names[policy.Name] = true
+	}
+
+	for _, repo := range repositories {
+		for _, name := range repo.Announcers {
+			if !names[name] {
+				return errors.Errorf("repository %s references unknown or disabled announcer %q", repo.Name, name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// withTemplates wraps the announcer with the message templates defined in the config, the announcer is returned as is
+// if no templates are defined
+func withTemplates(announcer announce.Announcer, cfg config.Templates) (announce.Announcer, error) {
+	if cfg.Message == "" && cfg.Subject == "" && len(cfg.Overrides) == 0 {
+		return announcer, nil
+	}
+
+	var templates announce.Templates
+	var err error
+	if templates.Message, err = parseTemplate("message", cfg.Message); err != nil {
+		return nil, err
+	}
+
+	if templates.Subject, err = parseTemplate("subject", cfg.Subject); err != nil {
+		return nil, err
+	}
+
+	for i, o := range cfg.Overrides {
+		if len(o.Repositories) == 0 {
+			return nil, errors.Errorf("template override %d has no repositories", i)
+		}
+
+		override := announce.TemplateOverride{Repositories: o.Repositories}
+		if override.Message, err = parseTemplate(fmt.Sprintf("overrides[%d].message", i), o.Message); err != nil {
+			return nil, err
+		}
+
+		if override.Subject, err = parseTemplate(fmt.Sprintf("overrides[%d].subject", i), o.Subject); err != nil {
+			return nil, err
+		}
+
+		templates.Overrides = append(templates.Overrides, override)
+	}
+
+	return announce.NewTemplateAnnouncer(announcer, templates), nil
+}
+
+// parseTemplate parses the template if it is defined, the name is the config key of the template shown in the errors
+func parseTemplate(name, text string) (*template.Template, error) {
+	if text == "" {
+		return nil, nil
+	}
+
+	tmpl, err := announce.ParseTemplate(name, text)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s template", name)
+	}
+
+	return tmpl, nil
+}
+
+// digestAnnouncers are the announcers which are able to send the release digests, the event based ones deliver each
+// release to the systems which expect a single release per event
+var digestAnnouncers = map[string]bool{
+	"slack":      true,
+	"email":      true,
+	"telegram":   true,
+	"discord":    true,
+	"teams":      true,
+	"mattermost": true,
+	"rocketchat": true,
+	"matrix":     true,
+	"ntfy":       true,
+	"gotify":     true,
+	"pushover":   true,
+}
+
+// withPolicy wraps the announcer with the policy defined in the config, the announcer is wrapped with a digest
+// first if a digest schedule is defined, so the policy decides the releases to be added to the digest
+func withPolicy(announcer announce.Announcer, kind, name string, cfg config.Policy, store digest.Store) (announce.Announcer, error) {
+	if cfg.Digest.Schedule != "" {
+		if !digestAnnouncers[kind] {
+			return nil, fmt.Errorf("digest is not supported by the %s announcer", kind)
+		}
+
+		schedule, err := digest.ParseSchedule(cfg.Digest.Schedule, cfg.Digest.At, cfg.Digest.Weekday, cfg.Digest.Timezone)
+		if err != nil {
+			return nil, err
+		}
+
+		announcer = digest.NewDigestAnnouncer(announcer, name, schedule, store)
+	}
+
+	events, err := announce.ParseEvents(cfg.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := announce.Policy{
+		Name:               name,
+		Events:             events,
+		SecurityOnly:       cfg.SecurityOnly,
+		HasBreakingChanges: cfg.HasBreakingChanges,
+		Repositories:       cfg.Repositories,
+		Tags:               cfg.Tags,
+	}
+
+	for _, r := range cfg.Routes {
+		priority, err := announce.ParsePriority(r.Priority)
+		if err != nil {
+			return nil, err
+		}
+
+		policy.Routes = append(policy.Routes, announce.Route{
+			Security:           r.Security,
+			HasBreakingChanges: r.HasBreakingChanges,
+			Repositories:       r.Repositories,
+			Priority:           priority,
+			Recipients:         r.Recipients,
+			Immediate:          r.Immediate,
+		})
+	}
+
+	return announce.NewPolicyAnnouncer(announcer, policy), nil
+}
@@ -0,0 +1,148 @@
+//go:build unit
+
+package root
+
+import (
+	"testing"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildAnnouncers(t *testing.T) {
+	slack := func(settings map[string]any) config.AnnouncerEntry {
+		settings["webhookUrl"] = "https://hooks.slack.com/services/T000/B000/XXXX"
+		return config.AnnouncerEntry{Type: "slack", Settings: settings}
+	}
+
+	cases := []struct {
+		caseName      string
+		announcers    []config.AnnouncerEntry
+		repositories  []config.Repository
+		expectedNames []string
+		expectedErr   string
+	}{
+		{
+			"Name defaults to the type",
+			[]config.AnnouncerEntry{
+				{Type: "Stdout", Settings: map[string]any{"enabled": true}},
+				slack(map[string]any{"enabled": true, "name": "platform-team"}),
+			},
+			nil,
+			[]string{"stdout", "platform-team"},
+			"",
+		},
+		{
+			"Disabled announcer is skipped",
+			[]config.AnnouncerEntry{
+				slack(map[string]any{"enabled": false, "name": "platform-team"}),
+				slack(map[string]any{"enabled": true, "name": "security-team"}),
+			},
+			nil,
+			[]string{"security-team"},
+			"",
+		},
+		{
+			"Repository references the named announcer",
+			[]config.AnnouncerEntry{slack(map[string]any{"enabled": true, "name": "platform-team"})},
+			[]config.Repository{{Name: "terraform", Announcers: []string{"platform-team"}}},
+			[]string{"platform-team"},
+			"",
+		},
+		{
+			"Repository references an unknown announcer",
+			[]config.AnnouncerEntry{slack(map[string]any{"enabled": true, "name": "platform-team"})},
+			[]config.Repository{{Name: "terraform", Announcers: []string{"security-team"}}},
+			nil,
+			`repository terraform references unknown or disabled announcer "security-team"`,
+		},
+		{
+			"Repository references a disabled announcer",
+			[]config.AnnouncerEntry{
+				slack(map[string]any{"enabled": true, "name": "platform-team"}),
+				slack(map[string]any{"enabled": false, "name": "security-team"}),
+			},
+			[]config.Repository{{Name: "terraform", Announcers: []string{"platform-team", "security-team"}}},
+			nil,
+			`repository terraform references unknown or disabled announcer "security-team"`,
+		},
+		{
+			"Repository references the type of a named announcer",
+			[]config.AnnouncerEntry{slack(map[string]any{"enabled": true, "name": "platform-team"})},
+			[]config.Repository{{Name: "terraform", Announcers: []string{"slack"}}},
+			nil,
+			`repository terraform references unknown or disabled announcer "slack"`,
+		},
+		{
+			"Defaulted names collide",
+			[]config.AnnouncerEntry{
+				slack(map[string]any{"enabled": true}),
+				slack(map[string]any{"enabled": true}),
+			},
+			nil,
+			nil,
+			`announcer name "slack" is used more than once`,
+		},
+		{
+			"Unknown type",
+			[]config.AnnouncerEntry{{Type: "carrier-pigeon", Settings: map[string]any{"enabled": true}}},
+			nil,
+			nil,
+			`announcer 0 has unknown type "carrier-pigeon"`,
+		},
+		{
+			"Unknown field",
+			[]config.AnnouncerEntry{slack(map[string]any{"enabled": true, "channel": "#releases"})},
+			nil,
+			nil,
+			"failed to create slack announcer",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		announcers, err := buildAnnouncers(&config.Config{Announcers: tc.announcers, Repositories: tc.repositories}, nil)
+		if tc.expectedErr != "" {
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), tc.expectedErr)
+			continue
+		}
+
+		assert.Nil(t, err)
+
+		var names []string
+		for _, announcer := range announcers {
+			names = append(names, announcer.(*announce.PolicyAnnouncer).Name)
+		}
+
+		assert.Equal(t, tc.expectedNames, names)
+	}
+}
+
+// closingAnnouncer records if it is closed
+type closingAnnouncer struct {
+	announce.NoopAnnouncer
+	closed bool
+}
+
+func (c *closingAnnouncer) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestBuildAnnouncers_closesOnFailure(t *testing.T) {
+	closing := &closingAnnouncer{}
+	announcerTypes["closing"] = func(cfg *config.Config, entry config.AnnouncerEntry) (announce.Announcer, error) {
+		return closing, nil
+	}
+	defer delete(announcerTypes, "closing")
+
+	_, err := buildAnnouncers(&config.Config{Announcers: []config.AnnouncerEntry{
+		{Type: "closing", Settings: map[string]any{"enabled": true}},
+		{Type: "carrier-pigeon", Settings: map[string]any{"enabled": true}},
+	}}, nil)
+	assert.NotNil(t, err)
+	assert.True(t, closing.closed)
+}
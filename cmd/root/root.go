@@ -4,14 +4,9 @@ import (
 	"context"
 	"errors"
 
-	"github.com/aws/aws-sdk-go-v2/service/ses"
-	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/email"
-	internalses "github.com/bilalcaliskan/rss-feed-filterer/internal/announce/email/ses"
-
 	"github.com/bilalcaliskan/rss-feed-filterer/cmd/root/options"
 	"github.com/bilalcaliskan/rss-feed-filterer/cmd/start"
-	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
-	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/slack"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/digest"
 	"github.com/bilalcaliskan/rss-feed-filterer/internal/config"
 	"github.com/bilalcaliskan/rss-feed-filterer/internal/logging"
 	"github.com/bilalcaliskan/rss-feed-filterer/internal/storage/aws"
@@ -57,37 +52,16 @@ mechanism to track multiple project releases.`,
 				logger.Debug().Str("foo", "bar").Msg("this is a dummy log")
 			}
 
-			client, err := aws.CreateClient(cfg.AccessKey, cfg.SecretKey, cfg.Region)
+			client, err := aws.CreateClient(cfg.S3.AccessKey, cfg.S3.SecretKey, cfg.S3.Region)
 			if err != nil {
 				logger.Error().Err(err).Msg("failed to create s3 client")
 				return err
 			}
 
-			var announcers []announce.Announcer
-			if cfg.Announcer.Slack.Enabled {
-				announcer := slack.NewSlackAnnouncer(cfg.Announcer.Slack.WebhookUrl, cfg.Announcer.Slack.Username, cfg.Announcer.Slack.IconUrl, &slack.SlackService{})
-				announcers = append(announcers, announcer)
-			}
-
-			if cfg.Announcer.Email.Enabled {
-				var sender email.Sender
-
-				awsCfg, err := aws.CreateConfig(cfg.Email.AccessKey, cfg.Email.SecretKey, cfg.Email.Region)
-				if err != nil {
-					logger.Error().Err(err).Msg("failed to create aws config")
-					return err
-				}
-
-				if cfg.Announcer.Email.Type == "ses" {
-					sesClient := ses.NewFromConfig(awsCfg)
-					sender = internalses.NewSESSender(sesClient)
-				}
-				//} else if cfg.Announcer.Email.Type == "smtp" {
-				//	// TODO: implement smtp announcer
-				//}
-
-				announcer := email.NewEmailAnnouncer(sender, cfg.Announcer.Email.From, cfg.Announcer.Email.To, cfg.Announcer.Email.Cc, cfg.Announcer.Email.Bcc)
-				announcers = append(announcers, announcer)
+			announcers, err := buildAnnouncers(cfg, digest.NewS3Store(client, cfg.BucketName))
+			if err != nil {
+				logger.Error().Err(err).Msg("failed to create announcers")
+				return err
 			}
 
 			//else if cfg.Announcer.Email.Enabled {
@@ -31,6 +31,21 @@ func TestExecute(t *testing.T) {
 			[]string{"--config-file=../../test/config_email_enabled.yaml"},
 			true,
 		},
+		{
+			"SMTP email enabled config",
+			[]string{"--config-file=../../test/config_smtp_enabled.yaml"},
+			true,
+		},
+		{
+			"Announcers list config",
+			[]string{"--config-file=../../test/config_announcers.yaml"},
+			true,
+		},
+		{
+			"Duplicate announcer names",
+			[]string{"--config-file=../../test/config_announcers_duplicate.yaml"},
+			false,
+		},
 		{
 			"Empty config path",
 			[]string{"--verbose"},
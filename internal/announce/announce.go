@@ -1,14 +1,207 @@
 package announce
 
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/types"
+)
+
+// ErrFiltered is returned by the announcers which skip a payload because of their policy
+var ErrFiltered = errors.New("payload is filtered out by the announcer policy")
+
+// change levels of the payloads, used as the keys of the priority and severity mappings of the announcers
+const (
+	LevelSecurity = "security"
+	LevelBreaking = "breaking"
+	LevelHigh     = "high"
+	LevelNormal   = "normal"
+)
+
 type Announcer interface {
 	Notify(payload *AnnouncerPayload) error
 	IsEnabled() bool
 }
 
+// Close releases the resources of the announcer, like the reused connections, if it or one of the announcers wrapped
+// by it is an io.Closer
+func Close(announcer Announcer) error {
+	if closer, ok := find[io.Closer](announcer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+// find returns the first announcer of the wrapper chain which implements T
+func find[T any](announcer Announcer) (T, bool) {
+	for announcer != nil {
+		if found, ok := announcer.(T); ok {
+			return found, true
+		}
+
+		wrapper, ok := announcer.(interface{ Unwrap() Announcer })
+		if !ok {
+			break
+		}
+
+		announcer = wrapper.Unwrap()
+	}
+
+	var zero T
+	return zero, false
+}
+
 type AnnouncerPayload struct {
 	ProjectName string
 	Version     string
 	URL         string
+	// PublishedAt is the publish time of the release, nil if the feed does not contain it
+	PublishedAt *time.Time
+	// UpdatedAt is the last update time of the release, nil if the feed does not contain it
+	UpdatedAt *time.Time
+	// Event is the kind of change detected on the release, empty value is treated as types.EventNew
+	Event types.EventType
+	// Changes contains the names of the changed fields if the release is updated
+	Changes []string
+	// Notes is the release notes in HTML format, announcers convert it into their own format
+	Notes string
+	// BreakingChanges is the breaking change sections extracted from the release notes in HTML format
+	BreakingChanges string
+	// Security holds the security findings of the release, nil if the release is not security related
+	Security *types.Security
+	// Priority is the urgency of the payload set by the announcer policy, empty value is treated as PriorityNormal
+	Priority Priority
+	// Recipients override the default destination of the announcer if set by the announcer policy
+	Recipients []string
+	// Message is rendered from the message template of the announcer, it replaces the summary if set
+	Message string
+	// Subject is rendered from the subject template of the announcer, it replaces the email subject and the
+	// notification titles if set
+	Subject string
+	// Tags are the tags of the repository of the release, matched against the subscriptions of the announcers
+	Tags []string
+	// Announcers are the names of the announcers the repository of the release is restricted to, all announcers
+	// subscribed to the repository receive it if it is empty
+	Announcers []string
+	// Immediate bypasses the digest of the announcer, set by the announcer policy
+	Immediate bool
+	// Digest holds the batched releases if the payload is a digest of them, Message and Subject summarize them
+	Digest []*AnnouncerPayload
+}
+
+// Release returns the release of the payload
+func (p *AnnouncerPayload) Release() types.Release {
+	return types.Release{
+		ProjectName: p.ProjectName,
+		Version:     p.Version,
+		PublishedAt: p.PublishedAt,
+		UpdatedAt:   p.UpdatedAt,
+		Url:         p.URL,
+		Notes:       p.Notes,
+		Security:    p.Security,
+	}
+}
+
+// GetEvent returns the event type of the payload, defaults to types.EventNew
+func (p *AnnouncerPayload) GetEvent() types.EventType {
+	if p.Event == "" {
+		return types.EventNew
+	}
+
+	return p.Event
+}
+
+// IsDigest checks if the payload is a digest of the batched releases
+func (p *AnnouncerPayload) IsDigest() bool {
+	return len(p.Digest) > 0
+}
+
+// HasBreakingChanges checks if breaking changes are extracted from the release notes
+func (p *AnnouncerPayload) HasBreakingChanges() bool {
+	return p.BreakingChanges != ""
+}
+
+// IsUrgent checks if the payload is routed with high priority
+func (p *AnnouncerPayload) IsUrgent() bool {
+	return p.Priority == PriorityHigh
+}
+
+// Summary returns the one line human-readable message of the payload, or the rendered message template if it is set
+func (p *AnnouncerPayload) Summary() string {
+	if p.Message != "" {
+		return p.Message
+	}
+
+	var summary string
+	switch p.GetEvent() {
+	case types.EventUpdated:
+		summary = fmt.Sprintf("%s %s is updated (%s)! Check it out at %s", p.ProjectName, p.Version, strings.Join(p.Changes, ", "), p.URL)
+	case types.EventRemoved:
+		summary = fmt.Sprintf("%s %s is removed! It was available at %s", p.ProjectName, p.Version, p.URL)
+	default:
+		summary = fmt.Sprintf("%s %s is out! Check it out at %s", p.ProjectName, p.Version, p.URL)
+	}
+
+	if advisories := p.Advisories(); len(advisories) > 0 {
+		summary = fmt.Sprintf("%s Security advisories: %s", summary, strings.Join(advisories, ", "))
+	}
+
+	return summary
+}
+
+// Title returns the rendered subject template if it is set, the given default title otherwise
+func (p *AnnouncerPayload) Title(defaultTitle string) string {
+	if p.Subject != "" {
+		return p.Subject
+	}
+
+	return defaultTitle
+}
+
+// Advisories returns the CVE and GHSA identifiers of the release
+func (p *AnnouncerPayload) Advisories() []string {
+	if p.Security == nil {
+		return nil
+	}
+
+	return append(append([]string{}, p.Security.CVEs...), p.Security.GHSAs...)
+}
+
+// DedupKey returns the key which identifies the release across its events, the alerting systems use it to merge the
+// updated releases into the open alert and to resolve the alert of the removed releases
+func (p *AnnouncerPayload) DedupKey() string {
+	return p.ProjectName + "@" + p.Version
+}
+
+// Level returns the change level of the payload, security releases come first and breaking changes second
+func (p *AnnouncerPayload) Level() string {
+	switch {
+	case p.Security != nil:
+		return LevelSecurity
+	case p.HasBreakingChanges():
+		return LevelBreaking
+	case p.IsUrgent():
+		return LevelHigh
+	default:
+		return LevelNormal
+	}
+}
+
+// ValidateLevels checks if the keys of the mapping are known change levels
+func ValidateLevels[V any](mapping map[string]V) error {
+	for level := range mapping {
+		switch level {
+		case LevelSecurity, LevelBreaking, LevelHigh, LevelNormal:
+		default:
+			return fmt.Errorf("unknown change level %q", level)
+		}
+	}
+
+	return nil
 }
 
 type NoopAnnouncer struct{}
@@ -2,7 +2,12 @@
 
 package announce
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/types"
+	"github.com/stretchr/testify/assert"
+)
 
 func TestNoopAnnouncer_Notify(t *testing.T) {
 	n := &NoopAnnouncer{}
@@ -18,3 +23,66 @@ func TestNoopAnnouncer_IsEnabled(t *testing.T) {
 		t.Fatalf("Expected IsEnabled to return false, but got true")
 	}
 }
+
+// closingAnnouncer records the calls of Close
+type closingAnnouncer struct {
+	recordingAnnouncer
+	closed int
+}
+
+func (c *closingAnnouncer) Close() error {
+	c.closed++
+	return nil
+}
+
+func TestClose(t *testing.T) {
+	closer := &closingAnnouncer{}
+
+	assert.Nil(t, Close(closer))
+	assert.Nil(t, Close(NewPolicyAnnouncer(NewTemplateAnnouncer(closer, Templates{}), Policy{})))
+	assert.Equal(t, 2, closer.closed)
+
+	// announcers without resources are ignored
+	assert.Nil(t, Close(NewPolicyAnnouncer(&recordingAnnouncer{}, Policy{})))
+}
+
+func TestAnnouncerPayload_Summary(t *testing.T) {
+	payload := &AnnouncerPayload{ProjectName: "user1/project1", Version: "v1.0.0", URL: "https://example.com"}
+	if got := payload.Summary(); got != "user1/project1 v1.0.0 is out! Check it out at https://example.com" {
+		t.Fatalf("unexpected summary for new release: %s", got)
+	}
+
+	payload.Event = types.EventUpdated
+	payload.Changes = []string{"url", "updatedAt"}
+	if got := payload.Summary(); got != "user1/project1 v1.0.0 is updated (url, updatedAt)! Check it out at https://example.com" {
+		t.Fatalf("unexpected summary for updated release: %s", got)
+	}
+
+	payload.Event = types.EventRemoved
+	if got := payload.Summary(); got != "user1/project1 v1.0.0 is removed! It was available at https://example.com" {
+		t.Fatalf("unexpected summary for removed release: %s", got)
+	}
+}
+
+func TestAnnouncerPayload_Level(t *testing.T) {
+	cases := []struct {
+		caseName string
+		payload  *AnnouncerPayload
+		expected string
+	}{
+		{"Security", &AnnouncerPayload{Security: &types.Security{CVEs: []string{"CVE-2023-1234"}}, BreakingChanges: "<p>x</p>"}, LevelSecurity},
+		{"Breaking", &AnnouncerPayload{BreakingChanges: "<p>x</p>", Priority: PriorityHigh}, LevelBreaking},
+		{"High", &AnnouncerPayload{Priority: PriorityHigh}, LevelHigh},
+		{"Normal", &AnnouncerPayload{}, LevelNormal},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+		assert.Equal(t, tc.expected, tc.payload.Level())
+	}
+}
+
+func TestValidateLevels(t *testing.T) {
+	assert.Nil(t, ValidateLevels(map[string]string{LevelSecurity: "P1", LevelNormal: "P5"}))
+	assert.NotNil(t, ValidateLevels(map[string]int{"critical": 1}))
+}
@@ -0,0 +1,104 @@
+package bus
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+)
+
+// Message is a release event to be published to a message bus
+type Message struct {
+	// Key is the partitioning and ordering key of the message if the bus supports it
+	Key         string
+	ContentType string
+	// Attributes are the CloudEvents context attributes of the binary mode, publishers send them as headers with the
+	// prefix of their protocol binding
+	Attributes map[string]string
+	Value      []byte
+}
+
+// AttributeNames returns the names of the attributes in order, so the headers are sent in the same order every time
+func (m *Message) AttributeNames() []string {
+	names := make([]string, 0, len(m.Attributes))
+	for name := range m.Attributes {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// Publisher publishes the messages to a message bus like Kafka, NATS or Redis Streams
+type Publisher interface {
+	// Publish publishes the message to the destination which is a topic, subject or stream
+	Publish(destination string, msg *Message) error
+	// Close closes the connection of the publisher to the bus
+	Close() error
+}
+
+// BusAnnouncer is the announcer that publishes the release events to a message bus
+type BusAnnouncer struct {
+	Destination string
+	Format      announce.EventFormat
+	publisher   Publisher
+}
+
+// NewBusAnnouncer creates a new BusAnnouncer which publishes to the destination over the publisher, format defaults
+// to announce.EventFormatJSON
+func NewBusAnnouncer(publisher Publisher, destination string, format announce.EventFormat) (*BusAnnouncer, error) {
+	if publisher == nil || destination == "" {
+		return nil, errors.New("bus publisher and destination are required")
+	}
+
+	if format == "" {
+		format = announce.EventFormatJSON
+	}
+
+	return &BusAnnouncer{
+		Destination: destination,
+		Format:      format,
+		publisher:   publisher,
+	}, nil
+}
+
+// Notify publishes the release event keyed by the project name, so the events of a project stay in order. Routed
+// payloads override the destination with the recipients.
+func (b *BusAnnouncer) Notify(payload *announce.AnnouncerPayload) error {
+	event, err := announce.EncodeEvent(b.Format, payload)
+	if err != nil {
+		return err
+	}
+
+	msg := &Message{
+		Key:         payload.ProjectName,
+		ContentType: event.ContentType,
+		Attributes:  event.Attributes,
+		Value:       event.Body,
+	}
+
+	destinations := payload.Recipients
+	if len(destinations) == 0 {
+		destinations = []string{b.Destination}
+	}
+
+	var errs []error
+	for _, destination := range destinations {
+		if err := b.publisher.Publish(destination, msg); err != nil {
+			errs = append(errs, fmt.Errorf("destination %s: %w", destination, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close closes the publisher of the BusAnnouncer
+func (b *BusAnnouncer) Close() error {
+	return b.publisher.Close()
+}
+
+// IsEnabled checks if the BusAnnouncer is enabled.
+func (b *BusAnnouncer) IsEnabled() bool {
+	return b.publisher != nil && b.Destination != ""
+}
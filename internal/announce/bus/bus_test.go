@@ -0,0 +1,155 @@
+//go:build unit
+
+package bus
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type published struct {
+	destination string
+	msg         *Message
+}
+
+type recordingPublisher struct {
+	messages []published
+	failOn   string
+	closed   bool
+}
+
+func (r *recordingPublisher) Publish(destination string, msg *Message) error {
+	r.messages = append(r.messages, published{destination, msg})
+	if destination == r.failOn {
+		return errors.New("injected error")
+	}
+
+	return nil
+}
+
+func (r *recordingPublisher) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestNewBusAnnouncer(t *testing.T) {
+	announcer, err := NewBusAnnouncer(&recordingPublisher{}, "releases", "")
+	assert.Nil(t, err)
+	assert.Equal(t, announce.EventFormatJSON, announcer.Format)
+	assert.True(t, announcer.IsEnabled())
+
+	_, err = NewBusAnnouncer(&recordingPublisher{}, "", "")
+	assert.NotNil(t, err)
+
+	_, err = NewBusAnnouncer(nil, "releases", "")
+	assert.NotNil(t, err)
+}
+
+func TestBusAnnouncer_Notify(t *testing.T) {
+	cases := []struct {
+		caseName             string
+		failOn               string
+		payload              *announce.AnnouncerPayload
+		expectedDestinations []string
+		shouldPass           bool
+	}{
+		{
+			"Default destination",
+			"",
+			&announce.AnnouncerPayload{ProjectName: "user1/project1", Version: "v1.0.0", URL: "https://example.com"},
+			[]string{"releases"},
+			true,
+		},
+		{
+			"Routed destinations",
+			"",
+			&announce.AnnouncerPayload{ProjectName: "user1/project1", Version: "v1.0.1", URL: "https://example.com",
+				Security: &types.Security{CVEs: []string{"CVE-2023-1234"}}, Recipients: []string{"security", "releases"}},
+			[]string{"security", "releases"},
+			true,
+		},
+		{
+			"Failing destination does not stop the others",
+			"security",
+			&announce.AnnouncerPayload{ProjectName: "user1/project1", Version: "v1.0.1", URL: "https://example.com",
+				Recipients: []string{"security", "releases"}},
+			[]string{"security", "releases"},
+			false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		publisher := &recordingPublisher{failOn: tc.failOn}
+		announcer, err := NewBusAnnouncer(publisher, "releases", "")
+		assert.Nil(t, err)
+
+		err = announcer.Notify(tc.payload)
+		if tc.shouldPass {
+			assert.Nil(t, err)
+		} else {
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), "destination security")
+		}
+
+		assert.Len(t, publisher.messages, len(tc.expectedDestinations))
+		for i, p := range publisher.messages {
+			assert.Equal(t, tc.expectedDestinations[i], p.destination)
+			assert.Equal(t, tc.payload.ProjectName, p.msg.Key)
+			assert.Equal(t, "application/json", p.msg.ContentType)
+			assert.Nil(t, p.msg.Attributes)
+
+			var event map[string]any
+			assert.Nil(t, json.Unmarshal(p.msg.Value, &event))
+			assert.Equal(t, announce.EventSchemaVersion, event["schemaVersion"])
+			assert.Equal(t, tc.payload.ProjectName, event["projectName"])
+			assert.Equal(t, tc.payload.Version, event["version"])
+			assert.Equal(t, string(types.EventNew), event["event"])
+		}
+	}
+}
+
+func TestBusAnnouncer_NotifyCloudEvents(t *testing.T) {
+	payload := &announce.AnnouncerPayload{ProjectName: "user1/project1", Version: "v1.0.0", URL: "https://example.com",
+		Event: types.EventRemoved}
+
+	for _, format := range []announce.EventFormat{announce.EventFormatCloudEvents, announce.EventFormatCloudEventsBinary} {
+		t.Logf("starting case %s", format)
+
+		publisher := &recordingPublisher{}
+		announcer, err := NewBusAnnouncer(publisher, "releases", format)
+		assert.Nil(t, err)
+		assert.Nil(t, announcer.Notify(payload))
+		assert.Len(t, publisher.messages, 1)
+
+		msg := publisher.messages[0].msg
+		assert.Equal(t, "user1/project1", msg.Key)
+		if format == announce.EventFormatCloudEvents {
+			var event announce.CloudEvent
+			assert.Equal(t, announce.CloudEventsContentType, msg.ContentType)
+			assert.Nil(t, json.Unmarshal(msg.Value, &event))
+			assert.Equal(t, announce.CloudEventTypeRemoved, event.Type)
+			continue
+		}
+
+		assert.Equal(t, "application/json", msg.ContentType)
+		assert.Equal(t, announce.CloudEventTypeRemoved, msg.Attributes["type"])
+		assert.Equal(t, []string{"id", "source", "specversion", "subject", "time", "type"}, msg.AttributeNames())
+	}
+}
+
+func TestBusAnnouncer_Close(t *testing.T) {
+	publisher := &recordingPublisher{}
+	announcer, err := NewBusAnnouncer(publisher, "releases", "")
+	assert.Nil(t, err)
+
+	// the announcers are closed through their wrappers
+	assert.Nil(t, announce.Close(announce.NewPolicyAnnouncer(announcer, announce.Policy{})))
+	assert.True(t, publisher.closed)
+}
@@ -0,0 +1,106 @@
+package kafka
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/bus"
+)
+
+const timeout = 10 * time.Second
+
+// Writer is the interface that contains WriteMessages and Close functions.
+// We can mock this interface for testing purposes.
+type Writer interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// KafkaPublisher is the bus.Publisher that produces the messages to the Kafka topics
+type KafkaPublisher struct {
+	writer Writer
+}
+
+// NewKafkaPublisher creates a new KafkaPublisher. It requires a Writer interface.
+func NewKafkaPublisher(writer Writer) *KafkaPublisher {
+	return &KafkaPublisher{
+		writer,
+	}
+}
+
+// NewKafkaWriter creates the writer of the brokers. saslMechanism is one of plain, scram-sha-256 and scram-sha-512,
+// SASL is disabled if it is empty. The messages are partitioned with the murmur2 hash of their keys like the Java
+// clients, so the other producers of the same topic keep the order of the projects.
+func NewKafkaWriter(brokers []string, enableTls bool, saslMechanism, username, password string) (*kafka.Writer, error) {
+	if len(brokers) == 0 {
+		return nil, errors.New("at least one kafka broker is required")
+	}
+
+	transport := &kafka.Transport{}
+	if enableTls {
+		transport.TLS = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	var mechanism sasl.Mechanism
+	var err error
+	switch strings.ToLower(saslMechanism) {
+	case "":
+	case "plain":
+		mechanism = plain.Mechanism{Username: username, Password: password}
+	case "scram-sha-256":
+		mechanism, err = scram.Mechanism(scram.SHA256, username, password)
+	case "scram-sha-512":
+		mechanism, err = scram.Mechanism(scram.SHA512, username, password)
+	default:
+		return nil, fmt.Errorf("unknown kafka sasl mechanism %q", saslMechanism)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	transport.SASL = mechanism
+
+	return &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Balancer:     &kafka.Murmur2Balancer{},
+		RequiredAcks: kafka.RequireAll,
+		// the messages are written one by one, so waiting for a batch only delays them
+		BatchTimeout: 10 * time.Millisecond,
+		WriteTimeout: timeout,
+		Transport:    transport,
+	}, nil
+}
+
+// Publish produces the message to the topic and waits for the acknowledgement of all in-sync replicas. The CloudEvents
+// attributes are sent as the ce_ prefixed headers as defined in the Kafka protocol binding.
+func (k *KafkaPublisher) Publish(topic string, msg *bus.Message) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	headers := []kafka.Header{{Key: "content-type", Value: []byte(msg.ContentType)}}
+	for _, name := range msg.AttributeNames() {
+		headers = append(headers, kafka.Header{Key: "ce_" + name, Value: []byte(msg.Attributes[name])})
+	}
+
+	return k.writer.WriteMessages(ctx, kafka.Message{
+		Topic:   topic,
+		Key:     []byte(msg.Key),
+		Value:   msg.Value,
+		Headers: headers,
+	})
+}
+
+// Close flushes the pending messages and closes the writer
+func (k *KafkaPublisher) Close() error {
+	return k.writer.Close()
+}
@@ -0,0 +1,93 @@
+//go:build unit
+
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/bus"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockWriter struct {
+	mock.Mock
+}
+
+func (m *MockWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	args := m.Called(ctx, msgs)
+	return args.Error(0)
+}
+
+func (m *MockWriter) Close() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func TestNewKafkaWriter(t *testing.T) {
+	cases := []struct {
+		caseName      string
+		brokers       []string
+		tls           bool
+		saslMechanism string
+		shouldPass    bool
+	}{
+		{"Plaintext", []string{"localhost:9092"}, false, "", true},
+		{"TLS with SASL plain", []string{"broker1:9093", "broker2:9093"}, true, "plain", true},
+		{"SCRAM", []string{"broker1:9093"}, true, "SCRAM-SHA-512", true},
+		{"Unknown mechanism", []string{"broker1:9093"}, true, "gssapi", false},
+		{"Missing brokers", nil, false, "", false},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		writer, err := NewKafkaWriter(tc.brokers, tc.tls, tc.saslMechanism, "user", "password")
+		if !tc.shouldPass {
+			assert.NotNil(t, err)
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.Equal(t, kafka.RequireAll, writer.RequiredAcks)
+		assert.IsType(t, &kafka.Murmur2Balancer{}, writer.Balancer)
+
+		transport := writer.Transport.(*kafka.Transport)
+		assert.Equal(t, tc.tls, transport.TLS != nil)
+		assert.Equal(t, tc.saslMechanism != "", transport.SASL != nil)
+	}
+}
+
+func TestKafkaPublisher_Publish(t *testing.T) {
+	writer := new(MockWriter)
+	writer.On("WriteMessages", mock.Anything, mock.Anything).Return(nil).Once()
+	writer.On("WriteMessages", mock.Anything, mock.Anything).Return(errors.New("injected error")).Once()
+
+	publisher := NewKafkaPublisher(writer)
+	assert.Nil(t, publisher.Publish("releases", &bus.Message{Key: "user1/project1", ContentType: "application/json",
+		Attributes: map[string]string{"type": "release.published", "id": "1"}, Value: []byte(`{"version":"v1.0.0"}`)}))
+	assert.NotNil(t, publisher.Publish("releases", &bus.Message{Key: "user1/project1", ContentType: "application/json",
+		Value: []byte(`{"version":"v1.0.1"}`)}))
+
+	msgs := writer.Calls[0].Arguments.Get(1).([]kafka.Message)
+	assert.Len(t, msgs, 1)
+	assert.Equal(t, "releases", msgs[0].Topic)
+	assert.Equal(t, []byte("user1/project1"), msgs[0].Key)
+	assert.Equal(t, []byte(`{"version":"v1.0.0"}`), msgs[0].Value)
+	assert.Equal(t, []kafka.Header{
+		{Key: "content-type", Value: []byte("application/json")},
+		{Key: "ce_id", Value: []byte("1")},
+		{Key: "ce_type", Value: []byte("release.published")},
+	}, msgs[0].Headers)
+}
+
+func TestKafkaPublisher_Close(t *testing.T) {
+	writer := new(MockWriter)
+	writer.On("Close").Return(errors.New("injected error"))
+
+	assert.NotNil(t, NewKafkaPublisher(writer).Close())
+	writer.AssertCalled(t, "Close")
+}
@@ -0,0 +1,77 @@
+package nats
+
+import (
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/bus"
+)
+
+const timeout = 10 * time.Second
+
+// Conn is the interface that contains PublishMsg, FlushTimeout and Close functions.
+// We can mock this interface for testing purposes.
+type Conn interface {
+	PublishMsg(m *nats.Msg) error
+	FlushTimeout(timeout time.Duration) error
+	Close()
+}
+
+// NatsPublisher is the bus.Publisher that publishes the messages to the NATS subjects
+type NatsPublisher struct {
+	conn Conn
+}
+
+// NewNatsPublisher creates a new NatsPublisher. It requires a Conn interface.
+func NewNatsPublisher(conn Conn) *NatsPublisher {
+	return &NatsPublisher{
+		conn,
+	}
+}
+
+// NewNatsConn connects to the server. token, username and password or credentialsFile are used for the authentication
+// if they are set. The connection is retried in the background, so an unavailable server does not prevent the startup.
+func NewNatsConn(url, token, username, password, credentialsFile string) (*nats.Conn, error) {
+	opts := []nats.Option{
+		nats.Name("rss-feed-filterer"),
+		nats.RetryOnFailedConnect(true),
+		nats.MaxReconnects(-1),
+	}
+
+	switch {
+	case credentialsFile != "":
+		opts = append(opts, nats.UserCredentials(credentialsFile))
+	case token != "":
+		opts = append(opts, nats.Token(token))
+	case username != "":
+		opts = append(opts, nats.UserInfo(username, password))
+	}
+
+	return nats.Connect(url, opts...)
+}
+
+// Publish publishes the message to the subject. NATS has no message keys, so the key is sent in the Key header and the
+// CloudEvents attributes are sent as the ce- prefixed headers. It flushes the connection to report the delivery errors
+// to the server, the JetStream streams listening on the subject persist the messages.
+func (n *NatsPublisher) Publish(subject string, msg *bus.Message) error {
+	natsMsg := nats.NewMsg(subject)
+	natsMsg.Data = msg.Value
+	natsMsg.Header.Set("Content-Type", msg.ContentType)
+	natsMsg.Header.Set("Key", msg.Key)
+	for _, name := range msg.AttributeNames() {
+		natsMsg.Header.Set("ce-"+name, msg.Attributes[name])
+	}
+
+	if err := n.conn.PublishMsg(natsMsg); err != nil {
+		return err
+	}
+
+	return n.conn.FlushTimeout(timeout)
+}
+
+// Close closes the connection, the messages are already flushed by Publish
+func (n *NatsPublisher) Close() error {
+	n.conn.Close()
+	return nil
+}
@@ -0,0 +1,80 @@
+//go:build unit
+
+package nats
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/bus"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockConn struct {
+	mock.Mock
+}
+
+func (m *MockConn) PublishMsg(msg *nats.Msg) error {
+	args := m.Called(msg)
+	return args.Error(0)
+}
+
+func (m *MockConn) FlushTimeout(timeout time.Duration) error {
+	args := m.Called(timeout)
+	return args.Error(0)
+}
+
+func (m *MockConn) Close() {
+	m.Called()
+}
+
+func TestNatsPublisher_Publish(t *testing.T) {
+	cases := []struct {
+		caseName     string
+		publishErr   error
+		flushErr     error
+		expectedErr  error
+		expectedCall bool
+	}{
+		{"Published", nil, nil, nil, true},
+		{"Publish error", errors.New("nats: connection closed"), nil, errors.New("nats: connection closed"), false},
+		{"Flush error", nil, nats.ErrTimeout, nats.ErrTimeout, true},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		conn := new(MockConn)
+		conn.On("PublishMsg", mock.Anything).Return(tc.publishErr)
+		conn.On("FlushTimeout", timeout).Return(tc.flushErr)
+
+		publisher := NewNatsPublisher(conn)
+		assert.Equal(t, tc.expectedErr, publisher.Publish("releases.new", &bus.Message{Key: "user1/project1",
+			ContentType: "application/json", Attributes: map[string]string{"type": "release.published"},
+			Value: []byte(`{"version":"v1.0.0"}`)}))
+
+		msg := conn.Calls[0].Arguments.Get(0).(*nats.Msg)
+		assert.Equal(t, "releases.new", msg.Subject)
+		assert.Equal(t, []byte(`{"version":"v1.0.0"}`), msg.Data)
+		assert.Equal(t, "user1/project1", msg.Header.Get("Key"))
+		assert.Equal(t, "application/json", msg.Header.Get("Content-Type"))
+		assert.Equal(t, "release.published", msg.Header.Get("ce-type"))
+
+		if tc.expectedCall {
+			conn.AssertCalled(t, "FlushTimeout", timeout)
+		} else {
+			conn.AssertNotCalled(t, "FlushTimeout", timeout)
+		}
+	}
+}
+
+func TestNatsPublisher_Close(t *testing.T) {
+	conn := new(MockConn)
+	conn.On("Close").Return()
+
+	assert.Nil(t, NewNatsPublisher(conn).Close())
+	conn.AssertCalled(t, "Close")
+}
@@ -0,0 +1,75 @@
+package redis
+
+import (
+	"context"
+	"crypto/tls"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/bus"
+)
+
+const timeout = 10 * time.Second
+
+// Client is the interface that contains XAdd and Close functions.
+// We can mock this interface for testing purposes.
+type Client interface {
+	XAdd(ctx context.Context, a *redis.XAddArgs) *redis.StringCmd
+	Close() error
+}
+
+// RedisPublisher is the bus.Publisher that adds the messages to the Redis streams
+type RedisPublisher struct {
+	// MaxLen caps the length of the streams approximately, the streams are not trimmed if it is 0
+	MaxLen int64
+	client Client
+}
+
+// NewRedisPublisher creates a new RedisPublisher. It requires a Client interface.
+func NewRedisPublisher(client Client, maxLen int64) *RedisPublisher {
+	return &RedisPublisher{
+		MaxLen: maxLen,
+		client: client,
+	}
+}
+
+// NewRedisClient creates the client of the server, the connections are opened lazily.
+func NewRedisClient(addr, username, password string, db int, enableTls bool) *redis.Client {
+	opts := &redis.Options{
+		Addr:     addr,
+		Username: username,
+		Password: password,
+		DB:       db,
+	}
+
+	if enableTls {
+		opts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	return redis.NewClient(opts)
+}
+
+// Publish adds the entry with the key, content-type and event fields to the stream. Redis has no CloudEvents binding,
+// so the attributes are added as the ce_ prefixed fields like the Kafka binding.
+func (r *RedisPublisher) Publish(stream string, msg *bus.Message) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	values := []string{"key", msg.Key, "content-type", msg.ContentType, "event", string(msg.Value)}
+	for _, name := range msg.AttributeNames() {
+		values = append(values, "ce_"+name, msg.Attributes[name])
+	}
+
+	return r.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: stream,
+		MaxLen: r.MaxLen,
+		Approx: r.MaxLen > 0,
+		Values: values,
+	}).Err()
+}
+
+// Close closes the connections of the client
+func (r *RedisPublisher) Close() error {
+	return r.client.Close()
+}
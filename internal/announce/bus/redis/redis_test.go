@@ -0,0 +1,80 @@
+//go:build unit
+
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/bus"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockClient struct {
+	mock.Mock
+}
+
+func (m *MockClient) XAdd(ctx context.Context, a *redis.XAddArgs) *redis.StringCmd {
+	args := m.Called(ctx, a)
+	return args.Get(0).(*redis.StringCmd)
+}
+
+func (m *MockClient) Close() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func TestNewRedisClient(t *testing.T) {
+	client := NewRedisClient("localhost:6379", "default", "password", 2, true)
+	assert.Equal(t, 2, client.Options().DB)
+	assert.NotNil(t, client.Options().TLSConfig)
+	assert.Nil(t, client.Close())
+}
+
+func TestRedisPublisher_Publish(t *testing.T) {
+	cases := []struct {
+		caseName       string
+		maxLen         int64
+		result         *redis.StringCmd
+		expectedApprox bool
+		shouldPass     bool
+	}{
+		{"Unbounded stream", 0, redis.NewStringResult("1696161600000-0", nil), false, true},
+		{"Capped stream", 1000, redis.NewStringResult("1696161600000-0", nil), true, true},
+		{"XAdd error", 0, redis.NewStringResult("", errors.New("injected error")), false, false},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		client := new(MockClient)
+		client.On("XAdd", mock.Anything, mock.Anything).Return(tc.result)
+
+		publisher := NewRedisPublisher(client, tc.maxLen)
+		err := publisher.Publish("releases", &bus.Message{Key: "user1/project1", ContentType: "application/json",
+			Attributes: map[string]string{"type": "release.published"}, Value: []byte(`{"version":"v1.0.0"}`)})
+		if tc.shouldPass {
+			assert.Nil(t, err)
+		} else {
+			assert.NotNil(t, err)
+		}
+
+		args := client.Calls[0].Arguments.Get(1).(*redis.XAddArgs)
+		assert.Equal(t, "releases", args.Stream)
+		assert.Equal(t, tc.maxLen, args.MaxLen)
+		assert.Equal(t, tc.expectedApprox, args.Approx)
+		assert.Equal(t, []string{"key", "user1/project1", "content-type", "application/json", "event", `{"version":"v1.0.0"}`,
+			"ce_type", "release.published"}, args.Values)
+	}
+}
+
+func TestRedisPublisher_Close(t *testing.T) {
+	client := new(MockClient)
+	client.On("Close").Return(nil)
+
+	assert.Nil(t, NewRedisPublisher(client, 0).Close())
+	client.AssertCalled(t, "Close")
+}
@@ -0,0 +1,197 @@
+package announce
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/types"
+)
+
+// EventFormat is the encoding of the release events sent by the webhook, message bus and file announcers
+type EventFormat string
+
+const (
+	// EventFormatJSON is the versioned JSON event, it is the default
+	EventFormatJSON EventFormat = "json"
+	// EventFormatCloudEvents is the structured mode of CloudEvents, the body is the whole event
+	EventFormatCloudEvents EventFormat = "cloudevents"
+	// EventFormatCloudEventsBinary is the binary mode of CloudEvents, the body is the data and the context attributes
+	// are sent as the headers of the transport
+	EventFormatCloudEventsBinary EventFormat = "cloudevents-binary"
+)
+
+// CloudEvents types of the release events
+const (
+	CloudEventTypePublished = "release.published"
+	CloudEventTypeUpdated   = "release.updated"
+	CloudEventTypeRemoved   = "release.removed"
+)
+
+const (
+	// CloudEventsSpecVersion is the version of the CloudEvents specification the events conform to
+	CloudEventsSpecVersion = "1.0"
+	// CloudEventSource is the source attribute of the release events
+	CloudEventSource = "rss-feed-filterer"
+	// CloudEventsContentType is the media type of the events in the structured mode
+	CloudEventsContentType = "application/cloudevents+json"
+)
+
+var cloudEventTypes = map[types.EventType]string{
+	types.EventNew:     CloudEventTypePublished,
+	types.EventUpdated: CloudEventTypeUpdated,
+	types.EventRemoved: CloudEventTypeRemoved,
+}
+
+// CloudEvent is a CloudEvents 1.0 release event. Id is derived from the data, so the retries of an event have the same
+// id and the consumers can deduplicate them.
+type CloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	Id              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	Data            *CloudEventData `json:"data"`
+}
+
+// CloudEventData is the data of the release events, it is the release with the changes detected on it
+type CloudEventData struct {
+	types.Release
+	// Changes contains the names of the changed fields of the release.updated events
+	Changes []string `json:"changes,omitempty"`
+	// BreakingChanges is the breaking change sections of the notes in HTML format
+	BreakingChanges string `json:"breakingChanges,omitempty"`
+	// Level is one of security, breaking, high and normal
+	Level string `json:"level"`
+}
+
+// ParseEventFormat parses the format name, empty format defaults to EventFormatJSON
+func ParseEventFormat(format string) (EventFormat, error) {
+	switch EventFormat(strings.ToLower(format)) {
+	case "", EventFormatJSON:
+		return EventFormatJSON, nil
+	case EventFormatCloudEvents:
+		return EventFormatCloudEvents, nil
+	case EventFormatCloudEventsBinary:
+		return EventFormatCloudEventsBinary, nil
+	default:
+		return "", fmt.Errorf("unknown event format %q", format)
+	}
+}
+
+// NewCloudEvent creates the release event of the payload. Time is the publish time of the release.published events
+// and the time of the detection for the others.
+func NewCloudEvent(payload *AnnouncerPayload) (*CloudEvent, error) {
+	data := &CloudEventData{
+		Release:         payload.Release(),
+		Changes:         payload.Changes,
+		BreakingChanges: payload.BreakingChanges,
+		Level:           payload.Level(),
+	}
+
+	encoded, err := MarshalJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	event := &CloudEvent{
+		SpecVersion:     CloudEventsSpecVersion,
+		Source:          CloudEventSource,
+		Type:            cloudEventTypes[payload.GetEvent()],
+		Subject:         payload.ProjectName,
+		Time:            time.Now().UTC(),
+		DataContentType: "application/json",
+		Data:            data,
+	}
+
+	if payload.GetEvent() == types.EventNew && payload.PublishedAt != nil {
+		event.Time = payload.PublishedAt.UTC()
+	}
+
+	sum := sha256.Sum256([]byte(event.Type + "\n" + encoded))
+	event.Id = hex.EncodeToString(sum[:16])
+
+	return event, nil
+}
+
+// Attributes returns the context attributes of the event except datacontenttype, they are sent as the headers in the
+// binary mode with the prefix of the transport
+func (e *CloudEvent) Attributes() map[string]string {
+	return map[string]string{
+		"specversion": e.SpecVersion,
+		"id":          e.Id,
+		"source":      e.Source,
+		"type":        e.Type,
+		"subject":     e.Subject,
+		"time":        e.Time.Format(time.RFC3339),
+	}
+}
+
+// EncodedEvent is the release event encoded in a format
+type EncodedEvent struct {
+	ContentType string
+	// Attributes are the CloudEvents context attributes in the binary mode, nil for the other formats
+	Attributes map[string]string
+	Body       []byte
+}
+
+// EncodeEvent encodes the release event of the payload in the format
+func EncodeEvent(format EventFormat, payload *AnnouncerPayload) (*EncodedEvent, error) {
+	if format == EventFormatJSON || format == "" {
+		body, err := MarshalJSON(NewEvent(payload))
+		if err != nil {
+			return nil, err
+		}
+
+		return &EncodedEvent{ContentType: "application/json", Body: []byte(body)}, nil
+	}
+
+	event, err := NewCloudEvent(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == EventFormatCloudEventsBinary {
+		body, err := MarshalJSON(event.Data)
+		if err != nil {
+			return nil, err
+		}
+
+		return &EncodedEvent{ContentType: event.DataContentType, Attributes: event.Attributes(), Body: []byte(body)}, nil
+	}
+
+	body, err := MarshalJSON(event)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EncodedEvent{ContentType: CloudEventsContentType, Body: []byte(body)}, nil
+}
+
+// EncodeEventWithin encodes the release event of the payload in the format like EncodeEvent, the notes are dropped
+// from the event if it is longer than maxSize bytes, so the events of the releases with huge notes are not rejected
+// by the transports with a message size limit. The consumers can still follow the url of the release for the notes.
+func EncodeEventWithin(format EventFormat, payload *AnnouncerPayload, maxSize int) (*EncodedEvent, error) {
+	event, err := EncodeEvent(format, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(event.Body) > maxSize && payload.Notes != "" {
+		withoutNotes := *payload
+		withoutNotes.Notes = ""
+		if event, err = EncodeEvent(format, &withoutNotes); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(event.Body) > maxSize {
+		return nil, fmt.Errorf("release event is %d bytes, longer than the limit of %d bytes", len(event.Body), maxSize)
+	}
+
+	return event, nil
+}
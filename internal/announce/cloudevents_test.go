@@ -0,0 +1,168 @@
+//go:build unit
+
+package announce
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEventFormat(t *testing.T) {
+	cases := []struct {
+		caseName   string
+		format     string
+		expected   EventFormat
+		shouldPass bool
+	}{
+		{"Default", "", EventFormatJSON, true},
+		{"Structured", "CloudEvents", EventFormatCloudEvents, true},
+		{"Binary", "cloudevents-binary", EventFormatCloudEventsBinary, true},
+		{"Unknown", "avro", "", false},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		format, err := ParseEventFormat(tc.format)
+		if !tc.shouldPass {
+			assert.NotNil(t, err)
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.Equal(t, tc.expected, format)
+	}
+}
+
+func TestNewCloudEvent(t *testing.T) {
+	publishedAt := time.Date(2023, 10, 1, 12, 0, 0, 0, time.FixedZone("UTC+3", 3*60*60))
+	updatedAt := publishedAt.Add(time.Hour)
+
+	cases := []struct {
+		caseName     string
+		payload      *AnnouncerPayload
+		expectedType string
+		expectedTime *time.Time
+	}{
+		{
+			"Published release",
+			&AnnouncerPayload{ProjectName: "user1/project1", Version: "v1.0.0", URL: "https://example.com",
+				PublishedAt: &publishedAt, UpdatedAt: &updatedAt, Notes: "<p>notes</p>"},
+			CloudEventTypePublished,
+			&publishedAt,
+		},
+		{
+			"Updated release",
+			&AnnouncerPayload{ProjectName: "user1/project1", Version: "v1.0.0", URL: "https://example.com",
+				PublishedAt: &publishedAt, Event: types.EventUpdated, Changes: []string{"updatedAt"}},
+			CloudEventTypeUpdated,
+			nil,
+		},
+		{
+			"Removed release",
+			&AnnouncerPayload{ProjectName: "user1/project1", Version: "v1.0.0", URL: "https://example.com",
+				Event: types.EventRemoved},
+			CloudEventTypeRemoved,
+			nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		event, err := NewCloudEvent(tc.payload)
+		assert.Nil(t, err)
+		assert.Equal(t, CloudEventsSpecVersion, event.SpecVersion)
+		assert.Equal(t, CloudEventSource, event.Source)
+		assert.Equal(t, tc.expectedType, event.Type)
+		assert.Equal(t, "user1/project1", event.Subject)
+		assert.Equal(t, tc.payload.Release(), event.Data.Release)
+		assert.Equal(t, tc.payload.Changes, event.Data.Changes)
+		assert.Len(t, event.Id, 32)
+		if tc.expectedTime != nil {
+			assert.True(t, tc.expectedTime.Equal(event.Time))
+		} else {
+			assert.WithinDuration(t, time.Now(), event.Time, time.Minute)
+		}
+
+		// retries of the same event have the same id
+		again, _ := NewCloudEvent(tc.payload)
+		assert.Equal(t, event.Id, again.Id)
+	}
+
+	first, _ := NewCloudEvent(&AnnouncerPayload{ProjectName: "user1/project1", Version: "v1.0.0"})
+	second, _ := NewCloudEvent(&AnnouncerPayload{ProjectName: "user1/project1", Version: "v1.0.0", Event: types.EventRemoved})
+	assert.NotEqual(t, first.Id, second.Id)
+}
+
+func TestEncodeEvent(t *testing.T) {
+	payload := &AnnouncerPayload{ProjectName: "user1/project1", Version: "v1.0.0", URL: "https://example.com",
+		Notes: "<p>a & b</p>", Security: &types.Security{CVEs: []string{"CVE-2023-1234"}}}
+
+	encoded, err := EncodeEvent(EventFormatJSON, payload)
+	assert.Nil(t, err)
+	assert.Equal(t, "application/json", encoded.ContentType)
+	assert.Nil(t, encoded.Attributes)
+	assert.Contains(t, string(encoded.Body), `"schemaVersion":"1"`)
+	assert.Contains(t, string(encoded.Body), `"notes":"<p>a & b</p>"`)
+
+	encoded, err = EncodeEvent(EventFormatCloudEvents, payload)
+	assert.Nil(t, err)
+	assert.Equal(t, CloudEventsContentType, encoded.ContentType)
+	assert.Nil(t, encoded.Attributes)
+
+	var structured map[string]any
+	assert.Nil(t, json.Unmarshal(encoded.Body, &structured))
+	assert.Equal(t, "1.0", structured["specversion"])
+	assert.Equal(t, "release.published", structured["type"])
+	assert.Equal(t, "application/json", structured["datacontenttype"])
+	assert.Equal(t, LevelSecurity, structured["data"].(map[string]any)["level"])
+
+	encoded, err = EncodeEvent(EventFormatCloudEventsBinary, payload)
+	assert.Nil(t, err)
+	assert.Equal(t, "application/json", encoded.ContentType)
+	assert.Equal(t, structured["id"], encoded.Attributes["id"])
+	assert.Equal(t, "release.published", encoded.Attributes["type"])
+
+	var data map[string]any
+	assert.Nil(t, json.Unmarshal(encoded.Body, &data))
+	assert.Equal(t, structured["data"], data)
+}
+
+func TestEncodeEventWithin(t *testing.T) {
+	cases := []struct {
+		caseName      string
+		format        EventFormat
+		notes         string
+		summary       string
+		expectedNotes bool
+		shouldPass    bool
+	}{
+		{"Event within the limit", EventFormatJSON, "<p>Fixes bug</p>", "", true, true},
+		{"Oversized notes are dropped", EventFormatJSON, strings.Repeat("<p>Fixes bug</p>", 1000), "", false, true},
+		{"Oversized notes are dropped from the CloudEvents", EventFormatCloudEvents, strings.Repeat("<p>Fixes bug</p>", 1000),
+			"", false, true},
+		{"Oversized event without the notes", EventFormatJSON, "<p>Fixes bug</p>", strings.Repeat("a", 2000), false, false},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		encoded, err := EncodeEventWithin(tc.format, &AnnouncerPayload{ProjectName: "user1/project1", Version: "v1.0.0",
+			URL: "https://example.com", Notes: tc.notes, Message: tc.summary}, 1024)
+		if !tc.shouldPass {
+			assert.NotNil(t, err)
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.LessOrEqual(t, len(encoded.Body), 1024)
+		assert.Equal(t, tc.expectedNotes, strings.Contains(string(encoded.Body), "Fixes bug"))
+		assert.Contains(t, string(encoded.Body), "https://example.com")
+	}
+}
@@ -0,0 +1,101 @@
+package announce
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxDigestReleases is the number of the releases listed in the message of a digest, the rest are only counted so
+// the message fits into the limits of the chat services
+const maxDigestReleases = 25
+
+// Flusher is implemented by the announcers which batch the payloads and deliver them later, like the digests
+type Flusher interface {
+	// Flush delivers the batched payloads if they are due at the given time
+	Flush(now time.Time) error
+}
+
+// Flush flushes the announcer if it or one of the announcers wrapped by it is a Flusher, the wrappers expose the
+// announcers they wrap with an Unwrap method
+func Flush(announcer Announcer, now time.Time) error {
+	if flusher, ok := find[Flusher](announcer); ok {
+		return flusher.Flush(now)
+	}
+
+	return nil
+}
+
+// DigestGroup is the releases of a project in a digest
+type DigestGroup struct {
+	ProjectName string          `json:"projectName"`
+	Releases    []*TemplateData `json:"releases"`
+}
+
+// NewDigestPayload creates the digest of the releases, the subject is the title with the counts of the releases and
+// the projects, the message lists the summaries of the releases grouped by project
+func NewDigestPayload(title string, releases []*AnnouncerPayload) *AnnouncerPayload {
+	groups := groupByProject(releases)
+	subject := fmt.Sprintf("%s: %s of %s", title, plural(len(releases), "release"), plural(len(groups), "project"))
+
+	return &AnnouncerPayload{
+		Message: DigestMessage(subject, releases),
+		Subject: subject,
+		Digest:  releases,
+	}
+}
+
+// DigestMessage lists the summaries of the releases under their projects after the subject
+func DigestMessage(subject string, releases []*AnnouncerPayload) string {
+	var b strings.Builder
+	b.WriteString(subject)
+
+	listed := 0
+	for _, group := range groupByProject(releases) {
+		if listed == maxDigestReleases {
+			break
+		}
+
+		b.WriteString("\n\n" + group[0].ProjectName)
+		for _, release := range group {
+			if listed == maxDigestReleases {
+				break
+			}
+
+			b.WriteString("\n- " + release.Summary())
+			listed++
+		}
+	}
+
+	if remaining := len(releases) - listed; remaining > 0 {
+		b.WriteString("\n\n…and " + plural(remaining, "more release"))
+	}
+
+	return b.String()
+}
+
+// groupByProject groups the releases by project in the order the projects are first seen
+func groupByProject(releases []*AnnouncerPayload) [][]*AnnouncerPayload {
+	var groups [][]*AnnouncerPayload
+	indexes := make(map[string]int)
+	for _, release := range releases {
+		index, ok := indexes[release.ProjectName]
+		if !ok {
+			index = len(groups)
+			indexes[release.ProjectName] = index
+			groups = append(groups, nil)
+		}
+
+		groups[index] = append(groups[index], release)
+	}
+
+	return groups
+}
+
+func plural(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, noun)
+	}
+
+	return fmt.Sprintf("%d %ss", count, noun)
+}
@@ -0,0 +1,146 @@
+package digest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+)
+
+// DigestAnnouncer wraps an Announcer and batches the payloads into a single digest which is sent on its Schedule, the
+// payloads routed as immediate are passed to the wrapped announcer right away
+type DigestAnnouncer struct {
+	announce.Announcer
+	// Name identifies the pending releases of the announcer in the Store
+	Name string
+	*Schedule
+	store Store
+	now   func() time.Time
+	// mu serializes the updates of the pending releases by the concurrent repository checks and the flushes
+	mu sync.Mutex
+}
+
+// NewDigestAnnouncer creates a new DigestAnnouncer which wraps the given announcer
+func NewDigestAnnouncer(announcer announce.Announcer, name string, schedule *Schedule, store Store) *DigestAnnouncer {
+	return &DigestAnnouncer{
+		Announcer: announcer,
+		Name:      name,
+		Schedule:  schedule,
+		store:     store,
+		now:       time.Now,
+	}
+}
+
+// Notify adds the payload to the pending releases of the digest, a pending release of the same version and event is
+// replaced with the payload
+func (d *DigestAnnouncer) Notify(payload *announce.AnnouncerPayload) error {
+	if payload.Immediate || payload.IsDigest() {
+		return d.Announcer.Notify(payload)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	state, err := d.store.Load(d.Name)
+	if err != nil {
+		return fmt.Errorf("failed to load the pending releases of the digest: %w", err)
+	}
+
+	if len(state.Releases) == 0 {
+		state.Since = d.now()
+	}
+
+	replaced := false
+	for i, release := range state.Releases {
+		if release.DedupKey() == payload.DedupKey() && release.GetEvent() == payload.GetEvent() {
+			state.Releases[i] = payload
+			replaced = true
+			break
+		}
+	}
+
+	if !replaced {
+		state.Releases = append(state.Releases, payload)
+	}
+
+	if err := d.store.Save(d.Name, state); err != nil {
+		return fmt.Errorf("failed to save the pending releases of the digest: %w", err)
+	}
+
+	return nil
+}
+
+// Flush sends the digest of the pending releases if a scheduled time has passed since the oldest of them is added.
+// The releases routed to different recipients are sent in separate digests to their recipients with the highest
+// priority among them. The releases are kept as pending if their digest could not be sent.
+func (d *DigestAnnouncer) Flush(now time.Time) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	state, err := d.store.Load(d.Name)
+	if err != nil {
+		return fmt.Errorf("failed to load the pending releases of the digest: %w", err)
+	}
+
+	if len(state.Releases) == 0 || !d.IsDue(state.Since, now) {
+		return nil
+	}
+
+	title := fmt.Sprintf("%s%s release digest", strings.ToUpper(d.Every[:1]), d.Every[1:])
+
+	var errs []error
+	var pending []*announce.AnnouncerPayload
+	for _, releases := range groupByRecipients(state.Releases) {
+		payload := announce.NewDigestPayload(title, releases)
+		payload.Recipients = releases[0].Recipients
+		for _, release := range releases {
+			if release.IsUrgent() {
+				payload.Priority = announce.PriorityHigh
+			}
+		}
+
+		if err := d.Announcer.Notify(payload); err != nil {
+			errs = append(errs, err)
+			pending = append(pending, releases...)
+		}
+	}
+
+	// the failed releases keep the time the oldest of them is added, so their digest is retried on the next flush
+	state.Releases = pending
+	if len(pending) == 0 {
+		state = &State{}
+	}
+
+	if err := d.store.Save(d.Name, state); err != nil {
+		errs = append(errs, fmt.Errorf("failed to save the pending releases of the digest: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// groupByRecipients groups the releases by their recipients in the order the recipients are first seen
+func groupByRecipients(releases []*announce.AnnouncerPayload) [][]*announce.AnnouncerPayload {
+	var groups [][]*announce.AnnouncerPayload
+	indexes := make(map[string]int)
+	for _, release := range releases {
+		key := strings.Join(release.Recipients, "\n")
+		index, ok := indexes[key]
+		if !ok {
+			index = len(groups)
+			indexes[key] = index
+			groups = append(groups, nil)
+		}
+
+		groups[index] = append(groups[index], release)
+	}
+
+	return groups
+}
+
+// Unwrap returns the wrapped announcer
+func (d *DigestAnnouncer) Unwrap() announce.Announcer {
+	return d.Announcer
+}
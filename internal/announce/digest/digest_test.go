@@ -0,0 +1,184 @@
+//go:build unit
+
+package digest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/storage/aws"
+	internaltypes "github.com/bilalcaliskan/rss-feed-filterer/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingAnnouncer struct {
+	payloads []*announce.AnnouncerPayload
+	err      error
+	// failingRecipient fails the payloads routed to it
+	failingRecipient string
+}
+
+func (r *recordingAnnouncer) Notify(payload *announce.AnnouncerPayload) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	if r.failingRecipient != "" && slices.Contains(payload.Recipients, r.failingRecipient) {
+		return errors.New("recipient is unreachable")
+	}
+
+	r.payloads = append(r.payloads, payload)
+	return nil
+}
+
+func (r *recordingAnnouncer) IsEnabled() bool {
+	return true
+}
+
+// newMemoryClient returns an S3 client which keeps the objects in memory
+func newMemoryClient() *aws.MockS3Client {
+	var mu sync.Mutex
+	objects := make(map[string][]byte)
+
+	return &aws.MockS3Client{
+		GetObjectAPI: func(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			content, ok := objects[*params.Key]
+			if !ok {
+				return nil, &types.NoSuchKey{}
+			}
+
+			return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(content))}, nil
+		},
+		PutObjectAPI: func(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			content, err := io.ReadAll(params.Body)
+			if err != nil {
+				return nil, err
+			}
+
+			objects[*params.Key] = content
+			return &s3.PutObjectOutput{}, nil
+		},
+		HeadObjectAPI: func(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if _, ok := objects[*params.Key]; !ok {
+				return nil, &types.NoSuchKey{}
+			}
+
+			return &s3.HeadObjectOutput{}, nil
+		},
+	}
+}
+
+func TestS3Store(t *testing.T) {
+	store := NewS3Store(newMemoryClient(), "bucket")
+
+	state, err := store.Load("slack")
+	assert.Nil(t, err)
+	assert.Empty(t, state.Releases)
+
+	since := time.Date(2023, 10, 4, 8, 0, 0, 0, time.UTC)
+	assert.Nil(t, store.Save("slack", &State{Since: since, Releases: []*announce.AnnouncerPayload{
+		{ProjectName: "user1/project1", Version: "v1.0.0", Priority: announce.PriorityHigh},
+	}}))
+
+	state, err = store.Load("slack")
+	assert.Nil(t, err)
+	assert.True(t, since.Equal(state.Since))
+	assert.Len(t, state.Releases, 1)
+	assert.Equal(t, announce.PriorityHigh, state.Releases[0].Priority)
+
+	// the digests of the announcers are kept apart
+	state, err = store.Load("email")
+	assert.Nil(t, err)
+	assert.Empty(t, state.Releases)
+}
+
+func TestDigestAnnouncer(t *testing.T) {
+	now := time.Date(2023, 10, 4, 8, 0, 0, 0, time.UTC)
+	schedule := &Schedule{Every: ScheduleDaily, Hour: 9, Location: time.UTC}
+	recorder := &recordingAnnouncer{}
+	store := NewS3Store(newMemoryClient(), "bucket")
+	announcer := NewDigestAnnouncer(recorder, "slack", schedule, store)
+	announcer.now = func() time.Time { return now }
+
+	assert.Nil(t, announcer.Notify(&announce.AnnouncerPayload{ProjectName: "user1/project1", Version: "v1.0.0", URL: "https://example.com/1"}))
+	assert.Nil(t, announcer.Notify(&announce.AnnouncerPayload{ProjectName: "user1/project2", Version: "v2.0.0"}))
+	// an update of a pending release replaces it
+	assert.Nil(t, announcer.Notify(&announce.AnnouncerPayload{ProjectName: "user1/project1", Version: "v1.0.0", URL: "https://example.com/2"}))
+	assert.Nil(t, announcer.Notify(&announce.AnnouncerPayload{ProjectName: "user1/project1", Version: "v1.0.0", Event: internaltypes.EventRemoved}))
+	// immediate releases bypass the digest
+	assert.Nil(t, announcer.Notify(&announce.AnnouncerPayload{ProjectName: "user1/project3", Version: "v3.0.0", Immediate: true}))
+	assert.Len(t, recorder.payloads, 1)
+	assert.Equal(t, "user1/project3", recorder.payloads[0].ProjectName)
+
+	// nothing is sent until the scheduled time
+	assert.Nil(t, announcer.Flush(now.Add(time.Minute*59)))
+	assert.Len(t, recorder.payloads, 1)
+
+	recorder.err = errors.New("webhook is down")
+	assert.NotNil(t, announcer.Flush(now.Add(time.Hour)))
+	recorder.err = nil
+
+	// the releases are kept until the digest is sent
+	assert.Nil(t, announcer.Flush(now.Add(time.Hour)))
+	assert.Len(t, recorder.payloads, 2)
+	digest := recorder.payloads[1]
+	assert.True(t, digest.IsDigest())
+	assert.Equal(t, "Daily release digest: 3 releases of 2 projects", digest.Subject)
+	assert.Equal(t, "https://example.com/2", digest.Digest[0].URL)
+	assert.Equal(t, internaltypes.EventRemoved, digest.Digest[2].Event)
+
+	// the digest is cleared once it is sent
+	assert.Nil(t, announcer.Flush(now.Add(time.Hour*25)))
+	assert.Len(t, recorder.payloads, 2)
+	assert.Equal(t, recorder, announcer.Unwrap())
+}
+
+func TestDigestAnnouncer_routedReleases(t *testing.T) {
+	now := time.Date(2023, 10, 4, 8, 0, 0, 0, time.UTC)
+	schedule := &Schedule{Every: ScheduleDaily, Hour: 9, Location: time.UTC}
+	recorder := &recordingAnnouncer{failingRecipient: "#platform"}
+	announcer := NewDigestAnnouncer(recorder, "slack", schedule, NewS3Store(newMemoryClient(), "bucket"))
+	announcer.now = func() time.Time { return now }
+
+	assert.Nil(t, announcer.Notify(&announce.AnnouncerPayload{ProjectName: "user1/project1", Version: "v1.0.0"}))
+	assert.Nil(t, announcer.Notify(&announce.AnnouncerPayload{ProjectName: "user1/project2", Version: "v2.0.0",
+		Recipients: []string{"#security"}}))
+	assert.Nil(t, announcer.Notify(&announce.AnnouncerPayload{ProjectName: "user1/project3", Version: "v3.0.0",
+		Recipients: []string{"#security"}, Priority: announce.PriorityHigh}))
+	assert.Nil(t, announcer.Notify(&announce.AnnouncerPayload{ProjectName: "user1/project4", Version: "v4.0.0",
+		Recipients: []string{"#platform"}}))
+
+	// each recipient gets its own digest, the failed one is kept for the next flush
+	assert.NotNil(t, announcer.Flush(now.Add(time.Hour)))
+	assert.Len(t, recorder.payloads, 2)
+	assert.Empty(t, recorder.payloads[0].Recipients)
+	assert.Equal(t, announce.Priority(""), recorder.payloads[0].Priority)
+	assert.Equal(t, "Daily release digest: 1 release of 1 project", recorder.payloads[0].Subject)
+	assert.Equal(t, []string{"#security"}, recorder.payloads[1].Recipients)
+	assert.Equal(t, announce.PriorityHigh, recorder.payloads[1].Priority)
+	assert.Equal(t, "Daily release digest: 2 releases of 2 projects", recorder.payloads[1].Subject)
+
+	recorder.failingRecipient = ""
+	assert.Nil(t, announcer.Flush(now.Add(time.Hour*2)))
+	assert.Len(t, recorder.payloads, 3)
+	assert.Equal(t, []string{"#platform"}, recorder.payloads[2].Recipients)
+	assert.Equal(t, "user1/project4", recorder.payloads[2].Digest[0].ProjectName)
+
+	assert.Nil(t, announcer.Flush(now.Add(time.Hour*25)))
+	assert.Len(t, recorder.payloads, 3)
+}
@@ -0,0 +1,111 @@
+package digest
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// ScheduleHourly sends the digests at the beginning of every hour
+	ScheduleHourly = "hourly"
+	// ScheduleDaily sends the digests every day at the configured time
+	ScheduleDaily = "daily"
+	// ScheduleWeekly sends the digests every week on the configured day at the configured time
+	ScheduleWeekly = "weekly"
+
+	defaultAt      = "09:00"
+	defaultWeekday = time.Monday
+)
+
+// Schedule is the times the digests are sent at
+type Schedule struct {
+	// Every is one of hourly, daily and weekly
+	Every    string
+	Hour     int
+	Minute   int
+	Weekday  time.Weekday
+	Location *time.Location
+}
+
+// ParseSchedule creates the schedule, at is the time of the day in HH:MM format and defaults to 09:00, weekday
+// defaults to monday and timezone is the IANA name of the time zone which defaults to UTC. at and weekday are only
+// used by the schedules they apply to.
+func ParseSchedule(every, at, weekday, timezone string) (*Schedule, error) {
+	every = strings.ToLower(every)
+	if every != ScheduleHourly && every != ScheduleDaily && every != ScheduleWeekly {
+		return nil, fmt.Errorf("unknown digest schedule %q", every)
+	}
+
+	if at == "" {
+		at = defaultAt
+	}
+
+	timeOfDay, err := time.Parse("15:04", at)
+	if err != nil {
+		return nil, fmt.Errorf("invalid digest time %q, it should be in HH:MM format", at)
+	}
+
+	schedule := &Schedule{
+		Every:    every,
+		Hour:     timeOfDay.Hour(),
+		Minute:   timeOfDay.Minute(),
+		Weekday:  defaultWeekday,
+		Location: time.UTC,
+	}
+
+	if weekday != "" {
+		if schedule.Weekday, err = parseWeekday(weekday); err != nil {
+			return nil, err
+		}
+	}
+
+	if timezone != "" {
+		if schedule.Location, err = time.LoadLocation(timezone); err != nil {
+			return nil, fmt.Errorf("invalid digest timezone %q: %w", timezone, err)
+		}
+	}
+
+	return schedule, nil
+}
+
+// Previous returns the latest scheduled time which is not after now
+func (s *Schedule) Previous(now time.Time) time.Time {
+	local := now.In(s.Location)
+	year, month, day := local.Date()
+
+	switch s.Every {
+	case ScheduleHourly:
+		return time.Date(year, month, day, local.Hour(), 0, 0, 0, s.Location)
+	case ScheduleWeekly:
+		days := (int(local.Weekday()) - int(s.Weekday) + 7) % 7
+		previous := time.Date(year, month, day-days, s.Hour, s.Minute, 0, 0, s.Location)
+		if previous.After(now) {
+			previous = previous.AddDate(0, 0, -7)
+		}
+
+		return previous
+	default:
+		previous := time.Date(year, month, day, s.Hour, s.Minute, 0, 0, s.Location)
+		if previous.After(now) {
+			previous = previous.AddDate(0, 0, -1)
+		}
+
+		return previous
+	}
+}
+
+// IsDue checks if a scheduled time has passed since the given time
+func (s *Schedule) IsDue(since, now time.Time) bool {
+	return s.Previous(now).After(since)
+}
+
+func parseWeekday(weekday string) (time.Weekday, error) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(day.String(), weekday) {
+			return day, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown digest weekday %q", weekday)
+}
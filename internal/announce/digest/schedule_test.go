@@ -0,0 +1,77 @@
+//go:build unit
+
+package digest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSchedule(t *testing.T) {
+	cases := []struct {
+		caseName   string
+		every      string
+		at         string
+		weekday    string
+		timezone   string
+		expected   *Schedule
+		shouldPass bool
+	}{
+		{"Daily defaults", "daily", "", "", "", &Schedule{Every: ScheduleDaily, Hour: 9, Weekday: time.Monday, Location: time.UTC}, true},
+		{"Weekly", "Weekly", "17:30", "friday", "", &Schedule{Every: ScheduleWeekly, Hour: 17, Minute: 30, Weekday: time.Friday, Location: time.UTC}, true},
+		{"Unknown schedule", "monthly", "", "", "", nil, false},
+		{"Invalid time", "daily", "9am", "", "", nil, false},
+		{"Unknown weekday", "weekly", "", "someday", "", nil, false},
+		{"Unknown timezone", "daily", "", "", "Mars/Olympus", nil, false},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		schedule, err := ParseSchedule(tc.every, tc.at, tc.weekday, tc.timezone)
+		if !tc.shouldPass {
+			assert.NotNil(t, err)
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.Equal(t, tc.expected, schedule)
+	}
+}
+
+func TestSchedule_Previous(t *testing.T) {
+	istanbul, err := time.LoadLocation("Europe/Istanbul")
+	assert.Nil(t, err)
+
+	// 2023-10-04 is a wednesday
+	now := time.Date(2023, 10, 4, 8, 45, 0, 0, time.UTC)
+
+	cases := []struct {
+		caseName string
+		schedule *Schedule
+		expected time.Time
+	}{
+		{"Hourly", &Schedule{Every: ScheduleHourly, Location: time.UTC}, time.Date(2023, 10, 4, 8, 0, 0, 0, time.UTC)},
+		{"Daily today", &Schedule{Every: ScheduleDaily, Hour: 8, Minute: 30, Location: time.UTC}, time.Date(2023, 10, 4, 8, 30, 0, 0, time.UTC)},
+		{"Daily yesterday", &Schedule{Every: ScheduleDaily, Hour: 9, Location: time.UTC}, time.Date(2023, 10, 3, 9, 0, 0, 0, time.UTC)},
+		{"Daily in timezone", &Schedule{Every: ScheduleDaily, Hour: 11, Location: istanbul}, time.Date(2023, 10, 4, 8, 0, 0, 0, time.UTC)},
+		{"Weekly this week", &Schedule{Every: ScheduleWeekly, Hour: 9, Weekday: time.Monday, Location: time.UTC}, time.Date(2023, 10, 2, 9, 0, 0, 0, time.UTC)},
+		{"Weekly last week", &Schedule{Every: ScheduleWeekly, Hour: 9, Weekday: time.Wednesday, Location: time.UTC}, time.Date(2023, 9, 27, 9, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		assert.True(t, tc.expected.Equal(tc.schedule.Previous(now)), "got %s", tc.schedule.Previous(now))
+	}
+}
+
+func TestSchedule_IsDue(t *testing.T) {
+	schedule := &Schedule{Every: ScheduleDaily, Hour: 9, Location: time.UTC}
+	since := time.Date(2023, 10, 4, 8, 0, 0, 0, time.UTC)
+
+	assert.False(t, schedule.IsDue(since, time.Date(2023, 10, 4, 8, 59, 0, 0, time.UTC)))
+	assert.True(t, schedule.IsDue(since, time.Date(2023, 10, 4, 9, 0, 0, 0, time.UTC)))
+}
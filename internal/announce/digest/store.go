@@ -0,0 +1,59 @@
+package digest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/storage/aws"
+)
+
+// State is the pending releases of a digest
+type State struct {
+	// Since is the time the oldest pending release is added at, the digest is sent at the first scheduled time after it
+	Since    time.Time                    `json:"since"`
+	Releases []*announce.AnnouncerPayload `json:"releases"`
+}
+
+// Store persists the pending releases of the digests between the checks and the restarts
+type Store interface {
+	Load(name string) (*State, error)
+	Save(name string, state *State) error
+}
+
+// S3Store is the Store which keeps the pending releases of each digest as a JSON object in the bucket
+type S3Store struct {
+	client     aws.S3ClientAPI
+	bucketName string
+}
+
+// NewS3Store creates a new S3Store
+func NewS3Store(client aws.S3ClientAPI, bucketName string) *S3Store {
+	return &S3Store{
+		client:     client,
+		bucketName: bucketName,
+	}
+}
+
+// Load returns the pending releases of the digest, an empty state is returned if there is not any
+func (s *S3Store) Load(name string) (*State, error) {
+	state := &State{}
+	if !aws.IsObjectExists(s.client, s.bucketName, key(name)) {
+		return state, nil
+	}
+
+	if err := aws.GetObject(s.client, s.bucketName, key(name), state); err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// Save replaces the pending releases of the digest
+func (s *S3Store) Save(name string, state *State) error {
+	return aws.PutObject(s.client, s.bucketName, key(name), state)
+}
+
+func key(name string) string {
+	return fmt.Sprintf("digests/%s.json", name)
+}
@@ -0,0 +1,71 @@
+//go:build unit
+
+package announce
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type flushingAnnouncer struct {
+	recordingAnnouncer
+	flushedAt []time.Time
+}
+
+func (f *flushingAnnouncer) Flush(now time.Time) error {
+	f.flushedAt = append(f.flushedAt, now)
+	return nil
+}
+
+func TestNewDigestPayload(t *testing.T) {
+	releases := []*AnnouncerPayload{
+		{ProjectName: "user1/project1", Version: "v1.0.0", URL: "https://example.com/1"},
+		{ProjectName: "user1/project2", Version: "v2.0.0", URL: "https://example.com/2", Event: types.EventRemoved},
+		{ProjectName: "user1/project1", Version: "v1.1.0", URL: "https://example.com/3"},
+	}
+
+	payload := NewDigestPayload("Daily release digest", releases)
+	assert.True(t, payload.IsDigest())
+	assert.Equal(t, "Daily release digest: 3 releases of 2 projects", payload.Title("default"))
+	assert.Equal(t, "Daily release digest: 3 releases of 2 projects\n\n"+
+		"user1/project1\n- user1/project1 v1.0.0 is out! Check it out at https://example.com/1\n"+
+		"- user1/project1 v1.1.0 is out! Check it out at https://example.com/3\n\n"+
+		"user1/project2\n- user1/project2 v2.0.0 is removed! It was available at https://example.com/2", payload.Summary())
+
+	data := NewTemplateData(payload)
+	assert.Len(t, data.Digest, 2)
+	assert.Equal(t, "user1/project1", data.Digest[0].ProjectName)
+	assert.Len(t, data.Digest[0].Releases, 2)
+	assert.Equal(t, "v1.1.0", data.Digest[0].Releases[1].Version)
+
+	single := NewDigestPayload("Hourly release digest", releases[:1])
+	assert.Equal(t, "Hourly release digest: 1 release of 1 project", single.Subject)
+}
+
+func TestDigestMessage_Truncated(t *testing.T) {
+	var releases []*AnnouncerPayload
+	for i := 0; i < maxDigestReleases+5; i++ {
+		releases = append(releases, &AnnouncerPayload{ProjectName: "user1/project1", Version: fmt.Sprintf("v1.0.%d", i)})
+	}
+
+	message := DigestMessage("digest", releases)
+	assert.Equal(t, maxDigestReleases, strings.Count(message, "\n- "))
+	assert.True(t, strings.HasSuffix(message, "…and 5 more releases"))
+}
+
+func TestFlush(t *testing.T) {
+	now := time.Date(2023, 10, 2, 9, 0, 0, 0, time.UTC)
+	flusher := &flushingAnnouncer{}
+
+	assert.Nil(t, Flush(flusher, now))
+	assert.Nil(t, Flush(NewPolicyAnnouncer(NewTemplateAnnouncer(flusher, Templates{}), Policy{}), now))
+	assert.Equal(t, []time.Time{now, now}, flusher.flushedAt)
+
+	// announcers which do not batch the payloads are ignored
+	assert.Nil(t, Flush(NewPolicyAnnouncer(&recordingAnnouncer{}, Policy{}), now))
+}
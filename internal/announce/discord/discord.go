@@ -0,0 +1,273 @@
+package discord
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/notes"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/types"
+)
+
+const (
+	// embed limits of the Discord API
+	maxTitleLength       = 256
+	maxDescriptionLength = 4096
+	maxFieldLength       = 1024
+	// maxEmbedLength is the limit of the title, the description and the fields of an embed in total
+	maxEmbedLength = 6000
+
+	// maxRetries is the number of retries on rate limited requests
+	maxRetries = 3
+	// maxRetryAfter caps the waiting time requested by Discord so a global rate limit can not block the checks
+	maxRetryAfter = time.Minute
+)
+
+// embed colors by the change level of the release
+const (
+	colorNew      = 0x2ECC71
+	colorUpdated  = 0xF1C40F
+	colorRemoved  = 0x95A5A6
+	colorBreaking = 0xE67E22
+	colorSecurity = 0xE74C3C
+)
+
+type webhookMessage struct {
+	Content         string           `json:"content,omitempty"`
+	Username        string           `json:"username,omitempty"`
+	AvatarUrl       string           `json:"avatar_url,omitempty"`
+	Embeds          []embed          `json:"embeds"`
+	AllowedMentions *allowedMentions `json:"allowed_mentions,omitempty"`
+}
+
+type embed struct {
+	Title       string       `json:"title"`
+	Url         string       `json:"url,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Color       int          `json:"color"`
+	Timestamp   string       `json:"timestamp,omitempty"`
+	Fields      []embedField `json:"fields,omitempty"`
+}
+
+type embedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+type allowedMentions struct {
+	Parse []string `json:"parse"`
+}
+
+// rateLimitResponse is the body of the 429 responses, RetryAfter is in seconds
+type rateLimitResponse struct {
+	RetryAfter float64 `json:"retry_after"`
+	Global     bool    `json:"global"`
+}
+
+// DiscordAnnouncer is the announcer that posts the releases as rich embeds to a Discord webhook
+type DiscordAnnouncer struct {
+	WebhookUrl string
+	Username   string
+	AvatarUrl  string
+	client     *http.Client
+	sleep      func(time.Duration)
+}
+
+// NewDiscordAnnouncer creates a new DiscordAnnouncer, username and avatarUrl override the defaults of the webhook
+// if set.
+func NewDiscordAnnouncer(webhookUrl, username, avatarUrl string) (*DiscordAnnouncer, error) {
+	if webhookUrl == "" {
+		return nil, errors.New("discord webhook url is required")
+	}
+
+	return &DiscordAnnouncer{
+		WebhookUrl: webhookUrl,
+		Username:   username,
+		AvatarUrl:  avatarUrl,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		sleep:      time.Sleep,
+	}, nil
+}
+
+// Notify posts the embed to the webhook, it waits and retries as long as Discord asks with 429 responses.
+// Routed payloads mention the recipients, which are expected in Discord mention format like <@&roleId>.
+func (d *DiscordAnnouncer) Notify(payload *announce.AnnouncerPayload) error {
+	body, err := json.Marshal(d.buildMessage(payload))
+	if err != nil {
+		return err
+	}
+
+	for attempt := 0; ; attempt++ {
+		retryAfter, err := d.post(body)
+		if err == nil {
+			return nil
+		}
+
+		if retryAfter < 0 || attempt >= maxRetries {
+			return err
+		}
+
+		d.sleep(retryAfter)
+	}
+}
+
+// IsEnabled checks if the DiscordAnnouncer is enabled.
+func (d *DiscordAnnouncer) IsEnabled() bool {
+	return d.WebhookUrl != ""
+}
+
+// post sends the message once, the returned duration is non-negative if the request is rate limited and should be
+// retried after that long
+func (d *DiscordAnnouncer) post(body []byte) (time.Duration, error) {
+	resp, err := d.client.Post(d.WebhookUrl, "application/json", bytes.NewReader(body))
+	if err != nil {
+		// the webhook url contains the token, so the error is not wrapped to avoid leaking it to the logs
+		return -1, errors.New("an error occurred while calling discord webhook")
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return 0, nil
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return retryAfterOf(resp.Header, respBody), errors.New("discord webhook is rate limited")
+	}
+
+	return -1, fmt.Errorf("discord webhook returned status code %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+}
+
+// retryAfterOf reads the waiting time from the body of the 429 response, falls back to the Retry-After header
+func retryAfterOf(header http.Header, body []byte) time.Duration {
+	var response rateLimitResponse
+	seconds := 0.0
+	if err := json.Unmarshal(body, &response); err == nil && response.RetryAfter > 0 {
+		seconds = response.RetryAfter
+	} else if value, err := strconv.ParseFloat(header.Get("Retry-After"), 64); err == nil {
+		seconds = value
+	}
+
+	retryAfter := time.Duration(seconds * float64(time.Second))
+	if retryAfter > maxRetryAfter {
+		return maxRetryAfter
+	}
+
+	return retryAfter
+}
+
+func (d *DiscordAnnouncer) buildMessage(payload *announce.AnnouncerPayload) *webhookMessage {
+	e := embed{
+		Title: notes.Truncate(title(payload), maxTitleLength),
+		Url:   payload.URL,
+		Color: color(payload),
+		Fields: []embedField{
+			{Name: "Version", Value: payload.Version, Inline: true},
+			{Name: "Event", Value: string(payload.GetEvent()), Inline: true},
+		},
+	}
+
+	// the summary, which is the rendered message template if it is set, leads the release notes
+	description := payload.Summary()
+	// the digests list the releases in the description instead of the fields of a single release
+	if payload.IsDigest() {
+		e.Fields = nil
+	} else if releaseNotes := notes.ToMarkdown(payload.Notes); releaseNotes != "" {
+		description += "\n\n" + releaseNotes
+	}
+
+	if payload.PublishedAt != nil {
+		e.Timestamp = payload.PublishedAt.UTC().Format(time.RFC3339)
+	}
+
+	if len(payload.Changes) > 0 {
+		e.Fields = append(e.Fields, embedField{Name: "Changes", Value: strings.Join(payload.Changes, ", "), Inline: true})
+	}
+
+	if advisories := payload.Advisories(); len(advisories) > 0 {
+		e.Fields = append(e.Fields, embedField{Name: "Security advisories", Value: notes.Truncate(strings.Join(advisories, ", "), maxFieldLength)})
+	}
+
+	if breakingChanges := notes.ToMarkdown(payload.BreakingChanges); breakingChanges != "" {
+		e.Fields = append(e.Fields, embedField{Name: "⚠️ Breaking changes", Value: notes.Truncate(breakingChanges, maxFieldLength)})
+	}
+
+	// the description gets what is left of the embed limit after the title and the fields
+	if limit := min(maxDescriptionLength, maxEmbedLength-embedLength(e)); limit > 0 {
+		e.Description = notes.Truncate(description, limit)
+	}
+
+	msg := &webhookMessage{
+		Username:  d.Username,
+		AvatarUrl: d.AvatarUrl,
+		Embeds:    []embed{e},
+		// nothing is pinged unless the payload is routed or urgent
+		AllowedMentions: &allowedMentions{Parse: []string{}},
+	}
+
+	mentions := strings.Join(payload.Recipients, " ")
+	if payload.IsUrgent() && mentions == "" {
+		mentions = "@here"
+	}
+
+	if mentions != "" {
+		msg.Content = mentions
+		msg.AllowedMentions.Parse = []string{"roles", "users", "everyone"}
+	}
+
+	if payload.IsUrgent() {
+		msg.Content = strings.TrimSpace("🚨 " + msg.Content)
+	}
+
+	return msg
+}
+
+// embedLength returns the number of the characters of the embed which count towards maxEmbedLength
+func embedLength(e embed) int {
+	length := utf8.RuneCountInString(e.Title) + utf8.RuneCountInString(e.Description)
+	for _, field := range e.Fields {
+		length += utf8.RuneCountInString(field.Name) + utf8.RuneCountInString(field.Value)
+	}
+
+	return length
+}
+
+func title(payload *announce.AnnouncerPayload) string {
+	if payload.Subject != "" {
+		return payload.Subject
+	}
+
+	switch payload.GetEvent() {
+	case types.EventUpdated:
+		return fmt.Sprintf("%s %s is updated", payload.ProjectName, payload.Version)
+	case types.EventRemoved:
+		return fmt.Sprintf("%s %s is removed", payload.ProjectName, payload.Version)
+	default:
+		return fmt.Sprintf("%s %s is out!", payload.ProjectName, payload.Version)
+	}
+}
+
+// color returns the embed color by the change level, security releases come first and breaking changes second
+func color(payload *announce.AnnouncerPayload) int {
+	switch {
+	case payload.Security != nil:
+		return colorSecurity
+	case payload.HasBreakingChanges():
+		return colorBreaking
+	case payload.GetEvent() == types.EventRemoved:
+		return colorRemoved
+	case payload.GetEvent() == types.EventUpdated:
+		return colorUpdated
+	default:
+		return colorNew
+	}
+}
@@ -0,0 +1,209 @@
+//go:build unit
+
+package discord
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeWebhook is a Discord webhook which responds with the given status codes in order, then 204
+type fakeWebhook struct {
+	mu        sync.Mutex
+	responses []int
+	messages  []webhookMessage
+}
+
+func newFakeWebhook(t *testing.T, responses ...int) (*fakeWebhook, string) {
+	webhook := &fakeWebhook{responses: responses}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg webhookMessage
+		_ = json.NewDecoder(r.Body).Decode(&msg)
+
+		webhook.mu.Lock()
+		defer webhook.mu.Unlock()
+		webhook.messages = append(webhook.messages, msg)
+
+		status := http.StatusNoContent
+		if len(webhook.responses) > 0 {
+			status, webhook.responses = webhook.responses[0], webhook.responses[1:]
+		}
+
+		switch status {
+		case http.StatusTooManyRequests:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"message":"You are being rate limited.","retry_after":1.5,"global":false}`))
+		case http.StatusBadRequest:
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"message":"Invalid Form Body","code":50035}`))
+		default:
+			w.WriteHeader(status)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return webhook, server.URL
+}
+
+func TestNewDiscordAnnouncer(t *testing.T) {
+	announcer, err := NewDiscordAnnouncer("", "", "")
+	assert.Nil(t, announcer)
+	assert.NotNil(t, err)
+
+	announcer, err = NewDiscordAnnouncer("https://discord.com/api/webhooks/1/token", "releases", "")
+	assert.Nil(t, err)
+	assert.True(t, announcer.IsEnabled())
+}
+
+func TestDiscordAnnouncer_Notify(t *testing.T) {
+	cases := []struct {
+		caseName         string
+		responses        []int
+		expectedRequests int
+		expectedSleeps   []time.Duration
+		shouldPass       bool
+	}{
+		{"Successful notification", nil, 1, nil, true},
+		{"Retries after rate limit", []int{http.StatusTooManyRequests, http.StatusTooManyRequests}, 3, []time.Duration{1500 * time.Millisecond, 1500 * time.Millisecond}, true},
+		{"Gives up after max retries", []int{429, 429, 429, 429, 429}, maxRetries + 1, []time.Duration{1500 * time.Millisecond, 1500 * time.Millisecond, 1500 * time.Millisecond}, false},
+		{"Does not retry bad requests", []int{http.StatusBadRequest}, 1, nil, false},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		webhook, url := newFakeWebhook(t, tc.responses...)
+		announcer, err := NewDiscordAnnouncer(url, "releases", "")
+		assert.Nil(t, err)
+
+		var sleeps []time.Duration
+		announcer.sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+
+		err = announcer.Notify(&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", URL: "https://example.com"})
+		if tc.shouldPass {
+			assert.Nil(t, err)
+		} else {
+			assert.NotNil(t, err)
+		}
+
+		assert.Len(t, webhook.messages, tc.expectedRequests)
+		assert.Equal(t, tc.expectedSleeps, sleeps)
+		assert.Equal(t, "releases", webhook.messages[0].Username)
+	}
+}
+
+func TestDiscordAnnouncer_buildMessage(t *testing.T) {
+	publishedAt := time.Date(2023, 5, 1, 10, 0, 0, 0, time.FixedZone("UTC+3", 3*60*60))
+	announcer, err := NewDiscordAnnouncer("https://discord.com/api/webhooks/1/token", "", "")
+	assert.Nil(t, err)
+
+	cases := []struct {
+		caseName        string
+		payload         *announce.AnnouncerPayload
+		expectedTitle   string
+		expectedColor   int
+		expectedContent string
+		expectedFields  []string
+	}{
+		{
+			"New release",
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", URL: "https://example.com", PublishedAt: &publishedAt,
+				Notes: "<p>Fixes <strong>bug</strong></p>"},
+			"x-project v1.0.0 is out!", colorNew, "", []string{"Version", "Event"},
+		},
+		{
+			"Updated release",
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", Event: types.EventUpdated, Changes: []string{"url"}},
+			"x-project v1.0.0 is updated", colorUpdated, "", []string{"Version", "Event", "Changes"},
+		},
+		{
+			"Removed release",
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", Event: types.EventRemoved},
+			"x-project v1.0.0 is removed", colorRemoved, "", []string{"Version", "Event"},
+		},
+		{
+			"Breaking changes routed to a role",
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v2.0.0", BreakingChanges: "<ul><li>Removed foo</li></ul>",
+				Recipients: []string{"<@&123>"}},
+			"x-project v2.0.0 is out!", colorBreaking, "<@&123>", []string{"Version", "Event", "⚠️ Breaking changes"},
+		},
+		{
+			"Urgent security release",
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.1", BreakingChanges: "<p>Removed foo</p>",
+				Security: &types.Security{CVEs: []string{"CVE-2023-1234"}}, Priority: announce.PriorityHigh},
+			"x-project v1.0.1 is out!", colorSecurity, "🚨 @here", []string{"Version", "Event", "Security advisories", "⚠️ Breaking changes"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		msg := announcer.buildMessage(tc.payload)
+		assert.Len(t, msg.Embeds, 1)
+		assert.Equal(t, tc.expectedTitle, msg.Embeds[0].Title)
+		assert.Equal(t, tc.expectedColor, msg.Embeds[0].Color)
+		assert.Equal(t, tc.expectedContent, msg.Content)
+
+		var fields []string
+		for _, field := range msg.Embeds[0].Fields {
+			fields = append(fields, field.Name)
+		}
+
+		assert.Equal(t, tc.expectedFields, fields)
+		if tc.expectedContent == "" {
+			assert.Empty(t, msg.AllowedMentions.Parse)
+		} else {
+			assert.NotEmpty(t, msg.AllowedMentions.Parse)
+		}
+	}
+
+	msg := announcer.buildMessage(cases[0].payload)
+	assert.Equal(t, "2023-05-01T07:00:00Z", msg.Embeds[0].Timestamp)
+	assert.Equal(t, "x-project v1.0.0 is out! Check it out at https://example.com\n\nFixes **bug**", msg.Embeds[0].Description)
+	assert.Equal(t, "https://example.com", msg.Embeds[0].Url)
+}
+
+func TestDiscordAnnouncer_buildMessage_embedLength(t *testing.T) {
+	announcer, err := NewDiscordAnnouncer("https://discord.com/api/webhooks/1/token", "", "")
+	assert.Nil(t, err)
+
+	msg := announcer.buildMessage(&announce.AnnouncerPayload{
+		ProjectName:     strings.Repeat("x-project", 50),
+		Version:         "v2.0.0",
+		Notes:           strings.Repeat("<p>Fixes a bug in the parser</p>", 500),
+		BreakingChanges: strings.Repeat("<p>Removed the deprecated api</p>", 100),
+		Security:        &types.Security{CVEs: []string{strings.Repeat("CVE-2023-1234 ", 100)}},
+	})
+
+	e := msg.Embeds[0]
+	assert.NotEmpty(t, e.Description)
+	assert.LessOrEqual(t, utf8.RuneCountInString(e.Description), maxDescriptionLength)
+	assert.LessOrEqual(t, embedLength(e), maxEmbedLength)
+}
+
+func TestDiscordAnnouncer_Notify_customMessage(t *testing.T) {
+	webhook, url := newFakeWebhook(t)
+	announcer, err := NewDiscordAnnouncer(url, "", "")
+	assert.Nil(t, err)
+
+	message, err := announce.ParseTemplate("message", "{{ .ProjectName }} shipped {{ .Version }}")
+	assert.Nil(t, err)
+
+	templated := announce.NewTemplateAnnouncer(announcer, announce.Templates{Message: message})
+	assert.Nil(t, templated.Notify(&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0",
+		URL: "https://example.com", Notes: "<p>Fixes <strong>bug</strong></p>"}))
+
+	assert.Len(t, webhook.messages, 1)
+	assert.Equal(t, "x-project shipped v1.0.0\n\nFixes **bug**", webhook.messages[0].Embeds[0].Description)
+}
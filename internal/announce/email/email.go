@@ -1,13 +1,20 @@
 package email
 
 import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	"text/template"
+
 	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/types"
 )
 
 // Sender interface ensures that any specific email service (like SMTP, SES, etc.)
 // can be integrated into the EmailAnnouncer.
 type Sender interface {
-	Send(to, cc, bcc []string, from, projectName, version, url string) error
+	Send(to, cc, bcc []string, from string, payload *EmailPayload) error
 }
 
 // EmailPayload is the payload that is sent to the email service.
@@ -15,6 +22,10 @@ type Sender interface {
 type EmailPayload struct {
 	Subject string
 	Content string
+	// HTML is the HTML alternative of the content, the email is sent as plain text only if it is empty
+	HTML string
+	// Urgent is set for the payloads routed with high priority, senders may mark the email as important
+	Urgent bool
 }
 
 // EmailAnnouncer is the announcer that sends the email. It contains the sender and the email addresses.
@@ -24,22 +35,111 @@ type EmailAnnouncer struct {
 	To   []string
 	Cc   []string
 	Bcc  []string
+	// TextTemplate renders the plain text content, the default one is used if it is nil
+	TextTemplate *template.Template
+	// HTMLTemplate renders the HTML alternative, the default one is used if it is nil
+	HTMLTemplate *htmltemplate.Template
 }
 
-// NewEmailAnnouncer creates a new EmailAnnouncer. It requires a sender and the email addresses.
-func NewEmailAnnouncer(sender Sender, from string, to, cc, bcc []string) *EmailAnnouncer {
-	return &EmailAnnouncer{
-		sender,
-		from,
-		to,
-		cc,
-		bcc,
+// NewEmailAnnouncer creates a new EmailAnnouncer. It requires a sender and the email addresses, textTemplate and
+// htmlTemplate override the default templates of the plain text and the HTML bodies if they are set.
+func NewEmailAnnouncer(sender Sender, from string, to, cc, bcc []string, textTemplate, htmlTemplate string) (*EmailAnnouncer, error) {
+	announcer := &EmailAnnouncer{
+		Sender: sender,
+		From:   from,
+		To:     to,
+		Cc:     cc,
+		Bcc:    bcc,
+	}
+
+	var err error
+	if textTemplate != "" {
+		if announcer.TextTemplate, err = announce.ParseTemplate("text", textTemplate); err != nil {
+			return nil, fmt.Errorf("invalid email text template: %w", err)
+		}
+	}
+
+	if htmlTemplate != "" {
+		if announcer.HTMLTemplate, err = announce.ParseHTMLTemplate("html", htmlTemplate); err != nil {
+			return nil, fmt.Errorf("invalid email html template: %w", err)
+		}
+	}
+
+	return announcer, nil
+}
+
+var (
+	defaultText = template.Must(announce.ParseTemplate("text", defaultTextTemplate))
+	defaultHTML = htmltemplate.Must(announce.ParseHTMLTemplate("html", defaultHTMLTemplate))
+)
+
+// NewEmailPayload creates the subject, the plain text content and the HTML alternative of the email from the
+// announcer payload. The default templates are used for the nil templates.
+func NewEmailPayload(payload *announce.AnnouncerPayload, textTemplate *template.Template, htmlTemplate *htmltemplate.Template) (*EmailPayload, error) {
+	var subject string
+	switch payload.GetEvent() {
+	case types.EventUpdated:
+		subject = fmt.Sprintf("Release update alert for project %s!", payload.ProjectName)
+	case types.EventRemoved:
+		subject = fmt.Sprintf("Release removal alert for project %s!", payload.ProjectName)
+	default:
+		subject = fmt.Sprintf("New release alert for project %s!", payload.ProjectName)
+	}
+
+	subject = payload.Title(subject)
+	if payload.IsUrgent() {
+		subject = fmt.Sprintf("[URGENT] %s", subject)
+	}
+
+	if textTemplate == nil {
+		textTemplate = defaultText
 	}
+
+	if htmlTemplate == nil {
+		htmlTemplate = defaultHTML
+	}
+
+	data := announce.NewTemplateData(payload)
+	content, err := announce.Render(textTemplate, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render the email text template: %w", err)
+	}
+
+	var html strings.Builder
+	if err := htmlTemplate.Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("failed to render the email html template: %w", err)
+	}
+
+	return &EmailPayload{
+		Subject: subject,
+		Content: content,
+		HTML:    html.String(),
+		Urgent:  payload.IsUrgent(),
+	}, nil
 }
 
-// Notify sends the email to the recipients.
+// Notify sends the email to the recipients, routed payloads override the "to" addresses.
 func (e *EmailAnnouncer) Notify(payload *announce.AnnouncerPayload) error {
-	return e.Send(e.To, e.Cc, e.Bcc, e.From, payload.ProjectName, payload.Version, payload.URL)
+	to := e.To
+	if len(payload.Recipients) > 0 {
+		to = payload.Recipients
+	}
+
+	emailPayload, err := NewEmailPayload(payload, e.TextTemplate, e.HTMLTemplate)
+	if err != nil {
+		return err
+	}
+
+	return e.Send(to, e.Cc, e.Bcc, e.From, emailPayload)
+}
+
+// Close closes the sender if it keeps a connection open between the emails
+func (e *EmailAnnouncer) Close() error {
+	if closer, ok := e.Sender.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
 }
 
 // IsEnabled checks if the EmailAnnouncer is enabled.
@@ -3,15 +3,21 @@ package email
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/types"
 	"github.com/stretchr/testify/assert"
 )
 
 type MockSender struct {
+	to      []string
+	payload *EmailPayload
 }
 
-func (s *MockSender) Send(to, cc, bcc []string, from, projectName, version, url string) error {
+func (s *MockSender) Send(to, cc, bcc []string, from string, payload *EmailPayload) error {
+	s.to = to
+	s.payload = payload
 	return errors.New("injected error")
 }
 
@@ -48,7 +54,7 @@ func TestNewEmailAnnouncer(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := NewEmailAnnouncer(tt.args.sender, tt.args.from, tt.args.to, tt.args.cc, tt.args.bcc); got == nil {
+			if got, err := NewEmailAnnouncer(tt.args.sender, tt.args.from, tt.args.to, tt.args.cc, tt.args.bcc, "", ""); got == nil || err != nil {
 				t.Errorf("NewEmailAnnouncer() = %v, want %v", got, tt.want)
 			}
 		})
@@ -84,3 +90,136 @@ func TestEmailAnnouncer_IsEnabled(t *testing.T) {
 
 	assert.True(t, announcer.IsEnabled())
 }
+
+func TestNewEmailPayload(t *testing.T) {
+	payload, err := NewEmailPayload(&announce.AnnouncerPayload{
+		ProjectName: "projectName",
+		Version:     "version",
+		URL:         "url",
+		Event:       types.EventRemoved,
+	}, nil, nil)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "Release removal alert for project projectName!", payload.Subject)
+	assert.Equal(t, "projectName version is removed! It was available at url", payload.Content)
+
+	payload, err = NewEmailPayload(&announce.AnnouncerPayload{
+		ProjectName:     "projectName",
+		Version:         "version",
+		URL:             "url",
+		Notes:           "<p>Bug <strong>fixes</strong></p>",
+		BreakingChanges: "<ul><li>BREAKING: drop v1 api</li></ul>",
+	}, nil, nil)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "New release alert for project projectName!", payload.Subject)
+	assert.Equal(t, "projectName version is out! Check it out at url\n\n!!! BREAKING CHANGES !!!\n\n- BREAKING: drop v1 api"+
+		"\n\nRelease notes:\n\nBug fixes", payload.Content)
+}
+
+func TestEmailAnnouncer_NotifyRouted(t *testing.T) {
+	sender := &MockSender{}
+	announcer, _ := NewEmailAnnouncer(sender, "from", []string{"to"}, nil, nil, "", "")
+
+	err := announcer.Notify(&announce.AnnouncerPayload{
+		ProjectName: "projectName",
+		Version:     "version",
+		URL:         "url",
+		Priority:    announce.PriorityHigh,
+		Recipients:  []string{"security@example.com"},
+	})
+
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{"security@example.com"}, sender.to)
+	assert.Equal(t, "[URGENT] New release alert for project projectName!", sender.payload.Subject)
+}
+
+func TestNewEmailPayload_HTML(t *testing.T) {
+	publishedAt := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	payload, err := NewEmailPayload(&announce.AnnouncerPayload{
+		ProjectName: "user1/project1",
+		Version:     "v1.0.0",
+		URL:         "https://example.com/releases/v1.0.0",
+		PublishedAt: &publishedAt,
+		Notes:       `<p>Fixes <strong>bug</strong> <a href="javascript:alert(1)">here</a></p><script>alert(1)</script>`,
+		Security:    &types.Security{CVEs: []string{"CVE-2023-1234"}},
+	}, nil, nil)
+	assert.Nil(t, err)
+
+	assert.Contains(t, payload.HTML, "Security release")
+	assert.Contains(t, payload.HTML, "Published on October 1, 2023 12:00 UTC")
+	assert.Contains(t, payload.HTML, `<a href="https://nvd.nist.gov/vuln/detail/CVE-2023-1234"`)
+	assert.Contains(t, payload.HTML, "Fixes <strong>bug</strong> here")
+	assert.Contains(t, payload.HTML, `<a href="https://example.com/releases/v1.0.0"`)
+	assert.NotContains(t, payload.HTML, "javascript")
+	assert.NotContains(t, payload.HTML, "<script>")
+
+	removed, err := NewEmailPayload(&announce.AnnouncerPayload{ProjectName: "user1/project1", Version: "v1.0.0",
+		URL: "https://example.com", Event: types.EventRemoved}, nil, nil)
+	assert.Nil(t, err)
+	assert.Contains(t, removed.HTML, "v1.0.0 is removed")
+	assert.NotContains(t, removed.HTML, "View release")
+}
+
+func TestNewEmailAnnouncer_Templates(t *testing.T) {
+	cases := []struct {
+		caseName        string
+		textTemplate    string
+		htmlTemplate    string
+		expectedContent string
+		expectedHTML    string
+		shouldPass      bool
+	}{
+		{"Custom templates", "{{ .ProjectName }} {{ .Version }}", "<b>{{ .Version }}</b>{{ htmlNotes .Notes }}",
+			"user1/project1 v1.0.0", "<b>v1.0.0</b><em>notes</em> &lt;3", true},
+		{"Escaped HTML fields", "", "<p>{{ .Notes }}</p>", "", "<p>&lt;em&gt;notes&lt;/em&gt; &amp;lt;3</p>", true},
+		{"Unknown field in text", "{{ .Tag }}", "", "", "", false},
+		{"Invalid html", "", "{{ if .Version }}", "", "", false},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		sender := &MockSender{}
+		announcer, err := NewEmailAnnouncer(sender, "from", []string{"to"}, nil, nil, tc.textTemplate, tc.htmlTemplate)
+		if !tc.shouldPass {
+			assert.NotNil(t, err)
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.NotNil(t, announcer.Notify(&announce.AnnouncerPayload{ProjectName: "user1/project1", Version: "v1.0.0",
+			URL: "https://example.com", Notes: "<em>notes</em> &lt;3"}))
+		if tc.expectedContent != "" {
+			assert.Equal(t, tc.expectedContent, sender.payload.Content)
+		}
+
+		assert.Equal(t, tc.expectedHTML, sender.payload.HTML)
+	}
+}
+
+type closingSender struct {
+	MockSender
+	closed bool
+}
+
+func (s *closingSender) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestEmailAnnouncer_Close(t *testing.T) {
+	sender := &closingSender{}
+	announcer, err := NewEmailAnnouncer(sender, "from@example.com", []string{"to@example.com"}, nil, nil, "", "")
+	assert.Nil(t, err)
+
+	// the sender is closed through the wrappers of the announcer
+	wrapped := announce.NewPolicyAnnouncer(announce.NewTemplateAnnouncer(announcer, announce.Templates{}), announce.Policy{})
+	assert.Nil(t, announce.Close(wrapped))
+	assert.True(t, sender.closed)
+
+	// the senders without a connection are not closed
+	announcer, err = NewEmailAnnouncer(&MockSender{}, "from@example.com", []string{"to@example.com"}, nil, nil, "", "")
+	assert.Nil(t, err)
+	assert.Nil(t, announcer.Close())
+}
@@ -2,10 +2,11 @@ package ses
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/aws/aws-sdk-go-v2/service/ses"
 	"github.com/aws/aws-sdk-go-v2/service/ses/types"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/email"
 )
 
 // SESClient is the interface that contains SendEmail function.
@@ -26,11 +27,8 @@ func NewSESSender(client SESClient) *SESSender {
 	}
 }
 
-// Send sends the email to the recipients.
-func (s *SESSender) Send(to, cc, bcc []string, from, projectName, version, url string) error {
-	content := fmt.Sprintf("%s %s is out! Check it out at %s", projectName, version, url)
-	subject := fmt.Sprintf("New release alert for project %s!", projectName)
-
+// Send sends the email to the recipients, SES sends it as multipart/alternative if the HTML body is set.
+func (s *SESSender) Send(to, cc, bcc []string, from string, payload *email.EmailPayload) error {
 	input := &ses.SendEmailInput{
 		Destination: &types.Destination{
 			ToAddresses:  to,
@@ -40,16 +38,22 @@ func (s *SESSender) Send(to, cc, bcc []string, from, projectName, version, url s
 		Message: &types.Message{
 			Body: &types.Body{
 				Text: &types.Content{
-					Data: &content,
+					Data: &payload.Content,
 				},
 			},
 			Subject: &types.Content{
-				Data: &subject,
+				Data: &payload.Subject,
 			},
 		},
 		Source: &from,
 	}
 
+	if payload.HTML != "" {
+		input.Message.Body.Html = &types.Content{
+			Data: &payload.HTML,
+		}
+	}
+
 	_, err := s.client.SendEmail(context.Background(), input)
 	return err
 }
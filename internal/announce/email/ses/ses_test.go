@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/service/ses"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/email"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -31,6 +32,25 @@ func TestSESSender_Send(t *testing.T) {
 	assert.NotNil(t, sender)
 
 	err := sender.Send([]string{"bilalcaliskan@protonmail.com"}, []string{}, []string{}, "bilalcaliskan@protonmail.com",
-		"x-project", "1.0.0", "https://github.com/x-group/x-project")
+		&email.EmailPayload{Subject: "New release alert for project x-project!", Content: "x-project 1.0.0 is out!"})
 	assert.NotNil(t, err)
 }
+
+func TestSESSender_SendHTML(t *testing.T) {
+	mockSender := new(MockEmailSender)
+	mockSender.On("SendEmail", mock.Anything, mock.Anything, mock.Anything).Return(&ses.SendEmailOutput{}, nil)
+	sender := NewSESSender(mockSender)
+
+	assert.Nil(t, sender.Send([]string{"to@example.com"}, nil, nil, "from@example.com",
+		&email.EmailPayload{Subject: "subject", Content: "content", HTML: "<p>content</p>"}))
+	assert.Nil(t, sender.Send([]string{"to@example.com"}, nil, nil, "from@example.com",
+		&email.EmailPayload{Subject: "subject", Content: "content"}))
+
+	html := mockSender.Calls[0].Arguments.Get(1).(*ses.SendEmailInput).Message.Body
+	assert.Equal(t, "content", *html.Text.Data)
+	assert.Equal(t, "<p>content</p>", *html.Html.Data)
+
+	text := mockSender.Calls[1].Arguments.Get(1).(*ses.SendEmailInput).Message.Body
+	assert.Equal(t, "content", *text.Text.Data)
+	assert.Nil(t, text.Html)
+}
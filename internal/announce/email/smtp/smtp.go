@@ -0,0 +1,398 @@
+package smtp
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/email"
+)
+
+const (
+	// SecurityStartTLS upgrades the plain connection to TLS with STARTTLS command, it is the default
+	SecurityStartTLS = "starttls"
+	// SecurityTLS connects with implicit TLS, generally on port 465
+	SecurityTLS = "tls"
+	// SecurityNone does not encrypt the connection, should only be used for local relays
+	SecurityNone = "none"
+
+	// AuthPlain authenticates with PLAIN mechanism, it is the default
+	AuthPlain = "plain"
+	// AuthLogin authenticates with LOGIN mechanism
+	AuthLogin = "login"
+
+	dialTimeout = 10 * time.Second
+)
+
+// SMTPSender is the struct that implements Sender interface over SMTP. It keeps the connection open between
+// the emails and reconnects if the server closed it in the meantime.
+type SMTPSender struct {
+	host      string
+	port      int
+	username  string
+	password  string
+	security  string
+	auth      string
+	tlsConfig *tls.Config
+
+	mu     sync.Mutex
+	client *smtp.Client
+}
+
+// NewSMTPSender creates a new SMTPSender. security is one of starttls, tls and none; auth is one of plain and login.
+// Authentication is skipped if the username is empty.
+func NewSMTPSender(host string, port int, username, password, security, auth string) (*SMTPSender, error) {
+	if host == "" || port <= 0 {
+		return nil, errors.New("smtp host and port are required")
+	}
+
+	if security == "" {
+		security = SecurityStartTLS
+	}
+
+	if security != SecurityStartTLS && security != SecurityTLS && security != SecurityNone {
+		return nil, fmt.Errorf("unknown smtp security %q", security)
+	}
+
+	if auth == "" {
+		auth = AuthPlain
+	}
+
+	if auth != AuthPlain && auth != AuthLogin {
+		return nil, fmt.Errorf("unknown smtp auth mechanism %q", auth)
+	}
+
+	return &SMTPSender{
+		host:      host,
+		port:      port,
+		username:  username,
+		password:  password,
+		security:  security,
+		auth:      auth,
+		tlsConfig: &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12},
+	}, nil
+}
+
+// Send sends the email to the recipients over the reused SMTP connection.
+func (s *SMTPSender) Send(to, cc, bcc []string, from string, payload *email.EmailPayload) error {
+	msg, err := buildMessage(to, cc, from, payload)
+	if err != nil {
+		return err
+	}
+
+	// the envelope takes the bare addresses, the display names are only kept in the headers
+	envelope, err := envelopeAddresses(append(append(append([]string{from}, to...), cc...), bcc...))
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	client, err := s.getClient()
+	if err != nil {
+		return err
+	}
+
+	if err := s.send(client, envelope[0], envelope[1:], msg); err != nil {
+		// the connection state is unknown after a failure, so it is not reused
+		s.reset()
+		return err
+	}
+
+	return nil
+}
+
+// Close closes the reused SMTP connection if there is any.
+func (s *SMTPSender) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.client == nil {
+		return nil
+	}
+
+	err := s.client.Quit()
+	if err != nil {
+		_ = s.client.Close()
+	}
+
+	s.client = nil
+	return err
+}
+
+func (s *SMTPSender) send(client *smtp.Client, from string, recipients []string, msg []byte) error {
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+
+	for _, recipient := range recipients {
+		if err := client.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err := writer.Write(msg); err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
+// getClient returns the open connection if it is still alive, creates a new one otherwise
+func (s *SMTPSender) getClient() (*smtp.Client, error) {
+	if s.client != nil {
+		if err := s.client.Noop(); err == nil {
+			return s.client, nil
+		}
+
+		s.reset()
+	}
+
+	client, err := s.connect()
+	if err != nil {
+		return nil, err
+	}
+
+	s.client = client
+	return client, nil
+}
+
+func (s *SMTPSender) connect() (*smtp.Client, error) {
+	addr := net.JoinHostPort(s.host, strconv.Itoa(s.port))
+
+	var conn net.Conn
+	var err error
+	if s.security == SecurityTLS {
+		conn, err = tls.DialWithDialer(&net.Dialer{Timeout: dialTimeout}, "tcp", addr, s.tlsConfig)
+	} else {
+		conn, err = net.DialTimeout("tcp", addr, dialTimeout)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	if err := s.prepare(client); err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// prepare greets the server, upgrades the connection with STARTTLS and authenticates if configured
+func (s *SMTPSender) prepare(client *smtp.Client) error {
+	if hostname, err := os.Hostname(); err == nil {
+		if err := client.Hello(hostname); err != nil {
+			return err
+		}
+	}
+
+	if s.security == SecurityStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("smtp server does not support STARTTLS")
+		}
+
+		if err := client.StartTLS(s.tlsConfig); err != nil {
+			return err
+		}
+	}
+
+	if s.username == "" {
+		return nil
+	}
+
+	var auth smtp.Auth
+	if s.auth == AuthLogin {
+		auth = &loginAuth{username: s.username, password: s.password, host: s.host}
+	} else {
+		auth = smtp.PlainAuth("", s.username, s.password, s.host)
+	}
+
+	return client.Auth(auth)
+}
+
+func (s *SMTPSender) reset() {
+	if s.client != nil {
+		_ = s.client.Close()
+		s.client = nil
+	}
+}
+
+// buildMessage creates the MIME message with the headers and quoted-printable encoded body, the message is
+// multipart/alternative with the plain text and the HTML parts if the HTML body is set
+func buildMessage(to, cc []string, from string, payload *email.EmailPayload) ([]byte, error) {
+	var body bytes.Buffer
+	contentType := "text/plain; charset=UTF-8"
+	if payload.HTML == "" {
+		if err := writeQuotedPrintable(&body, payload.Content); err != nil {
+			return nil, err
+		}
+	} else {
+		writer := multipart.NewWriter(&body)
+		for _, part := range [][2]string{{"text/plain; charset=UTF-8", payload.Content}, {"text/html; charset=UTF-8", payload.HTML}} {
+			partWriter, err := writer.CreatePart(textproto.MIMEHeader{
+				"Content-Type":              {part[0]},
+				"Content-Transfer-Encoding": {"quoted-printable"},
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			if err := writeQuotedPrintable(partWriter, part[1]); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+
+		contentType = mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": writer.Boundary()})
+	}
+
+	messageID, err := newMessageID(from)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := [][2]string{
+		{"From", from},
+		{"To", strings.Join(to, ", ")},
+		{"Cc", strings.Join(cc, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", payload.Subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", messageID},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", contentType},
+	}
+
+	if payload.HTML == "" {
+		headers = append(headers, [2]string{"Content-Transfer-Encoding", "quoted-printable"})
+	}
+
+	if payload.Urgent {
+		headers = append(headers, [2]string{"X-Priority", "1 (Highest)"}, [2]string{"Importance", "High"})
+	}
+
+	var msg bytes.Buffer
+	for _, header := range headers {
+		if header[1] == "" {
+			continue
+		}
+
+		msg.WriteString(header[0] + ": " + header[1] + "\r\n")
+	}
+
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+
+	return msg.Bytes(), nil
+}
+
+// envelopeAddresses returns the bare addresses of the given addresses for the MAIL FROM and RCPT TO commands, e.g.
+// from@example.com for "Releases <from@example.com>"
+func envelopeAddresses(addresses []string) ([]string, error) {
+	var result []string
+	for _, address := range addresses {
+		parsed, err := mail.ParseAddress(address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid email address %q: %w", address, err)
+		}
+
+		result = append(result, parsed.Address)
+	}
+
+	return result, nil
+}
+
+func writeQuotedPrintable(w io.Writer, text string) error {
+	writer := quotedprintable.NewWriter(w)
+	if _, err := writer.Write([]byte(text)); err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
+// newMessageID creates a unique Message-ID header value under the domain of the sender
+func newMessageID(from string) (string, error) {
+	domain := "localhost"
+	if addr, err := mail.ParseAddress(from); err == nil {
+		if at := strings.LastIndex(addr.Address, "@"); at >= 0 {
+			domain = addr.Address[at+1:]
+		}
+	}
+
+	random := make([]byte, 12)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("<%d.%x@%s>", time.Now().UnixNano(), random, domain), nil
+}
+
+// loginAuth implements the LOGIN authentication mechanism which is not supported by net/smtp
+type loginAuth struct {
+	username string
+	password string
+	host     string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && !isLocalhost(server.Name) {
+		return "", nil, errors.New("unencrypted connection")
+	}
+
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+
+	switch strings.ToLower(strings.TrimSpace(string(fromServer))) {
+	case "username:":
+		return []byte(a.username), nil
+	case "password:":
+		return []byte(a.password), nil
+	default:
+		return nil, fmt.Errorf("unexpected server challenge %q", fromServer)
+	}
+}
+
+func isLocalhost(name string) bool {
+	return name == "localhost" || name == "127.0.0.1" || name == "::1"
+}
@@ -0,0 +1,368 @@
+//go:build unit
+
+package smtp
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/email"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeMessage is an email received by the fakeServer
+type fakeMessage struct {
+	from       string
+	recipients []string
+	data       string
+}
+
+// fakeServer is a minimal in-process SMTP server which supports STARTTLS, implicit TLS and PLAIN/LOGIN auth
+type fakeServer struct {
+	listener    net.Listener
+	tlsConfig   *tls.Config
+	startTLS    bool
+	closeOnSend bool
+
+	mu          sync.Mutex
+	connections int
+	auths       []string
+	messages    []fakeMessage
+}
+
+func newFakeServer(t *testing.T, implicitTLS, startTLS, closeOnSend bool) (*fakeServer, *tls.Config) {
+	serverConfig, clientConfig := newTLSConfigs(t)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+
+	if implicitTLS {
+		listener = tls.NewListener(listener, serverConfig)
+	}
+
+	server := &fakeServer{listener: listener, tlsConfig: serverConfig, startTLS: startTLS, closeOnSend: closeOnSend}
+	go server.serve()
+	t.Cleanup(func() { _ = listener.Close() })
+
+	return server, clientConfig
+}
+
+func (f *fakeServer) port() int {
+	return f.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (f *fakeServer) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		f.mu.Lock()
+		f.connections++
+		f.mu.Unlock()
+
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeServer) handle(conn net.Conn) {
+	defer conn.Close()
+
+	_, isTLS := conn.(*tls.Conn)
+	tp := textproto.NewConn(conn)
+	_ = tp.PrintfLine("220 localhost ESMTP")
+
+	var current fakeMessage
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			_ = tp.PrintfLine("250-localhost")
+			if f.startTLS && !isTLS {
+				_ = tp.PrintfLine("250-STARTTLS")
+			}
+
+			_ = tp.PrintfLine("250 AUTH PLAIN LOGIN")
+		case "STARTTLS":
+			_ = tp.PrintfLine("220 ready to start TLS")
+			tlsConn := tls.Server(conn, f.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+
+			conn, isTLS = tlsConn, true
+			tp = textproto.NewConn(conn)
+		case "AUTH":
+			mechanism, initial, _ := strings.Cut(arg, " ")
+			if strings.ToUpper(mechanism) == "PLAIN" {
+				decoded, _ := base64.StdEncoding.DecodeString(initial)
+				parts := strings.Split(string(decoded), "\x00")
+				f.record(func() { f.auths = append(f.auths, "PLAIN "+parts[1]+" "+parts[2]) })
+			} else {
+				_ = tp.PrintfLine("334 %s", base64.StdEncoding.EncodeToString([]byte("Username:")))
+				username, _ := tp.ReadLine()
+				_ = tp.PrintfLine("334 %s", base64.StdEncoding.EncodeToString([]byte("Password:")))
+				password, _ := tp.ReadLine()
+				decodedUsername, _ := base64.StdEncoding.DecodeString(username)
+				decodedPassword, _ := base64.StdEncoding.DecodeString(password)
+				f.record(func() { f.auths = append(f.auths, "LOGIN "+string(decodedUsername)+" "+string(decodedPassword)) })
+			}
+
+			_ = tp.PrintfLine("235 authenticated")
+		case "MAIL":
+			current = fakeMessage{from: strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")}
+			_ = tp.PrintfLine("250 ok")
+		case "RCPT":
+			current.recipients = append(current.recipients, strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>"))
+			_ = tp.PrintfLine("250 ok")
+		case "DATA":
+			_ = tp.PrintfLine("354 go ahead")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+
+			current.data = string(data)
+			f.record(func() { f.messages = append(f.messages, current) })
+			_ = tp.PrintfLine("250 queued")
+			if f.closeOnSend {
+				return
+			}
+		case "NOOP", "RSET":
+			_ = tp.PrintfLine("250 ok")
+		case "QUIT":
+			_ = tp.PrintfLine("221 bye")
+			return
+		default:
+			_ = tp.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+func (f *fakeServer) record(fn func()) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	fn()
+}
+
+// newTLSConfigs creates a self-signed certificate for 127.0.0.1 and returns the server and client tls configs
+func newTLSConfigs(t *testing.T) (*tls.Config, *tls.Config) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	assert.Nil(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+
+	serverConfig := &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+	clientConfig := &tls.Config{ServerName: "127.0.0.1", RootCAs: pool, MinVersion: tls.VersionTLS12}
+
+	return serverConfig, clientConfig
+}
+
+func TestNewSMTPSender(t *testing.T) {
+	cases := []struct {
+		caseName   string
+		host       string
+		port       int
+		security   string
+		auth       string
+		shouldPass bool
+	}{
+		{"Defaults", "smtp.example.com", 587, "", "", true},
+		{"Implicit TLS with LOGIN auth", "smtp.example.com", 465, SecurityTLS, AuthLogin, true},
+		{"Missing host", "", 587, "", "", false},
+		{"Unknown security", "smtp.example.com", 587, "ssl", "", false},
+		{"Unknown auth", "smtp.example.com", 587, "", "cram-md5", false},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		sender, err := NewSMTPSender(tc.host, tc.port, "user", "pass", tc.security, tc.auth)
+		if tc.shouldPass {
+			assert.Nil(t, err)
+			assert.NotNil(t, sender)
+		} else {
+			assert.NotNil(t, err)
+		}
+	}
+}
+
+func TestSMTPSender_Send(t *testing.T) {
+	cases := []struct {
+		caseName            string
+		implicitTLS         bool
+		startTLS            bool
+		closeOnSend         bool
+		security            string
+		auth                string
+		username            string
+		expectedConnections int
+		expectedAuths       []string
+	}{
+		{"STARTTLS with PLAIN auth reuses the connection", false, true, false, SecurityStartTLS, AuthPlain, "user", 1, []string{"PLAIN user pass"}},
+		{"Implicit TLS with LOGIN auth", true, false, false, SecurityTLS, AuthLogin, "user", 1, []string{"LOGIN user pass"}},
+		{"Plain connection is reopened when closed by server", false, false, true, SecurityNone, AuthPlain, "", 2, nil},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		server, clientConfig := newFakeServer(t, tc.implicitTLS, tc.startTLS, tc.closeOnSend)
+		sender, err := NewSMTPSender("127.0.0.1", server.port(), tc.username, "pass", tc.security, tc.auth)
+		assert.Nil(t, err)
+		sender.tlsConfig = clientConfig
+
+		payload := &email.EmailPayload{Subject: "New release alert for project x-project!", Content: "x-project 1.0.0 is out!", Urgent: true}
+		for i := 0; i < 2; i++ {
+			err := sender.Send([]string{"to@example.com"}, []string{"cc@example.com"}, []string{"bcc@example.com"}, "from@example.com", payload)
+			assert.Nil(t, err)
+		}
+
+		// the server already closed the connection if closeOnSend is set
+		if err := sender.Close(); !tc.closeOnSend {
+			assert.Nil(t, err)
+		}
+
+		server.mu.Lock()
+		assert.Equal(t, tc.expectedConnections, server.connections)
+		assert.Equal(t, tc.expectedAuths, server.auths)
+		assert.Len(t, server.messages, 2)
+		message := server.messages[0]
+		server.mu.Unlock()
+
+		assert.Equal(t, "from@example.com", message.from)
+		assert.Equal(t, []string{"to@example.com", "cc@example.com", "bcc@example.com"}, message.recipients)
+		assert.Contains(t, message.data, "To: to@example.com\n")
+		assert.Contains(t, message.data, "Cc: cc@example.com\n")
+		assert.NotContains(t, message.data, "bcc@example.com")
+		assert.Contains(t, message.data, "Subject: New release alert for project x-project!\n")
+		assert.Contains(t, message.data, "Content-Type: text/plain; charset=UTF-8\n")
+		assert.Contains(t, message.data, "X-Priority: 1 (Highest)\n")
+		assert.True(t, strings.HasSuffix(message.data, "\nx-project 1.0.0 is out!\n"))
+	}
+}
+
+func TestSMTPSender_SendStartTLSNotSupported(t *testing.T) {
+	server, clientConfig := newFakeServer(t, false, false, false)
+	sender, err := NewSMTPSender("127.0.0.1", server.port(), "", "", SecurityStartTLS, "")
+	assert.Nil(t, err)
+	sender.tlsConfig = clientConfig
+
+	err = sender.Send([]string{"to@example.com"}, nil, nil, "from@example.com", &email.EmailPayload{Subject: "subject", Content: "content"})
+	assert.NotNil(t, err)
+}
+
+func TestSMTPSender_SendDisplayNames(t *testing.T) {
+	server, clientConfig := newFakeServer(t, false, false, false)
+	sender, err := NewSMTPSender("127.0.0.1", server.port(), "", "", SecurityNone, "")
+	assert.Nil(t, err)
+	sender.tlsConfig = clientConfig
+
+	err = sender.Send([]string{"Team <to@example.com>"}, nil, []string{"bcc@example.com"}, "Releases <from@example.com>",
+		&email.EmailPayload{Subject: "subject", Content: "content"})
+	assert.Nil(t, err)
+	assert.Nil(t, sender.Close())
+
+	server.mu.Lock()
+	message := server.messages[0]
+	server.mu.Unlock()
+
+	assert.Equal(t, "from@example.com", message.from)
+	assert.Equal(t, []string{"to@example.com", "bcc@example.com"}, message.recipients)
+	assert.Contains(t, message.data, "From: Releases <from@example.com>\n")
+	assert.Contains(t, message.data, "To: Team <to@example.com>\n")
+
+	err = sender.Send([]string{"to@example.com"}, nil, nil, "not an address", &email.EmailPayload{Subject: "subject", Content: "content"})
+	assert.NotNil(t, err)
+}
+
+func TestBuildMessage(t *testing.T) {
+	msg, err := buildMessage([]string{"to@example.com"}, nil, "Releases <from@example.com>",
+		&email.EmailPayload{Subject: "Yeni sürüm", Content: "içerik"})
+	assert.Nil(t, err)
+
+	reader := textproto.NewReader(bufio.NewReader(strings.NewReader(string(msg))))
+	header, err := reader.ReadMIMEHeader()
+	assert.Nil(t, err)
+
+	assert.Equal(t, "=?utf-8?q?Yeni_s=C3=BCr=C3=BCm?=", header.Get("Subject"))
+	assert.Equal(t, "", header.Get("Cc"))
+	assert.Equal(t, "", header.Get("X-Priority"))
+	assert.True(t, strings.HasSuffix(header.Get("Message-Id"), "@example.com>"))
+}
+
+func TestBuildMessage_Multipart(t *testing.T) {
+	msg, err := buildMessage([]string{"to@example.com"}, nil, "from@example.com",
+		&email.EmailPayload{Subject: "subject", Content: "sürüm çıktı", HTML: "<p>sürüm <b>çıktı</b></p>", Urgent: true})
+	assert.Nil(t, err)
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(msg))
+	assert.Nil(t, err)
+	assert.Equal(t, "1 (Highest)", parsed.Header.Get("X-Priority"))
+	assert.Equal(t, "", parsed.Header.Get("Content-Transfer-Encoding"))
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	assert.Nil(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	// the parts are ordered from the plainest to the richest, clients display the last one they support
+	expected := [][2]string{{"text/plain; charset=UTF-8", "sürüm çıktı"}, {"text/html; charset=UTF-8", "<p>sürüm <b>çıktı</b></p>"}}
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	for _, part := range expected {
+		p, err := reader.NextPart()
+		assert.Nil(t, err)
+		assert.Equal(t, part[0], p.Header.Get("Content-Type"))
+
+		// multipart.Reader decodes the quoted-printable parts transparently
+		content, err := io.ReadAll(p)
+		assert.Nil(t, err)
+		assert.Equal(t, part[1], string(content))
+	}
+
+	_, err = reader.NextPart()
+	assert.Equal(t, io.EOF, err)
+}
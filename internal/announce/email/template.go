@@ -0,0 +1,126 @@
+package email
+
+const (
+	// defaultTextTemplate renders the plain text alternative, the notes are truncated to 20000 characters
+	defaultTextTemplate = `{{ .Summary }}{{ with text .BreakingChanges }}
+
+!!! BREAKING CHANGES !!!
+
+{{ truncate 20000 . }}{{ end }}{{ with text .Notes }}
+
+Release notes:
+
+{{ truncate 20000 . }}{{ end }}`
+
+	// defaultHTMLTemplate renders a single column layout with the inline styles, which is displayed the same by the
+	// desktop and the mobile clients, the digests list the releases grouped by project
+	defaultHTMLTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{ if .Digest }}Release digest{{ else }}{{ .ProjectName }} {{ .Version }}{{ end }}</title>
+</head>
+<body style="margin:0;padding:0;background-color:#f6f8fa;">
+<table role="presentation" width="100%" cellpadding="0" cellspacing="0" border="0" style="background-color:#f6f8fa;">
+<tr>
+<td align="center" style="padding:24px 12px;">
+<table role="presentation" width="100%" cellpadding="0" cellspacing="0" border="0" style="max-width:600px;background-color:#ffffff;border:1px solid #d0d7de;border-radius:6px;font-family:-apple-system,BlinkMacSystemFont,'Segoe UI',Helvetica,Arial,sans-serif;font-size:15px;line-height:1.5;color:#1f2328;">
+{{- if .Digest }}
+<tr>
+<td style="padding:24px 24px 8px 24px;">
+<h1 style="margin:0;font-size:24px;line-height:1.25;">Release digest</h1>
+</td>
+</tr>
+{{- range .Digest }}
+<tr>
+<td style="padding:8px 24px;">
+<h2 style="margin:8px 0;font-size:17px;word-break:break-word;">{{ .ProjectName }}</h2>
+<ul style="margin:0;padding-left:20px;">
+{{- range .Releases }}
+<li style="margin-bottom:4px;word-break:break-word;">{{ if eq .Event "removed" }}{{ .Version }} is removed{{ else }}<a href="{{ .URL }}" style="color:#0969da;">{{ .Version }}</a>{{ if eq .Event "updated" }} is updated{{ end }}{{ end }}
+{{- if eq .Level "security" }} <span style="color:#cf222e;font-weight:600;">security</span>
+{{- else if eq .Level "breaking" }} <span style="color:#bc4c00;font-weight:600;">breaking changes</span>
+{{- end }}{{ with .PublishedAt }} <span style="font-size:13px;color:#656d76;">{{ date "January 2, 2006" . }}</span>{{ end }}</li>
+{{- end }}
+</ul>
+</td>
+</tr>
+{{- end }}
+<tr><td style="padding:0 0 16px 0;"></td></tr>
+{{- else }}
+{{- if eq .Level "security" }}
+<tr><td style="padding:10px 24px;background-color:#cf222e;color:#ffffff;font-weight:600;border-radius:6px 6px 0 0;">Security release</td></tr>
+{{- else if eq .Level "breaking" }}
+<tr><td style="padding:10px 24px;background-color:#bc4c00;color:#ffffff;font-weight:600;border-radius:6px 6px 0 0;">Breaking changes</td></tr>
+{{- else if eq .Level "high" }}
+<tr><td style="padding:10px 24px;background-color:#9a6700;color:#ffffff;font-weight:600;border-radius:6px 6px 0 0;">Urgent</td></tr>
+{{- end }}
+<tr>
+<td style="padding:24px 24px 8px 24px;">
+<div style="font-size:13px;color:#656d76;">{{ .ProjectName }}</div>
+<h1 style="margin:4px 0 0 0;font-size:24px;line-height:1.25;word-break:break-word;">{{ .Version }}{{ if eq .Event "updated" }} is updated{{ else if eq .Event "removed" }} is removed{{ end }}</h1>
+{{- with .PublishedAt }}
+<div style="margin-top:4px;font-size:13px;color:#656d76;">Published on {{ date "January 2, 2006 15:04 MST" . }}</div>
+{{- end }}
+</td>
+</tr>
+<tr>
+<td style="padding:8px 24px;">
+<p style="margin:0;word-break:break-word;">{{ .Summary }}</p>
+{{- with .Changes }}
+<p style="margin:8px 0 0 0;font-size:13px;color:#656d76;">Changed fields: {{ join ", " . }}</p>
+{{- end }}
+</td>
+</tr>
+{{- with .Security }}
+<tr>
+<td style="padding:8px 24px;">
+<h2 style="margin:8px 0;font-size:17px;">Security</h2>
+<ul style="margin:0;padding-left:20px;">
+{{- range .CVEs }}
+<li><a href="https://nvd.nist.gov/vuln/detail/{{ . }}" style="color:#0969da;">{{ . }}</a></li>
+{{- end }}
+{{- range .GHSAs }}
+<li><a href="https://github.com/advisories/{{ . }}" style="color:#0969da;">{{ . }}</a></li>
+{{- end }}
+{{- if and (not .CVEs) (not .GHSAs) }}
+<li>{{ join ", " .Keywords }}</li>
+{{- end }}
+</ul>
+</td>
+</tr>
+{{- end }}
+{{- with htmlNotes .BreakingChanges }}
+<tr>
+<td style="padding:8px 24px;">
+<h2 style="margin:8px 0;font-size:17px;color:#bc4c00;">Breaking changes</h2>
+<div style="white-space:pre-wrap;word-break:break-word;padding:12px;background-color:#fff8c5;border-radius:6px;">{{ . }}</div>
+</td>
+</tr>
+{{- end }}
+{{- with htmlNotes .Notes }}
+<tr>
+<td style="padding:8px 24px;">
+<h2 style="margin:8px 0;font-size:17px;">Release notes</h2>
+<div style="white-space:pre-wrap;word-break:break-word;">{{ . }}</div>
+</td>
+</tr>
+{{- end }}
+{{- if ne .Event "removed" }}
+<tr>
+<td style="padding:16px 24px 24px 24px;">
+<a href="{{ .URL }}" style="display:inline-block;padding:10px 18px;background-color:#1f883d;color:#ffffff;text-decoration:none;font-weight:600;border-radius:6px;">View release</a>
+</td>
+</tr>
+{{- end }}
+{{- end }}
+</table>
+<div style="padding:12px;font-family:Helvetica,Arial,sans-serif;font-size:12px;color:#656d76;">Sent by rss-feed-filterer</div>
+</td>
+</tr>
+</table>
+</body>
+</html>
+`
+)
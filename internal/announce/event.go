@@ -0,0 +1,46 @@
+package announce
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// EventSchemaVersion is the version of the release event schema, it is increased on the breaking changes of the schema
+const EventSchemaVersion = "1"
+
+// Event is the versioned release event published to the message buses
+type Event struct {
+	SchemaVersion string `json:"schemaVersion"`
+	*TemplateData
+}
+
+// NewEvent creates the release event of the payload
+func NewEvent(payload *AnnouncerPayload) *Event {
+	return &Event{
+		SchemaVersion: EventSchemaVersion,
+		TemplateData:  NewTemplateData(payload),
+	}
+}
+
+// EventAttributes returns the event, project, level and priority of the payload, they are sent along with the release
+// events by the publishers which support filtering on the message attributes
+func EventAttributes(payload *AnnouncerPayload) map[string]string {
+	data := NewTemplateData(payload)
+	return map[string]string{
+		"event":    string(data.Event),
+		"project":  data.ProjectName,
+		"level":    payload.Level(),
+		"priority": string(data.Priority),
+	}
+}
+
+// DeduplicationId returns the deduplication id of the release event, so the same event of a release is delivered once
+// by the FIFO topics and queues in their deduplication interval. It is derived from the project, version, event and
+// changes of the payload, since the encoded events can differ between the retries, e.g. by the time of the
+// CloudEvents.
+func DeduplicationId(payload *AnnouncerPayload) string {
+	key := strings.Join(append([]string{payload.DedupKey(), string(payload.GetEvent())}, payload.Changes...), "\n")
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,41 @@
+//go:build unit
+
+package announce
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventAttributes(t *testing.T) {
+	assert.Equal(t, map[string]string{"event": "new", "project": "x-project", "level": LevelNormal, "priority": "normal"},
+		EventAttributes(&AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0"}))
+	assert.Equal(t, map[string]string{"event": "removed", "project": "x-project", "level": LevelSecurity, "priority": "high"},
+		EventAttributes(&AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", Event: types.EventRemoved,
+			Security: &types.Security{CVEs: []string{"CVE-2023-1234"}}, Priority: PriorityHigh}))
+}
+
+func TestDeduplicationId(t *testing.T) {
+	updated := &AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", Event: types.EventUpdated, Changes: []string{"url"}}
+	id := DeduplicationId(updated)
+	assert.Len(t, id, 64)
+
+	// the retries of an event have the same id even though their CloudEvents differ by time
+	first, err := EncodeEvent(EventFormatCloudEvents, updated)
+	assert.Nil(t, err)
+	time.Sleep(time.Millisecond)
+	second, err := EncodeEvent(EventFormatCloudEvents, updated)
+	assert.Nil(t, err)
+	assert.NotEqual(t, first.Body, second.Body)
+	assert.Equal(t, id, DeduplicationId(updated))
+
+	assert.NotEqual(t, id, DeduplicationId(&AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.1",
+		Event: types.EventUpdated, Changes: []string{"url"}}))
+	assert.NotEqual(t, id, DeduplicationId(&AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0",
+		Event: types.EventRemoved}))
+	assert.NotEqual(t, id, DeduplicationId(&AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0",
+		Event: types.EventUpdated, Changes: []string{"notes"}}))
+}
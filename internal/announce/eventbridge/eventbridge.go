@@ -0,0 +1,171 @@
+package eventbridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	internaltypes "github.com/bilalcaliskan/rss-feed-filterer/internal/types"
+)
+
+const (
+	defaultEventBusName = "default"
+	defaultSource       = "rss-feed-filterer"
+
+	// maxBatchEntries and maxBatchSize are the limits of the entries of a PutEvents request
+	maxBatchEntries = 10
+	maxBatchSize    = 256 * 1024
+)
+
+// detailTypes are the detail types of the release events by the event type, the rules can match on them
+var detailTypes = map[internaltypes.EventType]string{
+	internaltypes.EventNew:     "Release Published",
+	internaltypes.EventUpdated: "Release Updated",
+	internaltypes.EventRemoved: "Release Removed",
+}
+
+// EventBridgeClient is the interface that contains PutEvents function.
+// We can mock this interface for testing purposes.
+type EventBridgeClient interface {
+	PutEvents(ctx context.Context, params *eventbridge.PutEventsInput, optFns ...func(*eventbridge.Options)) (*eventbridge.PutEventsOutput, error)
+}
+
+// EventBridgeAnnouncer is the announcer that puts the release events to an EventBridge event bus
+type EventBridgeAnnouncer struct {
+	EventBusName string
+	Source       string
+	// Format is one of announce.EventFormatJSON and announce.EventFormatCloudEvents
+	Format announce.EventFormat
+	client EventBridgeClient
+}
+
+// NewEventBridgeAnnouncer creates a new EventBridgeAnnouncer. It requires a EventBridgeClient interface. eventBusName
+// is the name or the arn of the bus, defaults to the default bus of the account. source defaults to rss-feed-filterer.
+// format is one of json and cloudevents, defaults to json.
+func NewEventBridgeAnnouncer(client EventBridgeClient, eventBusName, source string,
+	format announce.EventFormat) (*EventBridgeAnnouncer, error) {
+	if eventBusName == "" {
+		eventBusName = defaultEventBusName
+	}
+
+	if source == "" {
+		source = defaultSource
+	}
+
+	// the sources starting with aws. are reserved for the aws services
+	if strings.HasPrefix(source, "aws.") {
+		return nil, fmt.Errorf("eventbridge source %q is reserved for aws services", source)
+	}
+
+	switch format {
+	case "":
+		format = announce.EventFormatJSON
+	case announce.EventFormatJSON, announce.EventFormatCloudEvents:
+	default:
+		return nil, fmt.Errorf("unsupported eventbridge format %q", format)
+	}
+
+	return &EventBridgeAnnouncer{
+		EventBusName: eventBusName,
+		Source:       source,
+		Format:       format,
+		client:       client,
+	}, nil
+}
+
+// Notify puts the release event to the bus, routed payloads override it with the bus names in the recipients. The
+// detail of the event is the release event and the detail type is one of Release Published, Release Updated and
+// Release Removed. The time of the event is the publish time of the new releases and the update time for the others.
+// The entries are put in batches within the limits of PutEvents.
+func (e *EventBridgeAnnouncer) Notify(payload *announce.AnnouncerPayload) error {
+	// the detail is limited to what is left of the size of a batch with a single entry
+	detailType := detailTypes[payload.GetEvent()]
+	maxDetailSize := maxBatchSize - entrySize(types.PutEventsRequestEntry{Source: aws.String(e.Source),
+		DetailType: aws.String(detailType), Time: &time.Time{}})
+	event, err := announce.EncodeEventWithin(e.Format, payload, maxDetailSize)
+	if err != nil {
+		return err
+	}
+
+	buses := payload.Recipients
+	if len(buses) == 0 {
+		buses = []string{e.EventBusName}
+	}
+
+	// the events without a time get the time of the PutEvents call
+	eventTime := payload.PublishedAt
+	if payload.GetEvent() != internaltypes.EventNew {
+		eventTime = payload.UpdatedAt
+	}
+
+	var entries []types.PutEventsRequestEntry
+	for _, bus := range buses {
+		entries = append(entries, types.PutEventsRequestEntry{
+			EventBusName: aws.String(bus),
+			Source:       aws.String(e.Source),
+			DetailType:   aws.String(detailType),
+			Detail:       aws.String(string(event.Body)),
+			Time:         eventTime,
+		})
+	}
+
+	var errs []error
+	for start := 0; start < len(entries); {
+		end := start + 1
+		size := entrySize(entries[start])
+		for end < len(entries) && end-start < maxBatchEntries && size+entrySize(entries[end]) <= maxBatchSize {
+			size += entrySize(entries[end])
+			end++
+		}
+
+		errs = append(errs, e.put(entries[start:end], buses[start:end])...)
+		start = end
+	}
+
+	return errors.Join(errs...)
+}
+
+// put puts the batch of the entries to their buses and returns the errors of the rejected entries
+func (e *EventBridgeAnnouncer) put(entries []types.PutEventsRequestEntry, buses []string) []error {
+	output, err := e.client.PutEvents(context.Background(), &eventbridge.PutEventsInput{Entries: entries})
+	if err != nil {
+		return []error{err}
+	}
+
+	// PutEvents succeeds even if some entries are rejected, the results are in the same order with the entries
+	var errs []error
+	for i, entry := range output.Entries {
+		if entry.ErrorCode != nil && i < len(buses) {
+			errs = append(errs, fmt.Errorf("event bus %s: %s: %s", buses[i], aws.ToString(entry.ErrorCode),
+				aws.ToString(entry.ErrorMessage)))
+		}
+	}
+
+	return errs
+}
+
+// entrySize returns the size of the entry as it is calculated for the limit of PutEvents
+func entrySize(entry types.PutEventsRequestEntry) int {
+	size := len(aws.ToString(entry.Source)) + len(aws.ToString(entry.DetailType)) + len(aws.ToString(entry.Detail))
+	if entry.Time != nil {
+		size += 14
+	}
+
+	for _, resource := range entry.Resources {
+		size += len(resource)
+	}
+
+	return size
+}
+
+// IsEnabled checks if the EventBridgeAnnouncer is enabled.
+func (e *EventBridgeAnnouncer) IsEnabled() bool {
+	return e.EventBusName != ""
+}
@@ -0,0 +1,216 @@
+//go:build unit
+
+package eventbridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	ebtypes "github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockEventBridgeClient struct {
+	mock.Mock
+}
+
+func (m *MockEventBridgeClient) PutEvents(ctx context.Context, params *eventbridge.PutEventsInput, optFns ...func(*eventbridge.Options)) (*eventbridge.PutEventsOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*eventbridge.PutEventsOutput), args.Error(1)
+}
+
+func TestNewEventBridgeAnnouncer(t *testing.T) {
+	cases := []struct {
+		caseName       string
+		eventBusName   string
+		source         string
+		format         announce.EventFormat
+		expectedBus    string
+		expectedSource string
+		shouldPass     bool
+	}{
+		{"Defaults", "", "", "", defaultEventBusName, defaultSource, true},
+		{"Custom bus and source", "releases", "com.example.releases", announce.EventFormatCloudEvents, "releases",
+			"com.example.releases", true},
+		{"Reserved source", "", "aws.events", "", "", "", false},
+		{"Binary mode", "", "", announce.EventFormatCloudEventsBinary, "", "", false},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		announcer, err := NewEventBridgeAnnouncer(nil, tc.eventBusName, tc.source, tc.format)
+		if !tc.shouldPass {
+			assert.NotNil(t, err)
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.Equal(t, tc.expectedBus, announcer.EventBusName)
+		assert.Equal(t, tc.expectedSource, announcer.Source)
+		assert.True(t, announcer.IsEnabled())
+	}
+}
+
+func TestEventBridgeAnnouncer_Notify(t *testing.T) {
+	publishedAt := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	updatedAt := time.Date(2023, 10, 2, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		caseName           string
+		payload            *announce.AnnouncerPayload
+		output             *eventbridge.PutEventsOutput
+		injectedErr        error
+		expectedBuses      []string
+		expectedDetailType string
+		expectedErr        string
+	}{
+		{
+			"New release",
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", URL: "https://example.com", PublishedAt: &publishedAt},
+			&eventbridge.PutEventsOutput{Entries: []ebtypes.PutEventsResultEntry{{EventId: aws.String("1")}}},
+			nil,
+			[]string{"releases"},
+			"Release Published",
+			"",
+		},
+		{
+			"Removed release is routed to the other buses",
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", URL: "https://example.com",
+				PublishedAt: &publishedAt, UpdatedAt: &updatedAt, Event: types.EventRemoved, Recipients: []string{"security", "platform"}},
+			&eventbridge.PutEventsOutput{Entries: []ebtypes.PutEventsResultEntry{{EventId: aws.String("1")}, {EventId: aws.String("2")}}},
+			nil,
+			[]string{"security", "platform"},
+			"Release Removed",
+			"",
+		},
+		{
+			"Rejected entry",
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", URL: "https://example.com",
+				Event: types.EventUpdated, Recipients: []string{"security", "missing"}},
+			&eventbridge.PutEventsOutput{FailedEntryCount: 1, Entries: []ebtypes.PutEventsResultEntry{{EventId: aws.String("1")},
+				{ErrorCode: aws.String("ResourceNotFoundException"), ErrorMessage: aws.String("Event bus missing does not exist.")}}},
+			nil,
+			[]string{"security", "missing"},
+			"Release Updated",
+			"event bus missing: ResourceNotFoundException: Event bus missing does not exist.",
+		},
+		{
+			"PutEvents error",
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", URL: "https://example.com"},
+			&eventbridge.PutEventsOutput{},
+			errors.New("injected error"),
+			[]string{"releases"},
+			"Release Published",
+			"injected error",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		client := new(MockEventBridgeClient)
+		client.On("PutEvents", mock.Anything, mock.Anything, mock.Anything).Return(tc.output, tc.injectedErr)
+
+		announcer, err := NewEventBridgeAnnouncer(client, "releases", "", "")
+		assert.Nil(t, err)
+
+		err = announcer.Notify(tc.payload)
+		if tc.expectedErr != "" {
+			assert.EqualError(t, err, tc.expectedErr)
+		} else {
+			assert.Nil(t, err)
+		}
+
+		input := client.Calls[0].Arguments.Get(1).(*eventbridge.PutEventsInput)
+		assert.Len(t, input.Entries, len(tc.expectedBuses))
+
+		expectedDetail, _ := announce.EncodeEvent(announce.EventFormatJSON, tc.payload)
+		// the time of the updated and removed events is the update time of the release
+		expectedTime := tc.payload.PublishedAt
+		if tc.payload.GetEvent() != types.EventNew {
+			expectedTime = tc.payload.UpdatedAt
+		}
+
+		for i, entry := range input.Entries {
+			assert.Equal(t, tc.expectedBuses[i], aws.ToString(entry.EventBusName))
+			assert.Equal(t, defaultSource, aws.ToString(entry.Source))
+			assert.Equal(t, tc.expectedDetailType, aws.ToString(entry.DetailType))
+			assert.Equal(t, string(expectedDetail.Body), aws.ToString(entry.Detail))
+			assert.Equal(t, expectedTime, entry.Time)
+		}
+	}
+}
+
+func TestEventBridgeAnnouncer_Notify_cloudEvents(t *testing.T) {
+	publishedAt := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	client := new(MockEventBridgeClient)
+	client.On("PutEvents", mock.Anything, mock.Anything, mock.Anything).Return(
+		&eventbridge.PutEventsOutput{Entries: []ebtypes.PutEventsResultEntry{{EventId: aws.String("1")}}}, nil)
+
+	announcer, err := NewEventBridgeAnnouncer(client, "releases", "", announce.EventFormatCloudEvents)
+	assert.Nil(t, err)
+
+	payload := &announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", URL: "https://example.com",
+		PublishedAt: &publishedAt}
+	assert.Nil(t, announcer.Notify(payload))
+
+	expectedDetail, err := announce.EncodeEvent(announce.EventFormatCloudEvents, payload)
+	assert.Nil(t, err)
+	input := client.Calls[0].Arguments.Get(1).(*eventbridge.PutEventsInput)
+	assert.Equal(t, string(expectedDetail.Body), aws.ToString(input.Entries[0].Detail))
+}
+
+func TestEventBridgeAnnouncer_Notify_batches(t *testing.T) {
+	cases := []struct {
+		caseName        string
+		buses           int
+		notes           string
+		expectedBatches []int
+	}{
+		{"Entries within the limits", 3, "", []int{3}},
+		{"Batches of ten entries", 25, "", []int{10, 10, 5}},
+		{"Batches within the size limit", 5, strings.Repeat("a", 100*1024), []int{2, 2, 1}},
+		{"Oversized notes are dropped", 3, strings.Repeat("a", 300*1024), []int{3}},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		client := new(MockEventBridgeClient)
+		client.On("PutEvents", mock.Anything, mock.Anything, mock.Anything).Return(&eventbridge.PutEventsOutput{}, nil)
+
+		announcer, err := NewEventBridgeAnnouncer(client, "releases", "", "")
+		assert.Nil(t, err)
+
+		var buses []string
+		for i := 0; i < tc.buses; i++ {
+			buses = append(buses, fmt.Sprintf("bus-%d", i))
+		}
+
+		assert.Nil(t, announcer.Notify(&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0",
+			Notes: tc.notes, Recipients: buses}))
+
+		var batches []int
+		var sent []string
+		for _, call := range client.Calls {
+			input := call.Arguments.Get(1).(*eventbridge.PutEventsInput)
+			batches = append(batches, len(input.Entries))
+			for _, entry := range input.Entries {
+				sent = append(sent, aws.ToString(entry.EventBusName))
+			}
+		}
+
+		assert.Equal(t, tc.expectedBatches, batches)
+		assert.Equal(t, buses, sent)
+	}
+}
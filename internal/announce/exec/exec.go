@@ -0,0 +1,124 @@
+package exec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	osexec "os/exec"
+	"strings"
+	"time"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+)
+
+const (
+	// DefaultTimeout is the time limit of the command if it is not configured
+	DefaultTimeout = 30 * time.Second
+	// maxStderr is the number of the bytes of the standard error included in the returned errors
+	maxStderr = 1024
+)
+
+// ExecAnnouncer is the announcer that runs a command for each release, the release fields are passed as the RELEASE_
+// prefixed environment variables and the release event is written to the standard input of the command
+type ExecAnnouncer struct {
+	// Command is the executable and its arguments, it is not run in a shell
+	Command []string
+	Format  announce.EventFormat
+	Timeout time.Duration
+}
+
+// NewExecAnnouncer creates a new ExecAnnouncer. format is one of json and cloudevents, defaults to json.
+func NewExecAnnouncer(command []string, format announce.EventFormat, timeout time.Duration) (*ExecAnnouncer, error) {
+	if len(command) == 0 || command[0] == "" {
+		return nil, errors.New("exec command is required")
+	}
+
+	switch format {
+	case "":
+		format = announce.EventFormatJSON
+	case announce.EventFormatJSON, announce.EventFormatCloudEvents:
+	default:
+		return nil, fmt.Errorf("unsupported exec format %q", format)
+	}
+
+	if timeout < 0 {
+		return nil, errors.New("exec timeout can not be negative")
+	}
+
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+
+	return &ExecAnnouncer{
+		Command: command,
+		Format:  format,
+		Timeout: timeout,
+	}, nil
+}
+
+// Notify runs the command and waits for it, the command is killed if it does not finish in Timeout.
+func (e *ExecAnnouncer) Notify(payload *announce.AnnouncerPayload) error {
+	event, err := announce.EncodeEvent(e.Format, payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.Timeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := osexec.CommandContext(ctx, e.Command[0], e.Command[1:]...)
+	cmd.Env = append(os.Environ(), Environment(payload)...)
+	cmd.Stdin = bytes.NewReader(event.Body)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = &stderr
+	// the children of the killed command may keep the standard error open, do not wait for them
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("command %s timed out after %s", e.Command[0], e.Timeout)
+		}
+
+		output := strings.TrimSpace(stderr.String())
+		if len(output) > maxStderr {
+			output = output[:maxStderr]
+		}
+
+		return fmt.Errorf("command %s failed: %w: %s", e.Command[0], err, output)
+	}
+
+	return nil
+}
+
+// IsEnabled checks if the ExecAnnouncer is enabled.
+func (e *ExecAnnouncer) IsEnabled() bool {
+	return len(e.Command) > 0
+}
+
+// Environment returns the release fields of the payload as the environment variables, the empty fields are included
+// as empty variables so the scripts do not pick them up from the parent environment
+func Environment(payload *announce.AnnouncerPayload) []string {
+	return []string{
+		"RELEASE_PROJECT=" + payload.ProjectName,
+		"RELEASE_VERSION=" + payload.Version,
+		"RELEASE_URL=" + payload.URL,
+		"RELEASE_PUBLISHED_AT=" + formatTime(payload.PublishedAt),
+		"RELEASE_UPDATED_AT=" + formatTime(payload.UpdatedAt),
+		"RELEASE_EVENT=" + string(payload.GetEvent()),
+		"RELEASE_CHANGES=" + strings.Join(payload.Changes, ","),
+		"RELEASE_LEVEL=" + payload.Level(),
+		"RELEASE_ADVISORIES=" + strings.Join(payload.Advisories(), ","),
+		"RELEASE_SUMMARY=" + payload.Summary(),
+	}
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,86 @@
+//go:build unit
+
+package exec
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewExecAnnouncer(t *testing.T) {
+	cases := []struct {
+		caseName        string
+		command         []string
+		format          announce.EventFormat
+		timeout         time.Duration
+		expectedTimeout time.Duration
+		shouldPass      bool
+	}{
+		{"Defaults", []string{"true"}, "", 0, DefaultTimeout, true},
+		{"Custom timeout", []string{"cat", "-"}, announce.EventFormatCloudEvents, time.Second, time.Second, true},
+		{"Binary CloudEvents", []string{"true"}, announce.EventFormatCloudEventsBinary, 0, 0, false},
+		{"Missing command", nil, "", 0, 0, false},
+		{"Negative timeout", []string{"true"}, "", -time.Second, 0, false},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		announcer, err := NewExecAnnouncer(tc.command, tc.format, tc.timeout)
+		if !tc.shouldPass {
+			assert.NotNil(t, err)
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.Equal(t, tc.expectedTimeout, announcer.Timeout)
+		assert.True(t, announcer.IsEnabled())
+	}
+}
+
+func TestExecAnnouncer_Notify(t *testing.T) {
+	dir := t.TempDir()
+	payload := &announce.AnnouncerPayload{ProjectName: "user1/project1", Version: "v1.0.0", URL: "https://example.com",
+		Event: types.EventUpdated, Changes: []string{"url", "notes"}, Security: &types.Security{CVEs: []string{"CVE-2023-1234"}}}
+
+	cases := []struct {
+		caseName   string
+		script     string
+		timeout    time.Duration
+		expected   string
+		shouldPass bool
+	}{
+		{"Environment", `echo "$RELEASE_PROJECT $RELEASE_VERSION $RELEASE_EVENT $RELEASE_CHANGES $RELEASE_LEVEL $RELEASE_ADVISORIES" > "$1"`,
+			time.Second * 5, "user1/project1 v1.0.0 updated url,notes security CVE-2023-1234\n", true},
+		{"Standard input", `cat > "$1"`, time.Second * 5, `"schemaVersion":"1"`, true},
+		{"Failure", `echo "something went wrong" >&2; exit 3`, time.Second * 5, "something went wrong", false},
+		{"Timeout", `exec sleep 5`, time.Millisecond * 100, "timed out", false},
+	}
+
+	for i, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		output := filepath.Join(dir, strings.Repeat("o", i+1))
+		announcer, err := NewExecAnnouncer([]string{"sh", "-c", tc.script, "sh", output}, announce.EventFormatJSON, tc.timeout)
+		assert.Nil(t, err)
+
+		err = announcer.Notify(payload)
+		if !tc.shouldPass {
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), tc.expected)
+			continue
+		}
+
+		assert.Nil(t, err)
+		content, err := os.ReadFile(output)
+		assert.Nil(t, err)
+		assert.Contains(t, string(content), tc.expected)
+	}
+}
@@ -0,0 +1,109 @@
+package file
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+)
+
+// FileAnnouncer is the announcer that appends the release events to a file as JSON lines
+type FileAnnouncer struct {
+	Path   string
+	Format announce.EventFormat
+	// MaxSize is the size in bytes the file is rotated at, the file is not rotated if it is 0
+	MaxSize int64
+	// MaxBackups is the number of the rotated files to keep as Path.1, Path.2 and so on
+	MaxBackups int
+	// mu serializes the writes of the concurrent repository checks
+	mu sync.Mutex
+}
+
+// NewFileAnnouncer creates a new FileAnnouncer. format is one of json and cloudevents, defaults to json.
+func NewFileAnnouncer(path string, format announce.EventFormat, maxSize int64, maxBackups int) (*FileAnnouncer, error) {
+	if path == "" {
+		return nil, errors.New("file path is required")
+	}
+
+	switch format {
+	case "":
+		format = announce.EventFormatJSON
+	case announce.EventFormatJSON, announce.EventFormatCloudEvents:
+	default:
+		return nil, fmt.Errorf("unsupported file format %q", format)
+	}
+
+	if maxSize < 0 || maxBackups < 0 {
+		return nil, errors.New("file max size and max backups can not be negative")
+	}
+
+	return &FileAnnouncer{
+		Path:       path,
+		Format:     format,
+		MaxSize:    maxSize,
+		MaxBackups: maxBackups,
+	}, nil
+}
+
+// Notify appends the release event as a single line, the file is rotated before the write if the line does not fit.
+func (f *FileAnnouncer) Notify(payload *announce.AnnouncerPayload) error {
+	event, err := announce.EncodeEvent(f.Format, payload)
+	if err != nil {
+		return err
+	}
+
+	line := append(event.Body, '\n')
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.MaxSize > 0 {
+		if info, err := os.Stat(f.Path); err == nil && info.Size() > 0 && info.Size()+int64(len(line)) > f.MaxSize {
+			if err := f.rotate(); err != nil {
+				return fmt.Errorf("failed to rotate %s: %w", f.Path, err)
+			}
+		}
+	}
+
+	file, err := os.OpenFile(f.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(line); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// IsEnabled checks if the FileAnnouncer is enabled.
+func (f *FileAnnouncer) IsEnabled() bool {
+	return f.Path != ""
+}
+
+// rotate shifts the backups by one, drops the oldest one and moves the current file to Path.1
+func (f *FileAnnouncer) rotate() error {
+	if f.MaxBackups == 0 {
+		return os.Remove(f.Path)
+	}
+
+	if err := os.Remove(backup(f.Path, f.MaxBackups)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	for i := f.MaxBackups - 1; i > 0; i-- {
+		if err := os.Rename(backup(f.Path, i), backup(f.Path, i+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	return os.Rename(f.Path, backup(f.Path, 1))
+}
+
+func backup(path string, index int) string {
+	return fmt.Sprintf("%s.%d", path, index)
+}
@@ -0,0 +1,112 @@
+//go:build unit
+
+package file
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFileAnnouncer(t *testing.T) {
+	cases := []struct {
+		caseName       string
+		path           string
+		format         announce.EventFormat
+		maxSize        int64
+		expectedFormat announce.EventFormat
+		shouldPass     bool
+	}{
+		{"Default format", "releases.jsonl", "", 0, announce.EventFormatJSON, true},
+		{"CloudEvents", "releases.jsonl", announce.EventFormatCloudEvents, 1024, announce.EventFormatCloudEvents, true},
+		{"Binary CloudEvents", "releases.jsonl", announce.EventFormatCloudEventsBinary, 0, "", false},
+		{"Missing path", "", "", 0, "", false},
+		{"Negative max size", "releases.jsonl", "", -1, "", false},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		announcer, err := NewFileAnnouncer(tc.path, tc.format, tc.maxSize, 1)
+		if !tc.shouldPass {
+			assert.NotNil(t, err)
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.Equal(t, tc.expectedFormat, announcer.Format)
+		assert.True(t, announcer.IsEnabled())
+	}
+}
+
+func TestFileAnnouncer_Notify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "releases.jsonl")
+
+	announcer, err := NewFileAnnouncer(path, announce.EventFormatJSON, 0, 0)
+	assert.Nil(t, err)
+
+	assert.Nil(t, announcer.Notify(&announce.AnnouncerPayload{ProjectName: "user1/project1", Version: "v1.0.0"}))
+	assert.Nil(t, announcer.Notify(&announce.AnnouncerPayload{ProjectName: "user1/project1", Version: "v1.0.1"}))
+
+	content, err := os.ReadFile(path)
+	assert.Nil(t, err)
+
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	assert.Len(t, lines, 2)
+	for i, version := range []string{"v1.0.0", "v1.0.1"} {
+		var event map[string]any
+		assert.Nil(t, json.Unmarshal([]byte(lines[i]), &event))
+		assert.Equal(t, version, event["version"])
+		assert.Equal(t, announce.EventSchemaVersion, event["schemaVersion"])
+	}
+}
+
+func TestFileAnnouncer_Rotate(t *testing.T) {
+	cases := []struct {
+		caseName        string
+		maxBackups      int
+		expectedFiles   []string
+		expectedMissing []string
+	}{
+		{"Without backups", 0, []string{"releases.jsonl"}, []string{"releases.jsonl.1"}},
+		{"With backups", 2, []string{"releases.jsonl", "releases.jsonl.1", "releases.jsonl.2"}, []string{"releases.jsonl.3"}},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		dir := t.TempDir()
+		path := filepath.Join(dir, "releases.jsonl")
+
+		// every line exceeds the max size, so each write rotates the previous one
+		announcer, err := NewFileAnnouncer(path, announce.EventFormatJSON, 10, tc.maxBackups)
+		assert.Nil(t, err)
+
+		for _, version := range []string{"v1.0.0", "v1.0.1", "v1.0.2", "v1.0.3"} {
+			assert.Nil(t, announcer.Notify(&announce.AnnouncerPayload{ProjectName: "user1/project1", Version: version}))
+		}
+
+		for _, name := range tc.expectedFiles {
+			content, err := os.ReadFile(filepath.Join(dir, name))
+			assert.Nil(t, err)
+			assert.Equal(t, 1, strings.Count(string(content), "\n"))
+		}
+
+		for _, name := range tc.expectedMissing {
+			_, err := os.Stat(filepath.Join(dir, name))
+			assert.True(t, os.IsNotExist(err))
+		}
+
+		content, _ := os.ReadFile(path)
+		assert.Contains(t, string(content), "v1.0.3")
+		if tc.maxBackups > 0 {
+			content, _ = os.ReadFile(path + ".1")
+			assert.Contains(t, string(content), "v1.0.2")
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package gotify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/notes"
+)
+
+const (
+	defaultPriority = 5
+	urgentPriority  = 8
+	maxPriority     = 10
+	// maxMessageLength keeps the push notifications readable on the phones
+	maxMessageLength = 1500
+)
+
+type message struct {
+	Title    string         `json:"title"`
+	Message  string         `json:"message"`
+	Priority int            `json:"priority"`
+	Extras   map[string]any `json:"extras,omitempty"`
+}
+
+// errorResponse is the error body of the Gotify API
+type errorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"errorDescription"`
+}
+
+// GotifyAnnouncer is the announcer that pushes the releases to a Gotify application
+type GotifyAnnouncer struct {
+	ServerUrl string
+	AppToken  string
+	Priority  int
+	client    *http.Client
+}
+
+// NewGotifyAnnouncer creates a new GotifyAnnouncer. priority is between 1 and 10, defaults to 5. Urgent releases are
+// sent with priority 8 unless the configured priority is already higher.
+func NewGotifyAnnouncer(serverUrl, appToken string, priority int) (*GotifyAnnouncer, error) {
+	if serverUrl == "" || appToken == "" {
+		return nil, errors.New("gotify server url and application token are required")
+	}
+
+	if priority == 0 {
+		priority = defaultPriority
+	}
+
+	if priority < 1 || priority > maxPriority {
+		return nil, fmt.Errorf("gotify priority must be between 1 and %d", maxPriority)
+	}
+
+	return &GotifyAnnouncer{
+		ServerUrl: strings.TrimSuffix(serverUrl, "/"),
+		AppToken:  appToken,
+		Priority:  priority,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Notify pushes the message to the application. The messages of an application are delivered to all clients of its
+// user, so the recipients of the routed payloads are ignored.
+func (g *GotifyAnnouncer) Notify(payload *announce.AnnouncerPayload) error {
+	body, err := json.Marshal(g.buildMessage(payload))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, g.ServerUrl+"/message", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Gotify-Key", g.AppToken)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("an error occurred while calling gotify server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var response errorResponse
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		_ = json.Unmarshal(respBody, &response)
+		return fmt.Errorf("gotify server returned status code %d: %s", resp.StatusCode, response.ErrorDescription)
+	}
+
+	return nil
+}
+
+// IsEnabled checks if the GotifyAnnouncer is enabled.
+func (g *GotifyAnnouncer) IsEnabled() bool {
+	return g.ServerUrl != "" && g.AppToken != ""
+}
+
+func (g *GotifyAnnouncer) buildMessage(payload *announce.AnnouncerPayload) *message {
+	msg := &message{
+		Title:    payload.Title(fmt.Sprintf("%s %s", payload.ProjectName, payload.Version)),
+		Message:  payload.Summary(),
+		Priority: g.Priority,
+		Extras: map[string]any{
+			"client::display": map[string]any{"contentType": "text/markdown"},
+		},
+	}
+
+	if payload.IsUrgent() && msg.Priority < urgentPriority {
+		msg.Priority = urgentPriority
+	}
+
+	if breakingChanges := notes.ToMarkdown(payload.BreakingChanges); breakingChanges != "" {
+		msg.Message += "\n\n**Breaking changes**\n\n" + notes.Truncate(breakingChanges, maxMessageLength)
+	}
+
+	if payload.URL != "" {
+		msg.Extras["client::notification"] = map[string]any{"click": map[string]any{"url": payload.URL}}
+	}
+
+	return msg
+}
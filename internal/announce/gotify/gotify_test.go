@@ -0,0 +1,121 @@
+//go:build unit
+
+package gotify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/stretchr/testify/assert"
+)
+
+func newFakeServer(t *testing.T) (*[]message, string) {
+	var messages []message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/message" || r.Header.Get("X-Gotify-Key") != "app-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"Unauthorized","errorCode":401,"errorDescription":"you need to provide a valid access token"}`))
+			return
+		}
+
+		var msg message
+		_ = json.NewDecoder(r.Body).Decode(&msg)
+		messages = append(messages, msg)
+
+		_, _ = w.Write([]byte(`{"id":1,"appid":1}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return &messages, server.URL
+}
+
+func TestNewGotifyAnnouncer(t *testing.T) {
+	cases := []struct {
+		caseName         string
+		appToken         string
+		priority         int
+		expectedPriority int
+		shouldPass       bool
+	}{
+		{"Defaults", "app-token", 0, defaultPriority, true},
+		{"Custom priority", "app-token", 2, 2, true},
+		{"Missing token", "", 0, 0, false},
+		{"Invalid priority", "app-token", 11, 0, false},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		announcer, err := NewGotifyAnnouncer("https://gotify.example.com/", tc.appToken, tc.priority)
+		if !tc.shouldPass {
+			assert.NotNil(t, err)
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.Equal(t, "https://gotify.example.com", announcer.ServerUrl)
+		assert.Equal(t, tc.expectedPriority, announcer.Priority)
+		assert.True(t, announcer.IsEnabled())
+	}
+}
+
+func TestGotifyAnnouncer_Notify(t *testing.T) {
+	cases := []struct {
+		caseName         string
+		appToken         string
+		priority         int
+		payload          *announce.AnnouncerPayload
+		expectedPriority int
+		expectedMessage  string
+		shouldPass       bool
+	}{
+		{
+			"Default priority", "app-token", 0,
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", URL: "https://example.com"},
+			5, "x-project v1.0.0 is out! Check it out at https://example.com", true,
+		},
+		{
+			"Urgent breaking release", "app-token", 0,
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v2.0.0", URL: "https://example.com",
+				BreakingChanges: "<p>Removed foo</p>", Priority: announce.PriorityHigh},
+			8, "x-project v2.0.0 is out! Check it out at https://example.com\n\n**Breaking changes**\n\nRemoved foo", true,
+		},
+		{
+			"Configured priority higher than urgent", "app-token", 10,
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", URL: "https://example.com", Priority: announce.PriorityHigh},
+			10, "x-project v1.0.0 is out! Check it out at https://example.com", true,
+		},
+		{
+			"Invalid token", "wrong-token", 0,
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", URL: "https://example.com"},
+			0, "", false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		messages, url := newFakeServer(t)
+		announcer, err := NewGotifyAnnouncer(url, tc.appToken, tc.priority)
+		assert.Nil(t, err)
+
+		err = announcer.Notify(tc.payload)
+		if !tc.shouldPass {
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), "valid access token")
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.Len(t, *messages, 1)
+		msg := (*messages)[0]
+		assert.Equal(t, tc.payload.ProjectName+" "+tc.payload.Version, msg.Title)
+		assert.Equal(t, tc.expectedMessage, msg.Message)
+		assert.Equal(t, tc.expectedPriority, msg.Priority)
+		assert.Equal(t, map[string]any{"contentType": "text/markdown"}, msg.Extras["client::display"])
+		assert.Equal(t, map[string]any{"click": map[string]any{"url": "https://example.com"}}, msg.Extras["client::notification"])
+	}
+}
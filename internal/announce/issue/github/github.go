@@ -0,0 +1,141 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/issue"
+)
+
+const defaultApiUrl = "https://api.github.com"
+
+type githubIssue struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+}
+
+// GithubTracker opens the issues in a GitHub repository over the REST API
+type GithubTracker struct {
+	client     *http.Client
+	apiUrl     string
+	token      string
+	repository string
+}
+
+// NewGithubTracker creates a new GithubTracker, apiUrl defaults to the public GitHub API and repository is in
+// owner/repo format. The token needs the issues write permission on the repository.
+func NewGithubTracker(apiUrl, token, repository string) (*GithubTracker, error) {
+	if token == "" || !strings.Contains(repository, "/") {
+		return nil, errors.New("github token and repository in owner/repo format are required")
+	}
+
+	if apiUrl == "" {
+		apiUrl = defaultApiUrl
+	}
+
+	return &GithubTracker{
+		client:     &http.Client{Timeout: 10 * time.Second},
+		apiUrl:     strings.TrimSuffix(apiUrl, "/"),
+		token:      token,
+		repository: repository,
+	}, nil
+}
+
+// FindIssue searches the issues of the repository by title, the search is fuzzy so the exact title is checked
+func (g *GithubTracker) FindIssue(title string) (*issue.Issue, error) {
+	query := fmt.Sprintf(`repo:%s is:issue in:title "%s"`, g.repository, strings.ReplaceAll(title, `"`, ""))
+
+	var result struct {
+		Items []githubIssue `json:"items"`
+	}
+
+	if err := g.do(http.MethodGet, "/search/issues?per_page=100&q="+url.QueryEscape(query), nil, &result); err != nil {
+		return nil, err
+	}
+
+	for _, item := range result.Items {
+		if item.Title == title {
+			return &issue.Issue{Number: item.Number, Title: item.Title, Body: item.Body}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// CreateIssue opens the issue and sets its number
+func (g *GithubTracker) CreateIssue(i *issue.Issue) error {
+	request := map[string]any{"title": i.Title, "body": i.Body}
+	if len(i.Labels) > 0 {
+		request["labels"] = i.Labels
+	}
+
+	if len(i.Assignees) > 0 {
+		request["assignees"] = i.Assignees
+	}
+
+	var created githubIssue
+	if err := g.do(http.MethodPost, fmt.Sprintf("/repos/%s/issues", g.repository), request, &created); err != nil {
+		return err
+	}
+
+	i.Number = created.Number
+	return nil
+}
+
+// UpdateIssue replaces the body of the issue
+func (g *GithubTracker) UpdateIssue(number int, body string) error {
+	return g.do(http.MethodPatch, fmt.Sprintf("/repos/%s/issues/%d", g.repository, number), map[string]any{"body": body}, nil)
+}
+
+func (g *GithubTracker) do(method, path string, request, response any) error {
+	var body io.Reader
+	if request != nil {
+		encoded, err := json.Marshal(request)
+		if err != nil {
+			return err
+		}
+
+		body = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequest(method, g.apiUrl+path, body)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+g.token)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if request != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiError struct {
+			Message string `json:"message"`
+		}
+
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&apiError)
+		return fmt.Errorf("github api returned status code %d: %s", resp.StatusCode, apiError.Message)
+	}
+
+	if response == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(response)
+}
@@ -0,0 +1,148 @@
+//go:build unit
+
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/issue"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeIssue struct {
+	Number    int      `json:"number"`
+	Title     string   `json:"title"`
+	Body      string   `json:"body"`
+	Labels    []string `json:"labels"`
+	Assignees []string `json:"assignees"`
+}
+
+// fakeApi is an in-memory GitHub issues API for the x/tracker repository, its search is fuzzy like the real one
+type fakeApi struct {
+	mu      sync.Mutex
+	issues  []*fakeIssue
+	queries []string
+}
+
+func newFakeApi(t *testing.T, issues ...*fakeIssue) (*fakeApi, string) {
+	api := &fakeApi{issues: issues}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+
+		if r.Header.Get("Authorization") != "Bearer gh-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"message":"Bad credentials"}`))
+			return
+		}
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/search/issues":
+			query := r.URL.Query().Get("q")
+			api.queries = append(api.queries, query)
+
+			words := strings.Fields(strings.Trim(query[strings.Index(query, `"`):], `"`))
+			items := []*fakeIssue{}
+			for _, i := range api.issues {
+				if strings.Contains(i.Title, words[0]) {
+					items = append(items, i)
+				}
+			}
+
+			_ = json.NewEncoder(w).Encode(map[string]any{"total_count": len(items), "items": items})
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/x/tracker/issues":
+			var created fakeIssue
+			_ = json.NewDecoder(r.Body).Decode(&created)
+			created.Number = len(api.issues) + 1
+			api.issues = append(api.issues, &created)
+
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(created)
+		case r.Method == http.MethodPatch && strings.HasPrefix(r.URL.Path, "/repos/x/tracker/issues/"):
+			var number int
+			_, _ = fmt.Sscanf(r.URL.Path, "/repos/x/tracker/issues/%d", &number)
+			if number < 1 || number > len(api.issues) {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"message":"Not Found"}`))
+				return
+			}
+
+			var update map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&update)
+			api.issues[number-1].Body = update["body"]
+			_ = json.NewEncoder(w).Encode(api.issues[number-1])
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Not Found"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return api, server.URL
+}
+
+func TestNewGithubTracker(t *testing.T) {
+	tracker, err := NewGithubTracker("", "gh-token", "tracker")
+	assert.Nil(t, tracker)
+	assert.NotNil(t, err)
+
+	tracker, err = NewGithubTracker("", "gh-token", "x/tracker")
+	assert.Nil(t, err)
+	assert.Equal(t, defaultApiUrl, tracker.apiUrl)
+}
+
+func TestGithubTracker(t *testing.T) {
+	cases := []struct {
+		caseName       string
+		token          string
+		existing       []*fakeIssue
+		expectedIssues []*fakeIssue
+		shouldPass     bool
+	}{
+		{
+			"Opens the issue once and updates it afterwards", "gh-token",
+			[]*fakeIssue{{Number: 1, Title: "Upgrade terraform to v1.8.0-rc1", Body: "rc"}},
+			[]*fakeIssue{
+				{Number: 1, Title: "Upgrade terraform to v1.8.0-rc1", Body: "rc"},
+				{Number: 2, Title: "Upgrade terraform to v1.8.0", Body: "v1.8.0 https://example.com/2", Labels: []string{"upgrade"},
+					Assignees: []string{"alice"}},
+			},
+			true,
+		},
+		{"Bad credentials", "wrong-token", nil, nil, false},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		api, url := newFakeApi(t, tc.existing...)
+		tracker, err := NewGithubTracker(url, tc.token, "x/tracker")
+		assert.Nil(t, err)
+
+		announcer, err := issue.NewIssueAnnouncer(tracker, []string{"upgrade"}, []string{"alice"}, "{{ .Version }} {{ .URL }}")
+		assert.Nil(t, err)
+
+		for _, u := range []string{"https://example.com/1", "https://example.com/2"} {
+			err = announcer.Notify(&announce.AnnouncerPayload{ProjectName: "terraform", Version: "v1.8.0", URL: u})
+			if !tc.shouldPass {
+				assert.NotNil(t, err)
+				assert.Contains(t, err.Error(), "Bad credentials")
+			}
+		}
+
+		if !tc.shouldPass {
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.Equal(t, tc.expectedIssues, api.issues)
+		assert.Equal(t, `repo:x/tracker is:issue in:title "Upgrade terraform to v1.8.0"`, api.queries[0])
+	}
+}
@@ -0,0 +1,168 @@
+package gitlab
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/issue"
+)
+
+const defaultApiUrl = "https://gitlab.com"
+
+type gitlabIssue struct {
+	Iid         int    `json:"iid"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+type gitlabUser struct {
+	Id       int    `json:"id"`
+	Username string `json:"username"`
+}
+
+// GitlabTracker opens the issues in a GitLab project over the REST API
+type GitlabTracker struct {
+	client  *http.Client
+	apiUrl  string
+	token   string
+	project string
+}
+
+// NewGitlabTracker creates a new GitlabTracker, apiUrl defaults to https://gitlab.com and project is the path like
+// group/project or the numeric id of the project. The token needs the api scope.
+func NewGitlabTracker(apiUrl, token, project string) (*GitlabTracker, error) {
+	if token == "" || project == "" {
+		return nil, errors.New("gitlab token and project are required")
+	}
+
+	if apiUrl == "" {
+		apiUrl = defaultApiUrl
+	}
+
+	return &GitlabTracker{
+		client:  &http.Client{Timeout: 10 * time.Second},
+		apiUrl:  strings.TrimSuffix(apiUrl, "/"),
+		token:   token,
+		project: project,
+	}, nil
+}
+
+// FindIssue searches the issues of the project by title, the search is fuzzy so the exact title is checked
+func (g *GitlabTracker) FindIssue(title string) (*issue.Issue, error) {
+	query := url.Values{}
+	query.Set("search", title)
+	query.Set("in", "title")
+	query.Set("scope", "all")
+	query.Set("per_page", "100")
+
+	var issues []gitlabIssue
+	if err := g.do(http.MethodGet, g.projectPath("/issues?"+query.Encode()), nil, &issues); err != nil {
+		return nil, err
+	}
+
+	for _, item := range issues {
+		if item.Title == title {
+			return &issue.Issue{Number: item.Iid, Title: item.Title, Body: item.Description}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// CreateIssue opens the issue and sets its number, the assignees are resolved from their usernames
+func (g *GitlabTracker) CreateIssue(i *issue.Issue) error {
+	request := map[string]any{"title": i.Title, "description": i.Body}
+	if len(i.Labels) > 0 {
+		request["labels"] = strings.Join(i.Labels, ",")
+	}
+
+	if len(i.Assignees) > 0 {
+		ids, err := g.userIds(i.Assignees)
+		if err != nil {
+			return err
+		}
+
+		request["assignee_ids"] = ids
+	}
+
+	var created gitlabIssue
+	if err := g.do(http.MethodPost, g.projectPath("/issues"), request, &created); err != nil {
+		return err
+	}
+
+	i.Number = created.Iid
+	return nil
+}
+
+// UpdateIssue replaces the description of the issue
+func (g *GitlabTracker) UpdateIssue(number int, body string) error {
+	return g.do(http.MethodPut, g.projectPath(fmt.Sprintf("/issues/%d", number)), map[string]any{"description": body}, nil)
+}
+
+func (g *GitlabTracker) userIds(usernames []string) ([]int, error) {
+	var ids []int
+	for _, username := range usernames {
+		var users []gitlabUser
+		if err := g.do(http.MethodGet, "/api/v4/users?username="+url.QueryEscape(strings.TrimPrefix(username, "@")), nil, &users); err != nil {
+			return nil, err
+		}
+
+		if len(users) == 0 {
+			return nil, fmt.Errorf("gitlab user %s not found", username)
+		}
+
+		ids = append(ids, users[0].Id)
+	}
+
+	return ids, nil
+}
+
+func (g *GitlabTracker) projectPath(path string) string {
+	return "/api/v4/projects/" + url.PathEscape(g.project) + path
+}
+
+func (g *GitlabTracker) do(method, path string, request, response any) error {
+	var body io.Reader
+	if request != nil {
+		encoded, err := json.Marshal(request)
+		if err != nil {
+			return err
+		}
+
+		body = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequest(method, g.apiUrl+path, body)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("PRIVATE-TOKEN", g.token)
+	if request != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("gitlab api returned status code %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	if response == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(response)
+}
@@ -0,0 +1,151 @@
+//go:build unit
+
+package gitlab
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/issue"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeIssue struct {
+	Iid         int    `json:"iid"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Labels      string `json:"labels"`
+	AssigneeIds []int  `json:"assignee_ids"`
+}
+
+// fakeApi is an in-memory GitLab issues API for the group/tracker project, its search is fuzzy like the real one
+type fakeApi struct {
+	mu     sync.Mutex
+	issues []*fakeIssue
+	paths  []string
+}
+
+func newFakeApi(t *testing.T, issues ...*fakeIssue) (*fakeApi, string) {
+	api := &fakeApi{issues: issues}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+
+		if r.Header.Get("PRIVATE-TOKEN") != "gl-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"message":"401 Unauthorized"}`))
+			return
+		}
+
+		api.paths = append(api.paths, r.URL.RawPath)
+		const project = "/api/v4/projects/group/tracker/issues"
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/users":
+			users := map[string]int{"alice": 11, "bob": 12}
+			if id, ok := users[r.URL.Query().Get("username")]; ok {
+				_, _ = fmt.Fprintf(w, `[{"id":%d,"username":%q}]`, id, r.URL.Query().Get("username"))
+				return
+			}
+
+			_, _ = w.Write([]byte(`[]`))
+		case r.Method == http.MethodGet && r.URL.Path == project:
+			words := strings.Fields(r.URL.Query().Get("search"))
+			items := []*fakeIssue{}
+			for _, i := range api.issues {
+				if strings.Contains(i.Title, words[0]) {
+					items = append(items, i)
+				}
+			}
+
+			_ = json.NewEncoder(w).Encode(items)
+		case r.Method == http.MethodPost && r.URL.Path == project:
+			var created fakeIssue
+			_ = json.NewDecoder(r.Body).Decode(&created)
+			created.Iid = len(api.issues) + 1
+			api.issues = append(api.issues, &created)
+
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(created)
+		case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, project+"/"):
+			var iid int
+			_, _ = fmt.Sscanf(strings.TrimPrefix(r.URL.Path, project+"/"), "%d", &iid)
+
+			var update map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&update)
+			api.issues[iid-1].Description = update["description"]
+			_ = json.NewEncoder(w).Encode(api.issues[iid-1])
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"404 Not Found"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return api, server.URL
+}
+
+func TestNewGitlabTracker(t *testing.T) {
+	tracker, err := NewGitlabTracker("", "", "group/tracker")
+	assert.Nil(t, tracker)
+	assert.NotNil(t, err)
+
+	tracker, err = NewGitlabTracker("", "gl-token", "group/tracker")
+	assert.Nil(t, err)
+	assert.Equal(t, defaultApiUrl, tracker.apiUrl)
+}
+
+func TestGitlabTracker(t *testing.T) {
+	cases := []struct {
+		caseName       string
+		token          string
+		assignees      []string
+		existing       []*fakeIssue
+		expectedIssues []*fakeIssue
+		shouldPass     bool
+	}{
+		{
+			"Opens the issue once and updates it afterwards", "gl-token", []string{"alice", "@bob"},
+			[]*fakeIssue{{Iid: 1, Title: "Upgrade terraform to v1.8.0-rc1", Description: "rc"}},
+			[]*fakeIssue{
+				{Iid: 1, Title: "Upgrade terraform to v1.8.0-rc1", Description: "rc"},
+				{Iid: 2, Title: "Upgrade terraform to v1.8.0", Description: "v1.8.0 https://example.com/2", Labels: "upgrade,terraform",
+					AssigneeIds: []int{11, 12}},
+			},
+			true,
+		},
+		{"Unknown assignee", "gl-token", []string{"mallory"}, nil, nil, false},
+		{"Unauthorized", "wrong-token", nil, nil, nil, false},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		api, url := newFakeApi(t, tc.existing...)
+		tracker, err := NewGitlabTracker(url, tc.token, "group/tracker")
+		assert.Nil(t, err)
+
+		announcer, err := issue.NewIssueAnnouncer(tracker, []string{"upgrade", "terraform"}, tc.assignees, "{{ .Version }} {{ .URL }}")
+		assert.Nil(t, err)
+
+		for _, u := range []string{"https://example.com/1", "https://example.com/2"} {
+			err = announcer.Notify(&announce.AnnouncerPayload{ProjectName: "terraform", Version: "v1.8.0", URL: u})
+			if !tc.shouldPass {
+				assert.NotNil(t, err)
+			}
+		}
+
+		if !tc.shouldPass {
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.Equal(t, tc.expectedIssues, api.issues)
+		assert.True(t, strings.HasPrefix(api.paths[0], "/api/v4/projects/group%2Ftracker/issues"))
+	}
+}
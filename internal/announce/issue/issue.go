@@ -0,0 +1,120 @@
+package issue
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"text/template"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/types"
+)
+
+// defaultTemplate renders the summary, breaking changes and release notes as Markdown
+const defaultTemplate = `{{ .Summary }}
+{{ with .BreakingChanges }}
+## ⚠️ Breaking changes
+
+{{ markdown . }}
+{{ end }}{{ with .Notes }}
+## Release notes
+
+{{ markdown . }}
+{{ end }}`
+
+// Issue is an issue in the tracking repository
+type Issue struct {
+	// Number is the number of the issue in the repository, it is set by the Tracker
+	Number    int
+	Title     string
+	Body      string
+	Labels    []string
+	Assignees []string
+}
+
+// Tracker is the issue tracker which the issues are opened in
+type Tracker interface {
+	// FindIssue returns the issue with exactly the same title in any state, nil if there is none
+	FindIssue(title string) (*Issue, error)
+	CreateIssue(issue *Issue) error
+	// UpdateIssue replaces the body of the issue, labels and assignees are left untouched
+	UpdateIssue(number int, body string) error
+}
+
+// IssueAnnouncer is the announcer that opens an issue in a tracking repository for each release, the issue of the
+// release is updated instead if it already exists
+type IssueAnnouncer struct {
+	Tracker   Tracker
+	Labels    []string
+	Assignees []string
+	template  *template.Template
+}
+
+// NewIssueAnnouncer creates a new IssueAnnouncer, bodyTemplate is a text/template executed with announce.TemplateData
+// and defaults to the summary, breaking changes and release notes in Markdown.
+func NewIssueAnnouncer(tracker Tracker, labels, assignees []string, bodyTemplate string) (*IssueAnnouncer, error) {
+	if tracker == nil {
+		return nil, errors.New("issue tracker is required")
+	}
+
+	if bodyTemplate == "" {
+		bodyTemplate = defaultTemplate
+	}
+
+	tmpl, err := announce.ParseTemplate("issue", bodyTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid issue body template: %w", err)
+	}
+
+	return &IssueAnnouncer{
+		Tracker:   tracker,
+		Labels:    labels,
+		Assignees: assignees,
+		template:  tmpl,
+	}, nil
+}
+
+// Notify opens the issue of the release or updates the existing one. Removed releases only update the existing issue
+// since there is nothing to upgrade to. Routed payloads assign the new issue to their recipients instead.
+func (i *IssueAnnouncer) Notify(payload *announce.AnnouncerPayload) error {
+	var body bytes.Buffer
+	if err := i.template.Execute(&body, announce.NewTemplateData(payload)); err != nil {
+		return fmt.Errorf("failed to render issue body: %w", err)
+	}
+
+	title := Title(payload.ProjectName, payload.Version)
+	existing, err := i.Tracker.FindIssue(title)
+	if err != nil {
+		return fmt.Errorf("failed to search for existing issue: %w", err)
+	}
+
+	if existing != nil {
+		return i.Tracker.UpdateIssue(existing.Number, body.String())
+	}
+
+	if payload.GetEvent() == types.EventRemoved {
+		return nil
+	}
+
+	assignees := i.Assignees
+	if len(payload.Recipients) > 0 {
+		assignees = payload.Recipients
+	}
+
+	return i.Tracker.CreateIssue(&Issue{
+		Title:     title,
+		Body:      body.String(),
+		Labels:    i.Labels,
+		Assignees: assignees,
+	})
+}
+
+// IsEnabled checks if the IssueAnnouncer is enabled.
+func (i *IssueAnnouncer) IsEnabled() bool {
+	return i.Tracker != nil
+}
+
+// Title returns the title of the issue of the release, it is used to find the existing issue of the release
+func Title(projectName, version string) string {
+	return fmt.Sprintf("Upgrade %s to %s", projectName, version)
+}
@@ -0,0 +1,125 @@
+//go:build unit
+
+package issue
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTracker keeps the issues in memory
+type fakeTracker struct {
+	issues  []*Issue
+	findErr error
+}
+
+func (f *fakeTracker) FindIssue(title string) (*Issue, error) {
+	if f.findErr != nil {
+		return nil, f.findErr
+	}
+
+	for _, i := range f.issues {
+		if i.Title == title {
+			return i, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (f *fakeTracker) CreateIssue(issue *Issue) error {
+	issue.Number = len(f.issues) + 1
+	f.issues = append(f.issues, issue)
+	return nil
+}
+
+func (f *fakeTracker) UpdateIssue(number int, body string) error {
+	f.issues[number-1].Body = body
+	return nil
+}
+
+func TestNewIssueAnnouncer(t *testing.T) {
+	announcer, err := NewIssueAnnouncer(nil, nil, nil, "")
+	assert.Nil(t, announcer)
+	assert.NotNil(t, err)
+
+	announcer, err = NewIssueAnnouncer(&fakeTracker{}, nil, nil, "{{ .Summary ")
+	assert.Nil(t, announcer)
+	assert.NotNil(t, err)
+
+	announcer, err = NewIssueAnnouncer(&fakeTracker{}, nil, nil, "")
+	assert.Nil(t, err)
+	assert.True(t, announcer.IsEnabled())
+}
+
+func TestIssueAnnouncer_Notify(t *testing.T) {
+	cases := []struct {
+		caseName       string
+		existing       []*Issue
+		template       string
+		payload        *announce.AnnouncerPayload
+		findErr        error
+		expectedIssues []*Issue
+		shouldPass     bool
+	}{
+		{
+			"Creates the issue with default template", nil, "",
+			&announce.AnnouncerPayload{ProjectName: "terraform", Version: "v1.8.0", URL: "https://example.com",
+				Notes: "<p>Fixes <strong>bug</strong></p>", BreakingChanges: "<ul><li>Removed foo</li></ul>"},
+			nil,
+			[]*Issue{{Number: 1, Title: "Upgrade terraform to v1.8.0", Labels: []string{"upgrade"}, Assignees: []string{"alice"},
+				Body: "terraform v1.8.0 is out! Check it out at https://example.com\n\n## ⚠️ Breaking changes\n\n- Removed foo\n\n" +
+					"## Release notes\n\nFixes **bug**\n"}},
+			true,
+		},
+		{
+			"Updates the existing issue", []*Issue{{Number: 1, Title: "Upgrade terraform to v1.8.0", Body: "old", Labels: []string{"triaged"}}},
+			"{{ .Summary }}",
+			&announce.AnnouncerPayload{ProjectName: "terraform", Version: "v1.8.0", URL: "https://example.com/new", Event: types.EventUpdated,
+				Changes: []string{"url"}},
+			nil,
+			[]*Issue{{Number: 1, Title: "Upgrade terraform to v1.8.0", Labels: []string{"triaged"},
+				Body: "terraform v1.8.0 is updated (url)! Check it out at https://example.com/new"}},
+			true,
+		},
+		{
+			"Removed release does not open an issue", nil, "",
+			&announce.AnnouncerPayload{ProjectName: "terraform", Version: "v1.8.0", Event: types.EventRemoved},
+			nil, nil, true,
+		},
+		{
+			"Routed payload is assigned to recipients", nil, "{{ .Version }}",
+			&announce.AnnouncerPayload{ProjectName: "terraform", Version: "v1.8.1", Recipients: []string{"security-team"}},
+			nil,
+			[]*Issue{{Number: 1, Title: "Upgrade terraform to v1.8.1", Labels: []string{"upgrade"}, Assignees: []string{"security-team"},
+				Body: "v1.8.1"}},
+			true,
+		},
+		{
+			"Search failure", nil, "",
+			&announce.AnnouncerPayload{ProjectName: "terraform", Version: "v1.8.0"},
+			errors.New("rate limited"), nil, false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		tracker := &fakeTracker{issues: tc.existing, findErr: tc.findErr}
+		announcer, err := NewIssueAnnouncer(tracker, []string{"upgrade"}, []string{"alice"}, tc.template)
+		assert.Nil(t, err)
+
+		err = announcer.Notify(tc.payload)
+		if !tc.shouldPass {
+			assert.NotNil(t, err)
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.Equal(t, tc.expectedIssues, tracker.issues)
+	}
+}
@@ -0,0 +1,317 @@
+package jira
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/notes"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/types"
+)
+
+const (
+	defaultIssueType = "Task"
+
+	defaultSummaryTemplate     = "Upgrade {{ .ProjectName }} to {{ .Version }}"
+	defaultDescriptionTemplate = `{{ .Summary }}
+{{ with .BreakingChanges }}
+h3. Breaking changes
+
+{{ text . }}
+{{ end }}{{ with .Notes }}
+h3. Release notes
+
+{{ text . }}
+{{ end }}`
+
+	// maxSummaryLength is the limit of the summary field of Jira
+	maxSummaryLength = 255
+	// maxDescriptionLength is the limit of the description field and the comments of Jira
+	maxDescriptionLength = 32767
+	// maxLabelLength is the limit of the labels of Jira
+	maxLabelLength = 255
+	// releaseLabelPrefix is the prefix of the label which the issue of a release is found with
+	releaseLabelPrefix = "rss-feed-filterer:"
+)
+
+// issue is the issue in the response bodies of the create issue and enhanced search APIs
+type issue struct {
+	Key string `json:"key"`
+}
+
+// searchResponse is the response body of the enhanced search API, the search API is removed from Jira Cloud
+type searchResponse struct {
+	Issues []issue `json:"issues"`
+}
+
+// errorResponse is the error body of the Jira REST API
+type errorResponse struct {
+	ErrorMessages []string          `json:"errorMessages"`
+	Errors        map[string]string `json:"errors"`
+}
+
+// JiraAnnouncer is the announcer that creates a Jira issue for each release over the REST API
+type JiraAnnouncer struct {
+	BaseUrl    string
+	Username   string
+	Token      string
+	ProjectKey string
+	IssueType  string
+	Components []string
+	Labels     []string
+	// Priorities maps the change levels of announce package to the Jira priority names, the default priority of Jira is used for the
+	// unmapped levels
+	Priorities   map[string]string
+	summary      *template.Template
+	description  *template.Template
+	customFields map[string]*template.Template
+	client       *http.Client
+}
+
+// NewJiraAnnouncer creates a new JiraAnnouncer. username and token are used for basic auth as on Jira Cloud, token is
+// sent as a personal access token if username is empty. issueType defaults to Task. summary, description and the
+// values of customFields are text/templates executed with announce.TemplateData, custom field values which render to
+// JSON objects or arrays are sent as is.
+func NewJiraAnnouncer(baseUrl, username, token, projectKey, issueType string, components, labels []string,
+	priorities map[string]string, summary, description string, customFields map[string]string) (*JiraAnnouncer, error) {
+	if baseUrl == "" || token == "" || projectKey == "" {
+		return nil, errors.New("jira base url, token and project key are required")
+	}
+
+	if err := announce.ValidateLevels(priorities); err != nil {
+		return nil, fmt.Errorf("invalid jira priorities: %w", err)
+	}
+
+	if issueType == "" {
+		issueType = defaultIssueType
+	}
+
+	if summary == "" {
+		summary = defaultSummaryTemplate
+	}
+
+	if description == "" {
+		description = defaultDescriptionTemplate
+	}
+
+	summaryTemplate, err := announce.ParseTemplate("summary", summary)
+	if err != nil {
+		return nil, fmt.Errorf("invalid jira summary template: %w", err)
+	}
+
+	descriptionTemplate, err := announce.ParseTemplate("description", description)
+	if err != nil {
+		return nil, fmt.Errorf("invalid jira description template: %w", err)
+	}
+
+	customFieldTemplates := make(map[string]*template.Template, len(customFields))
+	for field, value := range customFields {
+		if customFieldTemplates[field], err = announce.ParseTemplate(field, value); err != nil {
+			return nil, fmt.Errorf("invalid jira custom field template %s: %w", field, err)
+		}
+	}
+
+	return &JiraAnnouncer{
+		BaseUrl:      strings.TrimSuffix(baseUrl, "/"),
+		Username:     username,
+		Token:        token,
+		ProjectKey:   projectKey,
+		IssueType:    issueType,
+		Components:   components,
+		Labels:       labels,
+		Priorities:   priorities,
+		summary:      summaryTemplate,
+		description:  descriptionTemplate,
+		customFields: customFieldTemplates,
+		client:       &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Notify creates the issue of the release, which is labeled with ReleaseLabel. Updated and removed releases are
+// commented on the existing issue of the release instead, removed releases without an issue are skipped since there
+// is nothing to upgrade to. New releases which already have an issue are skipped, so they are not duplicated.
+func (j *JiraAnnouncer) Notify(payload *announce.AnnouncerPayload) error {
+	fields, err := j.buildFields(payload)
+	if err != nil {
+		return err
+	}
+
+	label := ReleaseLabel(payload)
+	key, err := j.findIssue(label)
+	if err != nil {
+		return fmt.Errorf("failed to search for existing jira issue: %w", err)
+	}
+
+	event := payload.GetEvent()
+	switch {
+	case key != "" && event == types.EventNew:
+		return nil
+	case key != "":
+		return j.do(http.MethodPost, "/rest/api/2/issue/"+key+"/comment", map[string]any{"body": fields["description"]},
+			http.StatusCreated, nil)
+	case event == types.EventRemoved:
+		return nil
+	}
+
+	fields["labels"] = append(append([]string{}, j.Labels...), label)
+
+	return j.do(http.MethodPost, "/rest/api/2/issue", map[string]any{"fields": fields}, http.StatusCreated, nil)
+}
+
+// IsEnabled checks if the JiraAnnouncer is enabled.
+func (j *JiraAnnouncer) IsEnabled() bool {
+	return j.BaseUrl != "" && j.Token != "" && j.ProjectKey != ""
+}
+
+// ReleaseLabel returns the label of the issue of the release, it is used to find the existing issue of the release
+func ReleaseLabel(payload *announce.AnnouncerPayload) string {
+	// the labels of Jira can not contain spaces
+	return notes.Truncate(releaseLabelPrefix+strings.Join(strings.Fields(payload.DedupKey()), "_"), maxLabelLength)
+}
+
+// findIssue returns the key of the issue with the given label in any state, empty if there is none
+func (j *JiraAnnouncer) findIssue(label string) (string, error) {
+	jql := fmt.Sprintf("project = %s AND labels = %s", quote(j.ProjectKey), quote(label))
+	query := url.Values{"jql": {jql}, "maxResults": {"1"}, "fields": {"key"}}
+
+	var response searchResponse
+	if err := j.do(http.MethodGet, "/rest/api/2/search/jql?"+query.Encode(), nil, http.StatusOK, &response); err != nil {
+		return "", err
+	}
+
+	if len(response.Issues) == 0 {
+		return "", nil
+	}
+
+	return response.Issues[0].Key, nil
+}
+
+// do sends the request to the REST API, body is encoded as JSON if it is not nil and the response is decoded into
+// result if it is not nil
+func (j *JiraAnnouncer) do(method, path string, body any, expectedStatus int, result any) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequest(method, j.BaseUrl+path, reader)
+	if err != nil {
+		return err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if j.Username != "" {
+		req.SetBasicAuth(j.Username, j.Token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+j.Token)
+	}
+
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("an error occurred while calling jira api: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != expectedStatus {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		var response errorResponse
+		_ = json.Unmarshal(respBody, &response)
+
+		messages := response.ErrorMessages
+		for field, message := range response.Errors {
+			messages = append(messages, field+": "+message)
+		}
+
+		return fmt.Errorf("jira api returned status code %d: %s", resp.StatusCode, strings.Join(messages, ", "))
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+func (j *JiraAnnouncer) buildFields(payload *announce.AnnouncerPayload) (map[string]any, error) {
+	data := announce.NewTemplateData(payload)
+
+	summary, err := execute(j.summary, data)
+	if err != nil {
+		return nil, err
+	}
+
+	description, err := execute(j.description, data)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]any{
+		"project":     map[string]string{"key": j.ProjectKey},
+		"issuetype":   map[string]string{"name": j.IssueType},
+		"summary":     notes.Truncate(strings.TrimSpace(summary), maxSummaryLength),
+		"description": notes.Truncate(description, maxDescriptionLength),
+	}
+
+	if len(j.Components) > 0 {
+		var components []map[string]string
+		for _, component := range j.Components {
+			components = append(components, map[string]string{"name": component})
+		}
+
+		fields["components"] = components
+	}
+
+	if priority, ok := j.Priorities[payload.Level()]; ok {
+		fields["priority"] = map[string]string{"name": priority}
+	}
+
+	for field, tmpl := range j.customFields {
+		value, err := execute(tmpl, data)
+		if err != nil {
+			return nil, err
+		}
+
+		// objects and arrays are needed for the select, user and multi value fields
+		if trimmed := strings.TrimSpace(value); strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+			if json.Valid([]byte(trimmed)) {
+				fields[field] = json.RawMessage(trimmed)
+				continue
+			}
+		}
+
+		fields[field] = value
+	}
+
+	return fields, nil
+}
+
+// quote quotes the value as a JQL string
+func quote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+func execute(tmpl *template.Template, data *announce.TemplateData) (string, error) {
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render jira template %s: %w", tmpl.Name(), err)
+	}
+
+	return b.String(), nil
+}
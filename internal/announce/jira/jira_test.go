@@ -0,0 +1,224 @@
+//go:build unit
+
+package jira
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeRequest struct {
+	method   string
+	path     string
+	jql      string
+	username string
+	password string
+	bearer   string
+	fields   map[string]any
+	comment  string
+}
+
+// newFakeJira creates a Jira which finds the issue with the given key, none if it is empty, and responds to the other
+// requests with the given status and response
+func newFakeJira(t *testing.T, existing string, status int, response string) (*[]fakeRequest, string) {
+	var requests []fakeRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Fields map[string]any `json:"fields"`
+			Body   string         `json:"body"`
+		}
+
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		username, password, _ := r.BasicAuth()
+		requests = append(requests, fakeRequest{method: r.Method, path: r.URL.Path, jql: r.URL.Query().Get("jql"),
+			username: username, password: password, bearer: r.Header.Get("Authorization"), fields: body.Fields,
+			comment: body.Body})
+
+		switch r.URL.Path {
+		case "/rest/api/2/search":
+			// the search API is removed from Jira Cloud in favor of the enhanced search API
+			w.WriteHeader(http.StatusGone)
+			_, _ = w.Write([]byte(`{"errorMessages":["The requested API has been removed."],"errors":{}}`))
+			return
+		case "/rest/api/2/search/jql":
+			issues := []issue{}
+			if existing != "" {
+				issues = append(issues, issue{Key: existing})
+			}
+
+			_ = json.NewEncoder(w).Encode(searchResponse{Issues: issues})
+			return
+		}
+
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+
+	return &requests, server.URL
+}
+
+func TestNewJiraAnnouncer(t *testing.T) {
+	cases := []struct {
+		caseName     string
+		token        string
+		priorities   map[string]string
+		summary      string
+		customFields map[string]string
+		shouldPass   bool
+	}{
+		{"Defaults", "token", nil, "", nil, true},
+		{"Missing token", "", nil, "", nil, false},
+		{"Unknown priority level", "token", map[string]string{"critical": "Highest"}, "", nil, false},
+		{"Invalid summary template", "token", nil, "{{ .Version ", nil, false},
+		{"Invalid custom field template", "token", nil, "", map[string]string{"customfield_1": "{{ "}, false},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		announcer, err := NewJiraAnnouncer("https://jira.example.com/", "", tc.token, "OPS", "", nil, nil, tc.priorities,
+			tc.summary, "", tc.customFields)
+		if !tc.shouldPass {
+			assert.NotNil(t, err)
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.Equal(t, "https://jira.example.com", announcer.BaseUrl)
+		assert.Equal(t, defaultIssueType, announcer.IssueType)
+		assert.True(t, announcer.IsEnabled())
+	}
+}
+
+func TestJiraAnnouncer_Notify(t *testing.T) {
+	priorities := map[string]string{announce.LevelSecurity: "Highest", announce.LevelBreaking: "High"}
+	customFields := map[string]string{
+		"customfield_10010": "{{ .Version }}",
+		"customfield_10020": `{"value": "{{ .ProjectName }}"}`,
+	}
+
+	cases := []struct {
+		caseName         string
+		username         string
+		status           int
+		response         string
+		payload          *announce.AnnouncerPayload
+		expectedPriority any
+		shouldPass       bool
+	}{
+		{
+			"Cloud with basic auth and breaking changes", "bot@example.com", http.StatusCreated, `{"id":"1","key":"OPS-1"}`,
+			&announce.AnnouncerPayload{ProjectName: "terraform", Version: "v1.8.0", URL: "https://example.com",
+				Notes: "<p>Fixes <strong>bug</strong></p>", BreakingChanges: "<ul><li>Removed foo</li></ul>"},
+			map[string]any{"name": "High"}, true,
+		},
+		{
+			"Server with personal access token", "", http.StatusCreated, `{"id":"1","key":"OPS-1"}`,
+			&announce.AnnouncerPayload{ProjectName: "terraform", Version: "v1.8.0", URL: "https://example.com",
+				Notes: "<p>Fixes <strong>bug</strong></p>", BreakingChanges: "<ul><li>Removed foo</li></ul>"},
+			map[string]any{"name": "High"}, true,
+		},
+		{
+			"Invalid field", "", http.StatusBadRequest, `{"errorMessages":[],"errors":{"components":"Component name 'x' is not valid"}}`,
+			&announce.AnnouncerPayload{ProjectName: "terraform", Version: "v1.8.0"},
+			nil, false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		requests, url := newFakeJira(t, "", tc.status, tc.response)
+		announcer, err := NewJiraAnnouncer(url, tc.username, "secret", "OPS", "Story", []string{"platform"}, []string{"upgrade"},
+			priorities, "", "", customFields)
+		assert.Nil(t, err)
+
+		err = announcer.Notify(tc.payload)
+		if !tc.shouldPass {
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), "components: Component name 'x' is not valid")
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.Len(t, *requests, 2)
+		assert.Equal(t, "/rest/api/2/search/jql", (*requests)[0].path)
+		assert.Equal(t, `project = "OPS" AND labels = "rss-feed-filterer:terraform@v1.8.0"`, (*requests)[0].jql)
+		request := (*requests)[1]
+		assert.Equal(t, "/rest/api/2/issue", request.path)
+		if tc.username != "" {
+			assert.Equal(t, tc.username, request.username)
+			assert.Equal(t, "secret", request.password)
+		} else {
+			assert.Equal(t, "Bearer secret", request.bearer)
+		}
+
+		assert.Equal(t, map[string]any{"key": "OPS"}, request.fields["project"])
+		assert.Equal(t, map[string]any{"name": "Story"}, request.fields["issuetype"])
+		assert.Equal(t, "Upgrade terraform to v1.8.0", request.fields["summary"])
+		assert.Equal(t, "terraform v1.8.0 is out! Check it out at https://example.com\n\nh3. Breaking changes\n\n- Removed foo\n\n"+
+			"h3. Release notes\n\nFixes bug\n", request.fields["description"])
+		assert.Equal(t, []any{map[string]any{"name": "platform"}}, request.fields["components"])
+		assert.Equal(t, []any{"upgrade", "rss-feed-filterer:terraform@v1.8.0"}, request.fields["labels"])
+		assert.Equal(t, tc.expectedPriority, request.fields["priority"])
+		assert.Equal(t, "v1.8.0", request.fields["customfield_10010"])
+		assert.Equal(t, map[string]any{"value": "terraform"}, request.fields["customfield_10020"])
+	}
+
+	requests, url := newFakeJira(t, "", http.StatusCreated, `{"key":"OPS-2"}`)
+	announcer, err := NewJiraAnnouncer(url, "", "secret", "OPS", "", nil, nil, priorities, "", "", nil)
+	assert.Nil(t, err)
+	assert.Nil(t, announcer.Notify(&announce.AnnouncerPayload{ProjectName: "terraform", Version: "v1.8.1",
+		Notes: "<p>" + strings.Repeat("a", 40000) + "</p>"}))
+	assert.Nil(t, (*requests)[1].fields["priority"])
+	assert.Nil(t, (*requests)[1].fields["components"])
+	assert.Equal(t, []any{"rss-feed-filterer:terraform@v1.8.1"}, (*requests)[1].fields["labels"])
+	assert.Equal(t, maxDescriptionLength, utf8.RuneCountInString((*requests)[1].fields["description"].(string)))
+}
+
+func TestJiraAnnouncer_Notify_existingIssue(t *testing.T) {
+	cases := []struct {
+		caseName         string
+		existing         string
+		event            types.EventType
+		expectedRequests []string
+	}{
+		{"New release without an issue", "", types.EventNew, []string{"GET /rest/api/2/search/jql", "POST /rest/api/2/issue"}},
+		{"New release with an issue", "OPS-1", types.EventNew, []string{"GET /rest/api/2/search/jql"}},
+		{"Updated release with an issue", "OPS-1", types.EventUpdated,
+			[]string{"GET /rest/api/2/search/jql", "POST /rest/api/2/issue/OPS-1/comment"}},
+		{"Removed release with an issue", "OPS-1", types.EventRemoved,
+			[]string{"GET /rest/api/2/search/jql", "POST /rest/api/2/issue/OPS-1/comment"}},
+		{"Updated release without an issue", "", types.EventUpdated, []string{"GET /rest/api/2/search/jql", "POST /rest/api/2/issue"}},
+		{"Removed release without an issue", "", types.EventRemoved, []string{"GET /rest/api/2/search/jql"}},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		requests, url := newFakeJira(t, tc.existing, http.StatusCreated, `{"key":"OPS-2"}`)
+		announcer, err := NewJiraAnnouncer(url, "", "secret", "OPS", "", nil, nil, nil, "", "", nil)
+		assert.Nil(t, err)
+		assert.Nil(t, announcer.Notify(&announce.AnnouncerPayload{ProjectName: "hashicorp/terraform", Version: "v1.8.0",
+			URL: "https://example.com", Event: tc.event, Changes: []string{"notes"}}))
+
+		var sent []string
+		for _, request := range *requests {
+			sent = append(sent, request.method+" "+request.path)
+		}
+
+		assert.Equal(t, tc.expectedRequests, sent)
+		if last := (*requests)[len(*requests)-1]; strings.HasSuffix(last.path, "/comment") {
+			assert.True(t, strings.HasPrefix(last.comment, "hashicorp/terraform v1.8.0 is "))
+		}
+	}
+}
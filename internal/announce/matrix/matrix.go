@@ -0,0 +1,219 @@
+package matrix
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/notes"
+)
+
+const (
+	// maxNotesLength is the maximum length of each notes block, it is halved until the content fits maxContentSize
+	maxNotesLength = 20000
+	// minNotesLength is the length under which the notes blocks are not shortened any further
+	minNotesLength = 100
+	// maxContentSize is the maximum size of the encoded content, events are limited to 64KiB by the homeservers and
+	// the rest is left for the other keys of the event
+	maxContentSize = 60000
+	// maxAttempts is the number of attempts to send a message, all of them use the same transaction id
+	maxAttempts = 3
+	// maxRetryAfter caps the waiting time requested by the homeserver
+	maxRetryAfter = time.Minute
+)
+
+// roomMessage is the content of the m.room.message event
+type roomMessage struct {
+	MsgType       string `json:"msgtype"`
+	Body          string `json:"body"`
+	Format        string `json:"format,omitempty"`
+	FormattedBody string `json:"formatted_body,omitempty"`
+}
+
+// errorResponse is the standard error body of the client-server API
+type errorResponse struct {
+	ErrCode      string `json:"errcode"`
+	Error        string `json:"error"`
+	RetryAfterMs int64  `json:"retry_after_ms"`
+}
+
+// MatrixAnnouncer is the announcer that sends the releases to a Matrix room as m.notice messages
+type MatrixAnnouncer struct {
+	HomeserverUrl string
+	AccessToken   string
+	RoomId        string
+	client        *http.Client
+	sleep         func(time.Duration)
+	txnCounter    atomic.Uint64
+}
+
+// NewMatrixAnnouncer creates a new MatrixAnnouncer which sends the messages with the access token of a bot user
+func NewMatrixAnnouncer(homeserverUrl, accessToken, roomId string) (*MatrixAnnouncer, error) {
+	if homeserverUrl == "" || accessToken == "" || roomId == "" {
+		return nil, errors.New("matrix homeserver url, access token and room id are required")
+	}
+
+	return &MatrixAnnouncer{
+		HomeserverUrl: strings.TrimSuffix(homeserverUrl, "/"),
+		AccessToken:   accessToken,
+		RoomId:        roomId,
+		client:        &http.Client{Timeout: 10 * time.Second},
+		sleep:         time.Sleep,
+	}, nil
+}
+
+// Notify sends the message to the room, routed payloads are sent to each of their recipient rooms instead. Failed
+// requests are retried with the same transaction id, so the homeserver does not duplicate the message.
+func (m *MatrixAnnouncer) Notify(payload *announce.AnnouncerPayload) error {
+	body, err := json.Marshal(buildMessage(payload))
+	if err != nil {
+		return err
+	}
+
+	rooms := payload.Recipients
+	if len(rooms) == 0 {
+		rooms = []string{m.RoomId}
+	}
+
+	var errs []error
+	for _, room := range rooms {
+		if err := m.send(room, m.newTxnId(), body); err != nil {
+			errs = append(errs, fmt.Errorf("room %s: %w", room, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// IsEnabled checks if the MatrixAnnouncer is enabled.
+func (m *MatrixAnnouncer) IsEnabled() bool {
+	return m.HomeserverUrl != "" && m.AccessToken != "" && m.RoomId != ""
+}
+
+// newTxnId creates a transaction id which is unique for the lifetime of the access token
+func (m *MatrixAnnouncer) newTxnId() string {
+	return fmt.Sprintf("rss-feed-filterer.%d.%d", time.Now().UnixNano(), m.txnCounter.Add(1))
+}
+
+func (m *MatrixAnnouncer) send(room, txnId string, body []byte) error {
+	endpoint := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s", m.HomeserverUrl,
+		url.PathEscape(room), url.PathEscape(txnId))
+
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		var retryAfter time.Duration
+		if retryAfter, err = m.put(endpoint, body); err == nil {
+			return nil
+		}
+
+		if retryAfter < 0 || attempt == maxAttempts {
+			break
+		}
+
+		m.sleep(retryAfter)
+	}
+
+	return err
+}
+
+// put sends the request once, the returned duration is non-negative if the request should be retried after that long
+func (m *MatrixAnnouncer) put(endpoint string, body []byte) (time.Duration, error) {
+	req, err := http.NewRequest(http.MethodPut, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return -1, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+m.AccessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return time.Second, fmt.Errorf("an error occurred while calling matrix homeserver: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return 0, nil
+	}
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	var response errorResponse
+	_ = json.Unmarshal(respBody, &response)
+	err = fmt.Errorf("matrix homeserver returned status code %d: %s %s", resp.StatusCode, response.ErrCode, response.Error)
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return min(time.Duration(response.RetryAfterMs)*time.Millisecond, maxRetryAfter), err
+	case resp.StatusCode >= 500:
+		return time.Second, err
+	default:
+		return -1, err
+	}
+}
+
+// buildMessage builds the message with the notes blocks shortened until both of the bodies of it fit in an event
+// together, the escaping of the HTML and the JSON is why the size of the encoded content is measured
+func buildMessage(payload *announce.AnnouncerPayload) *roomMessage {
+	for limit := maxNotesLength; ; limit /= 2 {
+		msg := buildMessageWithLimit(payload, limit)
+		if limit/2 < minNotesLength {
+			return msg
+		}
+
+		if encoded, err := json.Marshal(msg); err == nil && len(encoded) <= maxContentSize {
+			return msg
+		}
+	}
+}
+
+func buildMessageWithLimit(payload *announce.AnnouncerPayload, limit int) *roomMessage {
+	summary := payload.Summary()
+	if payload.IsUrgent() {
+		// @room in the plain body notifies every member of the room
+		summary = "🚨 @room " + summary
+	}
+
+	plain := []string{summary}
+	formatted := []string{"<p>" + html.EscapeString(summary) + "</p>"}
+
+	blocks := []struct {
+		title   string
+		content string
+	}{
+		{"⚠️ Breaking changes", payload.BreakingChanges},
+		{"Release notes", payload.Notes},
+	}
+
+	for _, block := range blocks {
+		if strings.TrimSpace(block.content) == "" {
+			continue
+		}
+
+		text := notes.ToText(block.content)
+		plain = append(plain, block.title+"\n"+notes.Truncate(text, limit))
+
+		content := block.content
+		if len(content) > limit {
+			// cutting the HTML would break the markup, so the long notes are sent as preformatted text
+			content = "<pre>" + html.EscapeString(notes.Truncate(text, limit)) + "</pre>"
+		}
+
+		formatted = append(formatted, "<h4>"+html.EscapeString(block.title)+"</h4>"+content)
+	}
+
+	return &roomMessage{
+		MsgType:       "m.notice",
+		Body:          strings.Join(plain, "\n\n"),
+		Format:        "org.matrix.custom.html",
+		FormattedBody: strings.Join(formatted, "\n"),
+	}
+}
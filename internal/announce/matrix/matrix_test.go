@@ -0,0 +1,164 @@
+//go:build unit
+
+package matrix
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubHomeserver is a minimal client-server API which deduplicates the events by transaction id like a real
+// homeserver, it fails the requests with the given status codes in order
+type stubHomeserver struct {
+	mu       sync.Mutex
+	failures []int
+	requests []string
+	events   map[string]roomMessage
+	rooms    []string
+}
+
+func newStubHomeserver(t *testing.T, failures ...int) (*stubHomeserver, string) {
+	stub := &stubHomeserver{failures: failures, events: map[string]roomMessage{}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stub.mu.Lock()
+		defer stub.mu.Unlock()
+
+		if r.Method != http.MethodPut || r.Header.Get("Authorization") != "Bearer secret-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"errcode":"M_UNKNOWN_TOKEN","error":"Invalid access token"}`))
+			return
+		}
+
+		// /_matrix/client/v3/rooms/{roomId}/send/m.room.message/{txnId}
+		parts := strings.Split(r.URL.Path, "/")
+		room, txnId := parts[5], parts[8]
+		stub.requests = append(stub.requests, txnId)
+
+		var msg roomMessage
+		_ = json.NewDecoder(r.Body).Decode(&msg)
+
+		if _, ok := stub.events[room+"/"+txnId]; !ok {
+			stub.events[room+"/"+txnId] = msg
+			stub.rooms = append(stub.rooms, room)
+		}
+
+		if len(stub.failures) > 0 {
+			status := stub.failures[0]
+			stub.failures = stub.failures[1:]
+			w.WriteHeader(status)
+			if status == http.StatusTooManyRequests {
+				_, _ = w.Write([]byte(`{"errcode":"M_LIMIT_EXCEEDED","error":"Too many requests","retry_after_ms":2000}`))
+			} else {
+				_, _ = w.Write([]byte(`{"errcode":"M_UNKNOWN","error":"Internal server error"}`))
+			}
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"event_id":"$event"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return stub, server.URL
+}
+
+func TestNewMatrixAnnouncer(t *testing.T) {
+	announcer, err := NewMatrixAnnouncer("https://matrix.example.com", "", "!room:example.com")
+	assert.Nil(t, announcer)
+	assert.NotNil(t, err)
+
+	announcer, err = NewMatrixAnnouncer("https://matrix.example.com/", "token", "!room:example.com")
+	assert.Nil(t, err)
+	assert.Equal(t, "https://matrix.example.com", announcer.HomeserverUrl)
+	assert.True(t, announcer.IsEnabled())
+}
+
+func TestMatrixAnnouncer_Notify(t *testing.T) {
+	cases := []struct {
+		caseName         string
+		token            string
+		failures         []int
+		recipients       []string
+		expectedRequests int
+		expectedRooms    []string
+		expectedSleeps   []time.Duration
+		shouldPass       bool
+	}{
+		{"Successful notification", "secret-token", nil, nil, 1, []string{"!releases:example.com"}, nil, true},
+		{"Retries are not duplicated", "secret-token", []int{http.StatusBadGateway, http.StatusTooManyRequests}, nil, 3,
+			[]string{"!releases:example.com"}, []time.Duration{time.Second, 2 * time.Second}, true},
+		{"Gives up after max attempts", "secret-token", []int{500, 500, 500}, nil, maxAttempts,
+			[]string{"!releases:example.com"}, []time.Duration{time.Second, time.Second}, false},
+		{"Routed to other rooms", "secret-token", nil, []string{"!security:example.com", "!oncall:example.com"}, 2,
+			[]string{"!security:example.com", "!oncall:example.com"}, nil, true},
+		{"Invalid token is not retried", "wrong-token", nil, nil, 0, nil, nil, false},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		stub, url := newStubHomeserver(t, tc.failures...)
+		announcer, err := NewMatrixAnnouncer(url, tc.token, "!releases:example.com")
+		assert.Nil(t, err)
+
+		var sleeps []time.Duration
+		announcer.sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+
+		err = announcer.Notify(&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", URL: "https://example.com",
+			Recipients: tc.recipients})
+		if tc.shouldPass {
+			assert.Nil(t, err)
+		} else {
+			assert.NotNil(t, err)
+		}
+
+		assert.Len(t, stub.requests, tc.expectedRequests)
+		assert.Equal(t, tc.expectedRooms, stub.rooms)
+		assert.Equal(t, tc.expectedSleeps, sleeps)
+		for _, msg := range stub.events {
+			assert.Equal(t, "m.notice", msg.MsgType)
+		}
+	}
+}
+
+func TestBuildMessage(t *testing.T) {
+	msg := buildMessage(&announce.AnnouncerPayload{
+		ProjectName:     "x-project",
+		Version:         "v2.0.0",
+		URL:             "https://example.com/?a=1&b=2",
+		Notes:           "<p>Fixes <strong>bug</strong></p>",
+		BreakingChanges: "<ul><li>Removed foo</li></ul>",
+		Priority:        announce.PriorityHigh,
+	})
+
+	assert.Equal(t, "m.notice", msg.MsgType)
+	assert.Equal(t, "org.matrix.custom.html", msg.Format)
+	assert.Equal(t, "🚨 @room x-project v2.0.0 is out! Check it out at https://example.com/?a=1&b=2\n\n"+
+		"⚠️ Breaking changes\n- Removed foo\n\nRelease notes\nFixes bug", msg.Body)
+	assert.Equal(t, "<p>🚨 @room x-project v2.0.0 is out! Check it out at https://example.com/?a=1&amp;b=2</p>\n"+
+		"<h4>⚠️ Breaking changes</h4><ul><li>Removed foo</li></ul>\n"+
+		"<h4>Release notes</h4><p>Fixes <strong>bug</strong></p>", msg.FormattedBody)
+
+	long := buildMessage(&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0",
+		Notes: "<p>" + strings.Repeat("<b>a</b> ", maxNotesLength) + "</p>"})
+	assert.True(t, strings.HasPrefix(long.FormattedBody[strings.Index(long.FormattedBody, "</h4>")+5:], "<pre>a a"))
+	assert.True(t, strings.HasSuffix(long.FormattedBody, "…</pre>"))
+
+	// both of the blocks go into both of the bodies, each escaped as HTML and as JSON
+	huge := buildMessage(&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0",
+		BreakingChanges: strings.Repeat("<p>Removed <code>a < b && c > d</code></p>", 2000),
+		Notes:           strings.Repeat("<p>Fixes \"quotes\" & <em>ampersands</em></p>", 5000)})
+	encoded, err := json.Marshal(huge)
+	assert.Nil(t, err)
+	assert.Less(t, len(encoded), 65536)
+	assert.Contains(t, huge.Body, "Release notes\nFixes")
+	assert.Contains(t, huge.FormattedBody, "<h4>Release notes</h4><pre>Fixes")
+}
@@ -0,0 +1,138 @@
+package mattermost
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/notes"
+)
+
+// maxNotesLength is the maximum length of the release notes attachment
+const maxNotesLength = 4000
+
+// WebhookMessage is the payload of the Mattermost incoming webhooks
+type WebhookMessage struct {
+	Text        string       `json:"text"`
+	Channel     string       `json:"channel,omitempty"`
+	Username    string       `json:"username,omitempty"`
+	IconUrl     string       `json:"icon_url,omitempty"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+// Attachment is the message attachment of Mattermost, it follows the Slack attachment format
+type Attachment struct {
+	Fallback  string `json:"fallback"`
+	Color     string `json:"color,omitempty"`
+	Title     string `json:"title,omitempty"`
+	TitleLink string `json:"title_link,omitempty"`
+	Text      string `json:"text"`
+}
+
+// MattermostAnnouncer is the announcer that posts the releases to a Mattermost incoming webhook
+type MattermostAnnouncer struct {
+	WebhookUrl string
+	Channel    string
+	Username   string
+	IconUrl    string
+	client     *http.Client
+}
+
+// NewMattermostAnnouncer creates a new MattermostAnnouncer, channel, username and iconUrl override the defaults of the
+// webhook if set.
+func NewMattermostAnnouncer(webhookUrl, channel, username, iconUrl string) (*MattermostAnnouncer, error) {
+	if webhookUrl == "" {
+		return nil, errors.New("mattermost webhook url is required")
+	}
+
+	return &MattermostAnnouncer{
+		WebhookUrl: webhookUrl,
+		Channel:    channel,
+		Username:   username,
+		IconUrl:    iconUrl,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Notify posts the message to the channel, routed payloads are posted to each of their recipient channels instead.
+func (m *MattermostAnnouncer) Notify(payload *announce.AnnouncerPayload) error {
+	channels := payload.Recipients
+	if len(channels) == 0 {
+		channels = []string{m.Channel}
+	}
+
+	var errs []error
+	for _, channel := range channels {
+		msg := m.buildMessage(payload)
+		msg.Channel = channel
+		if err := m.post(msg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// IsEnabled checks if the MattermostAnnouncer is enabled.
+func (m *MattermostAnnouncer) IsEnabled() bool {
+	return m.WebhookUrl != ""
+}
+
+func (m *MattermostAnnouncer) post(msg *WebhookMessage) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	resp, err := m.client.Post(m.WebhookUrl, "application/json", bytes.NewReader(body))
+	if err != nil {
+		// the webhook url contains the key, so the error is not wrapped to avoid leaking it to the logs
+		return errors.New("an error occurred while calling mattermost webhook")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("mattermost webhook returned status code %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	return nil
+}
+
+func (m *MattermostAnnouncer) buildMessage(payload *announce.AnnouncerPayload) *WebhookMessage {
+	msg := &WebhookMessage{
+		Text:     payload.Summary(),
+		Username: m.Username,
+		IconUrl:  m.IconUrl,
+	}
+
+	if payload.IsUrgent() {
+		msg.Text = fmt.Sprintf(":rotating_light: @channel %s", msg.Text)
+	}
+
+	if breakingChanges := notes.ToMarkdown(payload.BreakingChanges); breakingChanges != "" {
+		msg.Attachments = append(msg.Attachments, Attachment{
+			Fallback: "Breaking changes",
+			Title:    ":warning: Breaking changes",
+			Color:    "#E74C3C",
+			Text:     notes.Truncate(breakingChanges, maxNotesLength),
+		})
+	}
+
+	if releaseNotes := notes.ToMarkdown(payload.Notes); releaseNotes != "" {
+		msg.Attachments = append(msg.Attachments, Attachment{
+			Fallback:  "Release notes",
+			Title:     "Release notes",
+			TitleLink: payload.URL,
+			Text:      notes.Truncate(releaseNotes, maxNotesLength),
+		})
+	}
+
+	return msg
+}
@@ -0,0 +1,107 @@
+//go:build unit
+
+package mattermost
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/stretchr/testify/assert"
+)
+
+func newFakeWebhook(t *testing.T, status int) (*[]WebhookMessage, string) {
+	var messages []WebhookMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg WebhookMessage
+		_ = json.NewDecoder(r.Body).Decode(&msg)
+		messages = append(messages, msg)
+
+		w.WriteHeader(status)
+		if status != http.StatusOK {
+			_, _ = w.Write([]byte(`{"id":"web.incoming_webhook.disabled.app_error","message":"Incoming webhooks have been disabled"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return &messages, server.URL
+}
+
+func TestNewMattermostAnnouncer(t *testing.T) {
+	announcer, err := NewMattermostAnnouncer("", "", "", "")
+	assert.Nil(t, announcer)
+	assert.NotNil(t, err)
+
+	announcer, err = NewMattermostAnnouncer("https://mattermost.example.com/hooks/x", "releases", "", "")
+	assert.Nil(t, err)
+	assert.True(t, announcer.IsEnabled())
+}
+
+func TestMattermostAnnouncer_Notify(t *testing.T) {
+	cases := []struct {
+		caseName            string
+		status              int
+		payload             *announce.AnnouncerPayload
+		expectedChannels    []string
+		expectedText        string
+		expectedAttachments []string
+		shouldPass          bool
+	}{
+		{
+			"Default channel with notes", http.StatusOK,
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", URL: "https://example.com", Notes: "<p>Fixes <strong>bug</strong></p>"},
+			[]string{"releases"}, "x-project v1.0.0 is out! Check it out at https://example.com", []string{"Release notes"}, true,
+		},
+		{
+			"Urgent payload routed to other channels", http.StatusOK,
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v2.0.0", URL: "https://example.com", BreakingChanges: "<p>Removed foo</p>",
+				Priority: announce.PriorityHigh, Recipients: []string{"security", "oncall"}},
+			[]string{"security", "oncall"}, ":rotating_light: @channel x-project v2.0.0 is out! Check it out at https://example.com",
+			[]string{":warning: Breaking changes"}, true,
+		},
+		{
+			"Failed delivery", http.StatusForbidden,
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", URL: "https://example.com"},
+			[]string{"releases"}, "x-project v1.0.0 is out! Check it out at https://example.com", nil, false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		messages, url := newFakeWebhook(t, tc.status)
+		announcer, err := NewMattermostAnnouncer(url, "releases", "rss-feed-filterer", "https://example.com/icon.png")
+		assert.Nil(t, err)
+
+		err = announcer.Notify(tc.payload)
+		if tc.shouldPass {
+			assert.Nil(t, err)
+		} else {
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), "disabled")
+		}
+
+		assert.Len(t, *messages, len(tc.expectedChannels))
+		for i, msg := range *messages {
+			assert.Equal(t, tc.expectedChannels[i], msg.Channel)
+			assert.Equal(t, tc.expectedText, msg.Text)
+			assert.Equal(t, "rss-feed-filterer", msg.Username)
+			assert.Equal(t, "https://example.com/icon.png", msg.IconUrl)
+
+			var titles []string
+			for _, attachment := range msg.Attachments {
+				titles = append(titles, attachment.Title)
+			}
+
+			assert.Equal(t, tc.expectedAttachments, titles)
+		}
+	}
+
+	messages, url := newFakeWebhook(t, http.StatusOK)
+	announcer, _ := NewMattermostAnnouncer(url, "", "", "")
+	assert.Nil(t, announcer.Notify(&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", Notes: "<p>Fixes <strong>bug</strong></p>"}))
+	assert.Equal(t, "", (*messages)[0].Channel)
+	assert.Equal(t, "Fixes **bug**", (*messages)[0].Attachments[0].Text)
+}
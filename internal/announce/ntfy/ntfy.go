@@ -0,0 +1,152 @@
+package ntfy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/notes"
+)
+
+const (
+	defaultServerUrl = "https://ntfy.sh"
+	defaultPriority  = 3
+	maxPriority      = 5
+	// maxMessageSize is the limit of the message body of ntfy in bytes, longer messages are truncated
+	maxMessageSize = 4096
+)
+
+type message struct {
+	Topic    string   `json:"topic"`
+	Title    string   `json:"title"`
+	Message  string   `json:"message"`
+	Priority int      `json:"priority"`
+	Tags     []string `json:"tags,omitempty"`
+	Click    string   `json:"click,omitempty"`
+	Markdown bool     `json:"markdown"`
+}
+
+// NtfyAnnouncer is the announcer that publishes the releases to ntfy topics
+type NtfyAnnouncer struct {
+	ServerUrl string
+	Topic     string
+	Token     string
+	Priority  int
+	Tags      []string
+	client    *http.Client
+}
+
+// NewNtfyAnnouncer creates a new NtfyAnnouncer. serverUrl defaults to https://ntfy.sh, token is the access token for
+// the protected topics and priority is between 1 and 5, defaults to 3. Urgent releases are sent with priority 5.
+func NewNtfyAnnouncer(serverUrl, topic, token string, priority int, tags []string) (*NtfyAnnouncer, error) {
+	if topic == "" {
+		return nil, errors.New("ntfy topic is required")
+	}
+
+	if serverUrl == "" {
+		serverUrl = defaultServerUrl
+	}
+
+	if priority == 0 {
+		priority = defaultPriority
+	}
+
+	if priority < 1 || priority > maxPriority {
+		return nil, fmt.Errorf("ntfy priority must be between 1 and %d", maxPriority)
+	}
+
+	return &NtfyAnnouncer{
+		ServerUrl: strings.TrimSuffix(serverUrl, "/"),
+		Topic:     topic,
+		Token:     token,
+		Priority:  priority,
+		Tags:      tags,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Notify publishes the message to the topic, routed payloads are published to each of their recipient topics instead.
+func (n *NtfyAnnouncer) Notify(payload *announce.AnnouncerPayload) error {
+	topics := payload.Recipients
+	if len(topics) == 0 {
+		topics = []string{n.Topic}
+	}
+
+	var errs []error
+	for _, topic := range topics {
+		msg := n.buildMessage(payload)
+		msg.Topic = topic
+		if err := n.publish(msg); err != nil {
+			errs = append(errs, fmt.Errorf("topic %s: %w", topic, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// IsEnabled checks if the NtfyAnnouncer is enabled.
+func (n *NtfyAnnouncer) IsEnabled() bool {
+	return n.Topic != ""
+}
+
+func (n *NtfyAnnouncer) publish(msg *message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, n.ServerUrl, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if n.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.Token)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("an error occurred while calling ntfy server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("ntfy server returned status code %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	return nil
+}
+
+func (n *NtfyAnnouncer) buildMessage(payload *announce.AnnouncerPayload) *message {
+	msg := &message{
+		Title:    payload.Title(fmt.Sprintf("%s %s", payload.ProjectName, payload.Version)),
+		Message:  payload.Summary(),
+		Priority: n.Priority,
+		Tags:     append([]string{}, n.Tags...),
+		Click:    payload.URL,
+		Markdown: true,
+	}
+
+	if payload.IsUrgent() {
+		msg.Priority = maxPriority
+		msg.Tags = append(msg.Tags, "rotating_light")
+	}
+
+	if breakingChanges := notes.ToMarkdown(payload.BreakingChanges); breakingChanges != "" {
+		msg.Tags = append(msg.Tags, "warning")
+		msg.Message += "\n\n**Breaking changes**\n\n" + breakingChanges
+	}
+
+	// ntfy turns the longer messages into attachments, which are not rendered as markdown
+	msg.Message = notes.TruncateBytes(msg.Message, maxMessageSize)
+
+	return msg
+}
@@ -0,0 +1,147 @@
+//go:build unit
+
+package ntfy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/stretchr/testify/assert"
+)
+
+func newFakeServer(t *testing.T) (*[]message, *[]string, string) {
+	var messages []message
+	var auths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg message
+		_ = json.NewDecoder(r.Body).Decode(&msg)
+		messages = append(messages, msg)
+		auths = append(auths, r.Header.Get("Authorization"))
+
+		if msg.Topic == "forbidden" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"code":40301,"http":403,"error":"forbidden"}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"id":"x","event":"message"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return &messages, &auths, server.URL
+}
+
+func TestNewNtfyAnnouncer(t *testing.T) {
+	cases := []struct {
+		caseName         string
+		topic            string
+		priority         int
+		expectedPriority int
+		shouldPass       bool
+	}{
+		{"Defaults", "releases", 0, defaultPriority, true},
+		{"Custom priority", "releases", 4, 4, true},
+		{"Missing topic", "", 0, 0, false},
+		{"Invalid priority", "releases", 6, 0, false},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		announcer, err := NewNtfyAnnouncer("", tc.topic, "", tc.priority, nil)
+		if !tc.shouldPass {
+			assert.NotNil(t, err)
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.Equal(t, defaultServerUrl, announcer.ServerUrl)
+		assert.Equal(t, tc.expectedPriority, announcer.Priority)
+		assert.True(t, announcer.IsEnabled())
+	}
+}
+
+func TestNtfyAnnouncer_Notify(t *testing.T) {
+	cases := []struct {
+		caseName         string
+		token            string
+		payload          *announce.AnnouncerPayload
+		expectedTopics   []string
+		expectedPriority int
+		expectedTags     []string
+		expectedMessage  string
+		shouldPass       bool
+	}{
+		{
+			"Default topic", "",
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", URL: "https://example.com"},
+			[]string{"releases"}, 3, []string{"package"}, "x-project v1.0.0 is out! Check it out at https://example.com", true,
+		},
+		{
+			"Urgent breaking release routed to other topics", "tk_token",
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v2.0.0", URL: "https://example.com",
+				BreakingChanges: "<p>Removed <strong>foo</strong></p>", Priority: announce.PriorityHigh, Recipients: []string{"alice", "bob"}},
+			[]string{"alice", "bob"}, 5, []string{"package", "rotating_light", "warning"},
+			"x-project v2.0.0 is out! Check it out at https://example.com\n\n**Breaking changes**\n\nRemoved **foo**", true,
+		},
+		{
+			"Forbidden topic", "",
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", URL: "https://example.com", Recipients: []string{"forbidden"}},
+			[]string{"forbidden"}, 3, []string{"package"}, "x-project v1.0.0 is out! Check it out at https://example.com", false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		messages, auths, url := newFakeServer(t)
+		announcer, err := NewNtfyAnnouncer(url, "releases", tc.token, 0, []string{"package"})
+		assert.Nil(t, err)
+
+		err = announcer.Notify(tc.payload)
+		if tc.shouldPass {
+			assert.Nil(t, err)
+		} else {
+			assert.NotNil(t, err)
+		}
+
+		assert.Len(t, *messages, len(tc.expectedTopics))
+		for i, msg := range *messages {
+			assert.Equal(t, tc.expectedTopics[i], msg.Topic)
+			assert.Equal(t, tc.payload.ProjectName+" "+tc.payload.Version, msg.Title)
+			assert.Equal(t, tc.expectedMessage, msg.Message)
+			assert.Equal(t, tc.expectedPriority, msg.Priority)
+			assert.Equal(t, tc.expectedTags, msg.Tags)
+			assert.Equal(t, "https://example.com", msg.Click)
+			assert.True(t, msg.Markdown)
+			if tc.token != "" {
+				assert.Equal(t, "Bearer "+tc.token, (*auths)[i])
+			} else {
+				assert.Equal(t, "", (*auths)[i])
+			}
+		}
+	}
+}
+
+func TestNtfyAnnouncer_Notify_longMessage(t *testing.T) {
+	messages, _, url := newFakeServer(t)
+	announcer, err := NewNtfyAnnouncer(url, "releases", "", 0, nil)
+	assert.Nil(t, err)
+
+	// the digest summary and the breaking changes are counted in bytes together
+	assert.Nil(t, announcer.Notify(&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v2.0.0",
+		URL: "https://example.com", Message: strings.Repeat("ü", 1500),
+		BreakingChanges: "<p>" + strings.Repeat("Removed föö. ", 300) + "</p>"}))
+
+	assert.Len(t, *messages, 1)
+	msg := (*messages)[0].Message
+	assert.LessOrEqual(t, len(msg), maxMessageSize)
+	assert.True(t, utf8.ValidString(msg))
+	assert.True(t, strings.HasPrefix(msg, strings.Repeat("ü", 1500)+"\n\n**Breaking changes**"))
+	assert.True(t, strings.HasSuffix(msg, "…"))
+}
@@ -0,0 +1,205 @@
+package opsgenie
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/notes"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/types"
+)
+
+const (
+	defaultApiUrl = "https://api.opsgenie.com"
+	source        = "rss-feed-filterer"
+
+	// limits of the alert fields of the Alert API
+	maxMessageLength     = 130
+	maxDescriptionLength = 15000
+)
+
+// defaultPriorities maps the change levels to the alert priorities if they are not configured
+var defaultPriorities = map[string]string{
+	announce.LevelSecurity: "P1",
+	announce.LevelBreaking: "P2",
+	announce.LevelHigh:     "P3",
+	announce.LevelNormal:   "P5",
+}
+
+// createAlertRequest is the request body of the create alert API
+type createAlertRequest struct {
+	Message     string            `json:"message"`
+	Alias       string            `json:"alias"`
+	Description string            `json:"description,omitempty"`
+	Responders  []responder       `json:"responders,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Details     map[string]string `json:"details,omitempty"`
+	Entity      string            `json:"entity"`
+	Source      string            `json:"source"`
+	Priority    string            `json:"priority"`
+}
+
+type responder struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// closeAlertRequest is the request body of the close alert API
+type closeAlertRequest struct {
+	Source string `json:"source"`
+	Note   string `json:"note"`
+}
+
+// errorResponse is the error body of the Alert API
+type errorResponse struct {
+	Message string `json:"message"`
+}
+
+// OpsgenieAnnouncer is the announcer that creates Opsgenie alerts over the Alert API
+type OpsgenieAnnouncer struct {
+	ApiUrl string
+	ApiKey string
+	// Priorities maps the change levels of announce package to the alert priorities
+	Priorities map[string]string
+	Tags       []string
+	Teams      []string
+	client     *http.Client
+}
+
+// NewOpsgenieAnnouncer creates a new OpsgenieAnnouncer. apiUrl defaults to the US instance, https://api.eu.opsgenie.com
+// should be used for the EU instance. priorities overrides the default mapping of the change levels which is P1 for
+// security, P2 for breaking, P3 for high priority and P5 for the other releases. teams are the default responders.
+func NewOpsgenieAnnouncer(apiUrl, apiKey string, priorities map[string]string, tags, teams []string) (*OpsgenieAnnouncer, error) {
+	if apiKey == "" {
+		return nil, errors.New("opsgenie api key is required")
+	}
+
+	if err := announce.ValidateLevels(priorities); err != nil {
+		return nil, fmt.Errorf("invalid opsgenie priorities: %w", err)
+	}
+
+	mapping := make(map[string]string, len(defaultPriorities))
+	for level, priority := range defaultPriorities {
+		mapping[level] = priority
+	}
+
+	for level, priority := range priorities {
+		switch priority = strings.ToUpper(priority); priority {
+		case "P1", "P2", "P3", "P4", "P5":
+			mapping[level] = priority
+		default:
+			return nil, fmt.Errorf("unknown opsgenie priority %q", priority)
+		}
+	}
+
+	if apiUrl == "" {
+		apiUrl = defaultApiUrl
+	}
+
+	return &OpsgenieAnnouncer{
+		ApiUrl:     strings.TrimSuffix(apiUrl, "/"),
+		ApiKey:     apiKey,
+		Priorities: mapping,
+		Tags:       tags,
+		Teams:      teams,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Notify creates the alert of the release, routed payloads override the responder teams with the recipients. The
+// alias is derived from the project and the version, so the updates of a release are deduplicated into its open alert
+// and the removed releases close it.
+func (o *OpsgenieAnnouncer) Notify(payload *announce.AnnouncerPayload) error {
+	if payload.GetEvent() == types.EventRemoved {
+		return o.post(fmt.Sprintf("/v2/alerts/%s/close?identifierType=alias", url.PathEscape(payload.DedupKey())),
+			&closeAlertRequest{Source: source, Note: payload.Summary()})
+	}
+
+	return o.post("/v2/alerts", o.buildAlert(payload))
+}
+
+// IsEnabled checks if the OpsgenieAnnouncer is enabled.
+func (o *OpsgenieAnnouncer) IsEnabled() bool {
+	return o.ApiKey != ""
+}
+
+func (o *OpsgenieAnnouncer) buildAlert(payload *announce.AnnouncerPayload) *createAlertRequest {
+	alert := &createAlertRequest{
+		Message:  notes.Truncate(fmt.Sprintf("%s %s is released", payload.ProjectName, payload.Version), maxMessageLength),
+		Alias:    payload.DedupKey(),
+		Tags:     append(append([]string{}, o.Tags...), payload.Level()),
+		Entity:   payload.ProjectName,
+		Source:   source,
+		Priority: o.Priorities[payload.Level()],
+		Details: map[string]string{
+			"project": payload.ProjectName,
+			"version": payload.Version,
+			"event":   string(payload.GetEvent()),
+			"url":     payload.URL,
+		},
+	}
+
+	if advisories := payload.Advisories(); len(advisories) > 0 {
+		alert.Details["advisories"] = strings.Join(advisories, ", ")
+	}
+
+	teams := payload.Recipients
+	if len(teams) == 0 {
+		teams = o.Teams
+	}
+
+	for _, team := range teams {
+		alert.Responders = append(alert.Responders, responder{Name: team, Type: "team"})
+	}
+
+	description := payload.Summary()
+	if breakingChanges := notes.ToText(payload.BreakingChanges); breakingChanges != "" {
+		description += "\n\nBreaking changes\n\n" + breakingChanges
+	}
+
+	if releaseNotes := notes.ToText(payload.Notes); releaseNotes != "" {
+		description += "\n\nRelease notes\n\n" + releaseNotes
+	}
+
+	alert.Description = notes.Truncate(description, maxDescriptionLength)
+
+	return alert
+}
+
+func (o *OpsgenieAnnouncer) post(path string, request any) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, o.ApiUrl+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "GenieKey "+o.ApiKey)
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("an error occurred while calling opsgenie api: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// the requests are processed asynchronously, so the api returns 202 for the accepted requests
+	if resp.StatusCode != http.StatusAccepted {
+		var response errorResponse
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		_ = json.Unmarshal(respBody, &response)
+		return fmt.Errorf("opsgenie api returned status code %d: %s", resp.StatusCode, response.Message)
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+//go:build unit
+
+package opsgenie
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeAlertApi struct {
+	uris   []string
+	alerts []createAlertRequest
+}
+
+func newFakeAlertApi(t *testing.T) (*fakeAlertApi, string) {
+	api := &fakeAlertApi{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "GenieKey api-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"message":"Could not authenticate","took":0.0,"requestId":"1"}`))
+			return
+		}
+
+		var alert createAlertRequest
+		_ = json.NewDecoder(r.Body).Decode(&alert)
+		api.uris = append(api.uris, r.URL.RequestURI())
+		api.alerts = append(api.alerts, alert)
+
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"result":"Request will be processed","took":0.1,"requestId":"1"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return api, server.URL
+}
+
+func TestNewOpsgenieAnnouncer(t *testing.T) {
+	cases := []struct {
+		caseName         string
+		apiKey           string
+		priorities       map[string]string
+		expectedBreaking string
+		shouldPass       bool
+	}{
+		{"Defaults", "api-key", nil, "P2", true},
+		{"Overridden priority", "api-key", map[string]string{announce.LevelBreaking: "p1"}, "P1", true},
+		{"Missing api key", "", nil, "", false},
+		{"Unknown level", "api-key", map[string]string{"critical": "P1"}, "", false},
+		{"Unknown priority", "api-key", map[string]string{announce.LevelNormal: "P6"}, "", false},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		announcer, err := NewOpsgenieAnnouncer("https://api.eu.opsgenie.com/", tc.apiKey, tc.priorities, nil, nil)
+		if !tc.shouldPass {
+			assert.NotNil(t, err)
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.Equal(t, "https://api.eu.opsgenie.com", announcer.ApiUrl)
+		assert.Equal(t, tc.expectedBreaking, announcer.Priorities[announce.LevelBreaking])
+		assert.Equal(t, "P1", announcer.Priorities[announce.LevelSecurity])
+		assert.True(t, announcer.IsEnabled())
+	}
+}
+
+func TestOpsgenieAnnouncer_Notify(t *testing.T) {
+	cases := []struct {
+		caseName           string
+		apiKey             string
+		payload            *announce.AnnouncerPayload
+		expectedUri        string
+		expectedPriority   string
+		expectedResponders []responder
+		shouldPass         bool
+	}{
+		{
+			"Security release creates a P1 alert", "api-key",
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.1", URL: "https://example.com",
+				Notes: "<p>Fixes a vulnerability</p>", Security: &types.Security{CVEs: []string{"CVE-2023-1234"}}},
+			"/v2/alerts", "P1", []responder{{Name: "platform", Type: "team"}}, true,
+		},
+		{
+			"Routed release overrides the teams", "api-key",
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.1", URL: "https://example.com",
+				Priority: announce.PriorityHigh, Recipients: []string{"backend"}},
+			"/v2/alerts", "P3", []responder{{Name: "backend", Type: "team"}}, true,
+		},
+		{
+			"Removed release closes the alert", "api-key",
+			&announce.AnnouncerPayload{ProjectName: "user1/x-project", Version: "v1.0.1", URL: "https://example.com",
+				Event: types.EventRemoved},
+			"/v2/alerts/user1%2Fx-project@v1.0.1/close?identifierType=alias", "", nil, true,
+		},
+		{
+			"Invalid api key", "wrong-key",
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.1", URL: "https://example.com"},
+			"", "", nil, false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		api, url := newFakeAlertApi(t)
+		announcer, err := NewOpsgenieAnnouncer(url, tc.apiKey, nil, []string{"releases"}, []string{"platform"})
+		assert.Nil(t, err)
+
+		err = announcer.Notify(tc.payload)
+		if !tc.shouldPass {
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), "Could not authenticate")
+			assert.Empty(t, api.alerts)
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{tc.expectedUri}, api.uris)
+		if tc.payload.GetEvent() == types.EventRemoved {
+			continue
+		}
+
+		alert := api.alerts[0]
+		assert.Equal(t, "x-project@v1.0.1", alert.Alias)
+		assert.Equal(t, "x-project v1.0.1 is released", alert.Message)
+		assert.Equal(t, tc.expectedPriority, alert.Priority)
+		assert.Equal(t, tc.expectedResponders, alert.Responders)
+		assert.Equal(t, []string{"releases", tc.payload.Level()}, alert.Tags)
+		assert.Equal(t, "x-project", alert.Entity)
+		assert.Contains(t, alert.Description, tc.payload.Summary())
+	}
+}
@@ -0,0 +1,231 @@
+package pagerduty
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/notes"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/types"
+)
+
+// severities of the PagerDuty events
+const (
+	SeverityCritical = "critical"
+	SeverityError    = "error"
+	SeverityWarning  = "warning"
+	SeverityInfo     = "info"
+)
+
+const (
+	defaultApiUrl = "https://events.pagerduty.com/v2/enqueue"
+	defaultSource = "rss-feed-filterer"
+
+	actionTrigger = "trigger"
+	actionResolve = "resolve"
+
+	// maxSummaryLength is the limit of the summary field of the Events API
+	maxSummaryLength = 1024
+	// maxDetailLength keeps the custom details well below the 512 KB limit of an event
+	maxDetailLength = 10000
+)
+
+// defaultSeverities maps the change levels to the severities if they are not configured
+var defaultSeverities = map[string]string{
+	announce.LevelSecurity: SeverityCritical,
+	announce.LevelBreaking: SeverityError,
+	announce.LevelHigh:     SeverityWarning,
+	announce.LevelNormal:   SeverityInfo,
+}
+
+// event is the request body of the Events API v2
+type event struct {
+	RoutingKey  string        `json:"routing_key"`
+	EventAction string        `json:"event_action"`
+	DedupKey    string        `json:"dedup_key"`
+	Payload     *eventPayload `json:"payload,omitempty"`
+	Links       []link        `json:"links,omitempty"`
+}
+
+type eventPayload struct {
+	Summary       string         `json:"summary"`
+	Source        string         `json:"source"`
+	Severity      string         `json:"severity"`
+	Timestamp     string         `json:"timestamp,omitempty"`
+	Component     string         `json:"component"`
+	Class         string         `json:"class"`
+	CustomDetails map[string]any `json:"custom_details"`
+}
+
+type link struct {
+	Href string `json:"href"`
+	Text string `json:"text"`
+}
+
+// eventResponse is the response body of the Events API v2
+type eventResponse struct {
+	Status  string   `json:"status"`
+	Message string   `json:"message"`
+	Errors  []string `json:"errors"`
+}
+
+// PagerDutyAnnouncer is the announcer that triggers PagerDuty alerts over the Events API v2
+type PagerDutyAnnouncer struct {
+	ApiUrl     string
+	RoutingKey string
+	Source     string
+	// Severities maps the change levels of announce package to the PagerDuty severities
+	Severities map[string]string
+	client     *http.Client
+}
+
+// NewPagerDutyAnnouncer creates a new PagerDutyAnnouncer. apiUrl defaults to the public Events API and source to
+// rss-feed-filterer. severities overrides the default mapping of the change levels which is critical for security,
+// error for breaking, warning for high priority and info for the other releases.
+func NewPagerDutyAnnouncer(apiUrl, routingKey, source string, severities map[string]string) (*PagerDutyAnnouncer, error) {
+	if routingKey == "" {
+		return nil, errors.New("pagerduty routing key is required")
+	}
+
+	if err := announce.ValidateLevels(severities); err != nil {
+		return nil, fmt.Errorf("invalid pagerduty severities: %w", err)
+	}
+
+	mapping := make(map[string]string, len(defaultSeverities))
+	for level, severity := range defaultSeverities {
+		mapping[level] = severity
+	}
+
+	for level, severity := range severities {
+		switch severity {
+		case SeverityCritical, SeverityError, SeverityWarning, SeverityInfo:
+			mapping[level] = severity
+		default:
+			return nil, fmt.Errorf("unknown pagerduty severity %q", severity)
+		}
+	}
+
+	if apiUrl == "" {
+		apiUrl = defaultApiUrl
+	}
+
+	if source == "" {
+		source = defaultSource
+	}
+
+	return &PagerDutyAnnouncer{
+		ApiUrl:     apiUrl,
+		RoutingKey: routingKey,
+		Source:     source,
+		Severities: mapping,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Notify sends the event to the routing key, routed payloads override it with the routing keys in the recipients.
+// The dedup key is derived from the project and the version, so the updates of a release are merged into its alert
+// and the removed releases resolve it.
+func (p *PagerDutyAnnouncer) Notify(payload *announce.AnnouncerPayload) error {
+	routingKeys := payload.Recipients
+	if len(routingKeys) == 0 {
+		routingKeys = []string{p.RoutingKey}
+	}
+
+	var errs []error
+	for _, routingKey := range routingKeys {
+		if err := p.send(p.buildEvent(routingKey, payload)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// IsEnabled checks if the PagerDutyAnnouncer is enabled.
+func (p *PagerDutyAnnouncer) IsEnabled() bool {
+	return p.RoutingKey != ""
+}
+
+func (p *PagerDutyAnnouncer) buildEvent(routingKey string, payload *announce.AnnouncerPayload) *event {
+	e := &event{
+		RoutingKey:  routingKey,
+		EventAction: actionTrigger,
+		DedupKey:    payload.DedupKey(),
+	}
+
+	if payload.GetEvent() == types.EventRemoved {
+		e.EventAction = actionResolve
+		return e
+	}
+
+	details := map[string]any{
+		"project": payload.ProjectName,
+		"version": payload.Version,
+		"event":   payload.GetEvent(),
+	}
+
+	if len(payload.Changes) > 0 {
+		details["changes"] = payload.Changes
+	}
+
+	if advisories := payload.Advisories(); len(advisories) > 0 {
+		details["advisories"] = advisories
+	}
+
+	if breakingChanges := notes.ToText(payload.BreakingChanges); breakingChanges != "" {
+		details["breaking_changes"] = notes.Truncate(breakingChanges, maxDetailLength)
+	}
+
+	if releaseNotes := notes.ToText(payload.Notes); releaseNotes != "" {
+		details["release_notes"] = notes.Truncate(releaseNotes, maxDetailLength)
+	}
+
+	e.Payload = &eventPayload{
+		Summary:       notes.Truncate(payload.Summary(), maxSummaryLength),
+		Source:        p.Source,
+		Severity:      p.Severities[payload.Level()],
+		Component:     payload.ProjectName,
+		Class:         payload.Level(),
+		CustomDetails: details,
+	}
+
+	if payload.PublishedAt != nil {
+		e.Payload.Timestamp = payload.PublishedAt.UTC().Format(time.RFC3339)
+	}
+
+	if payload.URL != "" {
+		e.Links = []link{{Href: payload.URL, Text: fmt.Sprintf("%s %s", payload.ProjectName, payload.Version)}}
+	}
+
+	return e
+}
+
+func (p *PagerDutyAnnouncer) send(e *event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.client.Post(p.ApiUrl, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("an error occurred while calling pagerduty events api: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		var response eventResponse
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		_ = json.Unmarshal(respBody, &response)
+
+		return fmt.Errorf("pagerduty events api returned status code %d: %s", resp.StatusCode,
+			strings.Join(append([]string{response.Message}, response.Errors...), ", "))
+	}
+
+	return nil
+}
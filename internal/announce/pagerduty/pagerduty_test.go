@@ -0,0 +1,164 @@
+//go:build unit
+
+package pagerduty
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func newFakeEventsApi(t *testing.T) (*[]event, string) {
+	var events []event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e event
+		_ = json.NewDecoder(r.Body).Decode(&e)
+		events = append(events, e)
+
+		if e.RoutingKey == "invalid" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"status":"invalid event","message":"Event object is invalid","errors":["Length of 'routing_key' is incorrect (should be 32 characters)"]}`))
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"status":"success","message":"Event processed","dedup_key":"` + e.DedupKey + `"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return &events, server.URL
+}
+
+func TestNewPagerDutyAnnouncer(t *testing.T) {
+	cases := []struct {
+		caseName         string
+		routingKey       string
+		severities       map[string]string
+		expectedSecurity string
+		expectedNormal   string
+		shouldPass       bool
+	}{
+		{"Defaults", "routing-key", nil, SeverityCritical, SeverityInfo, true},
+		{"Overridden severity", "routing-key", map[string]string{announce.LevelNormal: SeverityWarning}, SeverityCritical, SeverityWarning, true},
+		{"Missing routing key", "", nil, "", "", false},
+		{"Unknown level", "routing-key", map[string]string{"urgent": SeverityError}, "", "", false},
+		{"Unknown severity", "routing-key", map[string]string{announce.LevelHigh: "high"}, "", "", false},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		announcer, err := NewPagerDutyAnnouncer("", tc.routingKey, "", tc.severities)
+		if !tc.shouldPass {
+			assert.NotNil(t, err)
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.Equal(t, defaultApiUrl, announcer.ApiUrl)
+		assert.Equal(t, defaultSource, announcer.Source)
+		assert.Equal(t, tc.expectedSecurity, announcer.Severities[announce.LevelSecurity])
+		assert.Equal(t, tc.expectedNormal, announcer.Severities[announce.LevelNormal])
+		assert.True(t, announcer.IsEnabled())
+	}
+}
+
+func TestPagerDutyAnnouncer_Notify(t *testing.T) {
+	publishedAt := time.Date(2023, 10, 1, 12, 0, 0, 0, time.FixedZone("UTC+3", 3*60*60))
+
+	cases := []struct {
+		caseName            string
+		payload             *announce.AnnouncerPayload
+		expectedRoutingKeys []string
+		expectedAction      string
+		expectedSeverity    string
+		shouldPass          bool
+	}{
+		{
+			"Security release triggers a critical alert",
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.1", URL: "https://example.com",
+				PublishedAt: &publishedAt, Notes: "<p>Fixes a vulnerability</p>",
+				Security: &types.Security{CVEs: []string{"CVE-2023-1234"}}},
+			[]string{"routing-key"}, actionTrigger, SeverityCritical, true,
+		},
+		{
+			"Breaking release is routed to another service",
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.1", URL: "https://example.com",
+				BreakingChanges: "<ul><li>Removed foo</li></ul>", Recipients: []string{"platform-key", "backend-key"}},
+			[]string{"platform-key", "backend-key"}, actionTrigger, SeverityError, true,
+		},
+		{
+			"Removed release resolves the alert",
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.1", URL: "https://example.com",
+				Event: types.EventRemoved},
+			[]string{"routing-key"}, actionResolve, "", true,
+		},
+		{
+			"Rejected event",
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.1", URL: "https://example.com",
+				Recipients: []string{"invalid", "routing-key"}},
+			[]string{"invalid", "routing-key"}, actionTrigger, SeverityInfo, false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		events, url := newFakeEventsApi(t)
+		announcer, err := NewPagerDutyAnnouncer(url, "routing-key", "", nil)
+		assert.Nil(t, err)
+
+		err = announcer.Notify(tc.payload)
+		if tc.shouldPass {
+			assert.Nil(t, err)
+		} else {
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), "routing_key")
+		}
+
+		assert.Len(t, *events, len(tc.expectedRoutingKeys))
+		for i, e := range *events {
+			assert.Equal(t, tc.expectedRoutingKeys[i], e.RoutingKey)
+			assert.Equal(t, tc.expectedAction, e.EventAction)
+			assert.Equal(t, "x-project@v1.0.1", e.DedupKey)
+
+			if tc.expectedAction == actionResolve {
+				assert.Nil(t, e.Payload)
+				continue
+			}
+
+			assert.Equal(t, tc.expectedSeverity, e.Payload.Severity)
+			assert.Equal(t, tc.payload.Summary(), e.Payload.Summary)
+			assert.Equal(t, defaultSource, e.Payload.Source)
+			assert.Equal(t, "x-project", e.Payload.Component)
+			assert.Equal(t, []link{{Href: "https://example.com", Text: "x-project v1.0.1"}}, e.Links)
+		}
+	}
+}
+
+func TestPagerDutyAnnouncer_buildEvent(t *testing.T) {
+	announcer, err := NewPagerDutyAnnouncer("", "routing-key", "releases", nil)
+	assert.Nil(t, err)
+
+	publishedAt := time.Date(2023, 10, 1, 12, 0, 0, 0, time.FixedZone("UTC+3", 3*60*60))
+	e := announcer.buildEvent("routing-key", &announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.1",
+		PublishedAt: &publishedAt, Event: types.EventUpdated, Changes: []string{"url"},
+		Notes: "<p>Fixes <b>bar</b></p>", BreakingChanges: "<ul><li>Removed foo</li></ul>",
+		Security: &types.Security{CVEs: []string{"CVE-2023-1234"}, GHSAs: []string{"GHSA-xxxx-xxxx-xxxx"}}})
+
+	assert.Equal(t, "releases", e.Payload.Source)
+	assert.Equal(t, "2023-10-01T09:00:00Z", e.Payload.Timestamp)
+	assert.Equal(t, announce.LevelSecurity, e.Payload.Class)
+	assert.Empty(t, e.Links)
+	assert.Equal(t, types.EventUpdated, e.Payload.CustomDetails["event"])
+	assert.Equal(t, []string{"url"}, e.Payload.CustomDetails["changes"])
+	assert.Equal(t, []string{"CVE-2023-1234", "GHSA-xxxx-xxxx-xxxx"}, e.Payload.CustomDetails["advisories"])
+	assert.Contains(t, e.Payload.CustomDetails["breaking_changes"], "Removed foo")
+	assert.Contains(t, e.Payload.CustomDetails["release_notes"], "Fixes bar")
+}
@@ -0,0 +1,197 @@
+package announce
+
+import (
+	"fmt"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/types"
+)
+
+// Priority is the urgency of a payload, announcers may highlight the payloads with high priority
+type Priority string
+
+const (
+	PriorityNormal Priority = "normal"
+	PriorityHigh   Priority = "high"
+)
+
+// Policy decides which payloads are delivered to an announcer and how
+type Policy struct {
+	// Name is the name of the announcer, the repositories restricted to a set of announcers reference it
+	Name string
+	// Events are the event types to be announced, only new releases are announced if it is empty
+	Events []types.EventType
+	// SecurityOnly restricts the announcer to the security related releases
+	SecurityOnly bool
+	// HasBreakingChanges restricts the announcer to the releases with breaking changes
+	HasBreakingChanges bool
+	// Repositories restricts the announcer to the releases of the given repositories, all repositories are allowed
+	// if it is empty
+	Repositories []string
+	// Tags restricts the announcer to the releases of the repositories with any of the given tags, the releases of
+	// Repositories are also allowed if both are set
+	Tags []string
+	// Routes override the delivery of the matching payloads, first matching route wins
+	Routes []Route
+}
+
+// Route overrides the delivery of the payloads matching its conditions, a route without conditions matches all
+type Route struct {
+	// Security matches only the security related releases
+	Security bool
+	// HasBreakingChanges matches only the releases with breaking changes
+	HasBreakingChanges bool
+	// Repositories matches only the releases of the given repositories if it is not empty
+	Repositories []string
+	// Priority is the urgency of the matching payloads
+	Priority Priority
+	// Recipients override the destination of the matching payloads, like Slack channels or email addresses
+	Recipients []string
+	// Immediate sends the matching payloads right away instead of adding them to the digest of the announcer
+	Immediate bool
+}
+
+// ParseEvents converts the event type names into event types, it fails on unknown event types
+func ParseEvents(events []string) ([]types.EventType, error) {
+	var result []types.EventType
+	for _, event := range events {
+		switch eventType := types.EventType(event); eventType {
+		case types.EventNew, types.EventUpdated, types.EventRemoved:
+			result = append(result, eventType)
+		default:
+			return nil, fmt.Errorf("unknown event type %q", event)
+		}
+	}
+
+	return result, nil
+}
+
+// ParsePriority converts the priority name into a Priority, defaults to PriorityNormal
+func ParsePriority(priority string) (Priority, error) {
+	switch p := Priority(priority); p {
+	case "":
+		return PriorityNormal, nil
+	case PriorityNormal, PriorityHigh:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown priority %q", priority)
+	}
+}
+
+// Allows checks if the payload should be delivered under the policy
+func (p Policy) Allows(payload *AnnouncerPayload) bool {
+	if p.SecurityOnly && payload.Security == nil {
+		return false
+	}
+
+	if p.HasBreakingChanges && !payload.HasBreakingChanges() {
+		return false
+	}
+
+	if !p.Subscribes(payload) {
+		return false
+	}
+
+	if len(p.Events) == 0 {
+		return payload.GetEvent() == types.EventNew
+	}
+
+	for _, event := range p.Events {
+		if event == payload.GetEvent() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Subscribes checks if the announcer receives the releases of the repository of the payload, the repositories which
+// name their announcers are only delivered to them regardless of the subscriptions of the announcers
+func (p Policy) Subscribes(payload *AnnouncerPayload) bool {
+	if len(payload.Announcers) > 0 {
+		return contains(payload.Announcers, p.Name)
+	}
+
+	if len(p.Repositories) == 0 && len(p.Tags) == 0 {
+		return true
+	}
+
+	if contains(p.Repositories, payload.ProjectName) {
+		return true
+	}
+
+	for _, tag := range payload.Tags {
+		if contains(p.Tags, tag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Route returns a copy of the payload with the overrides of the first matching route applied
+func (p Policy) Route(payload *AnnouncerPayload) *AnnouncerPayload {
+	routed := *payload
+	for _, route := range p.Routes {
+		if !route.Matches(payload) {
+			continue
+		}
+
+		if route.Priority != "" {
+			routed.Priority = route.Priority
+		}
+
+		if len(route.Recipients) > 0 {
+			routed.Recipients = route.Recipients
+		}
+
+		routed.Immediate = route.Immediate
+
+		break
+	}
+
+	return &routed
+}
+
+// Matches checks if the payload satisfies the conditions of the route
+func (r Route) Matches(payload *AnnouncerPayload) bool {
+	return (!r.Security || payload.Security != nil) && (!r.HasBreakingChanges || payload.HasBreakingChanges()) &&
+		(len(r.Repositories) == 0 || contains(r.Repositories, payload.ProjectName))
+}
+
+func contains(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PolicyAnnouncer wraps an Announcer and passes only the payloads allowed by its Policy
+type PolicyAnnouncer struct {
+	Announcer
+	Policy
+}
+
+// NewPolicyAnnouncer creates a new PolicyAnnouncer which wraps the given announcer
+func NewPolicyAnnouncer(announcer Announcer, policy Policy) *PolicyAnnouncer {
+	return &PolicyAnnouncer{
+		Announcer: announcer,
+		Policy:    policy,
+	}
+}
+
+// Notify routes the payload and passes it to the wrapped announcer if it is allowed, returns ErrFiltered otherwise
+func (p *PolicyAnnouncer) Notify(payload *AnnouncerPayload) error {
+	if !p.Allows(payload) {
+		return ErrFiltered
+	}
+
+	return p.Announcer.Notify(p.Route(payload))
+}
+
+// Unwrap returns the wrapped announcer
+func (p *PolicyAnnouncer) Unwrap() Announcer {
+	return p.Announcer
+}
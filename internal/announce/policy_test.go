@@ -0,0 +1,194 @@
+//go:build unit
+
+package announce
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type errorAnnouncer struct{}
+
+func (e *errorAnnouncer) Notify(payload *AnnouncerPayload) error {
+	return errors.New("injected error")
+}
+
+func (e *errorAnnouncer) IsEnabled() bool {
+	return true
+}
+
+type recordingAnnouncer struct {
+	payloads []*AnnouncerPayload
+}
+
+func (r *recordingAnnouncer) Notify(payload *AnnouncerPayload) error {
+	r.payloads = append(r.payloads, payload)
+	return nil
+}
+
+func (r *recordingAnnouncer) IsEnabled() bool {
+	return true
+}
+
+func TestParseEvents(t *testing.T) {
+	events, err := ParseEvents([]string{"new", "removed"})
+	assert.Nil(t, err)
+	assert.Equal(t, []types.EventType{types.EventNew, types.EventRemoved}, events)
+
+	_, err = ParseEvents([]string{"deleted"})
+	assert.NotNil(t, err)
+}
+
+func TestParsePriority(t *testing.T) {
+	priority, err := ParsePriority("")
+	assert.Nil(t, err)
+	assert.Equal(t, PriorityNormal, priority)
+
+	priority, err = ParsePriority("high")
+	assert.Nil(t, err)
+	assert.Equal(t, PriorityHigh, priority)
+
+	_, err = ParsePriority("critical")
+	assert.NotNil(t, err)
+}
+
+func TestPolicyAnnouncer_Route(t *testing.T) {
+	policy := Policy{
+		Routes: []Route{
+			{Security: true, Priority: PriorityHigh, Recipients: []string{"#security"}},
+			{Recipients: []string{"#releases"}},
+		},
+	}
+
+	recorder := &recordingAnnouncer{}
+	announcer := NewPolicyAnnouncer(recorder, policy)
+
+	securityPayload := &AnnouncerPayload{ProjectName: "user1/project1", Version: "v1.0.1", Security: &types.Security{CVEs: []string{"CVE-2023-12345"}}}
+	assert.Nil(t, announcer.Notify(securityPayload))
+	assert.Nil(t, announcer.Notify(&AnnouncerPayload{ProjectName: "user1/project1", Version: "v1.0.0"}))
+
+	assert.Len(t, recorder.payloads, 2)
+	assert.Equal(t, []string{"#security"}, recorder.payloads[0].Recipients)
+	assert.True(t, recorder.payloads[0].IsUrgent())
+	assert.Equal(t, []string{"#releases"}, recorder.payloads[1].Recipients)
+	assert.False(t, recorder.payloads[1].IsUrgent())
+
+	breaking := NewPolicyAnnouncer(recorder, Policy{
+		HasBreakingChanges: true,
+		Routes:             []Route{{HasBreakingChanges: true, Priority: PriorityHigh}},
+	})
+	assert.Equal(t, ErrFiltered, breaking.Notify(&AnnouncerPayload{ProjectName: "user1/project1", Version: "v1.0.0"}))
+	assert.Nil(t, breaking.Notify(&AnnouncerPayload{ProjectName: "user1/project1", Version: "v2.0.0", BreakingChanges: "<p>drop v1 api</p>"}))
+	assert.Len(t, recorder.payloads, 3)
+	assert.True(t, recorder.payloads[2].IsUrgent())
+
+	repositories := NewPolicyAnnouncer(recorder, Policy{
+		Repositories: []string{"user1/project1", "user1/project2"},
+		Routes:       []Route{{Repositories: []string{"user1/project2"}, Priority: PriorityHigh}},
+	})
+	assert.Equal(t, ErrFiltered, repositories.Notify(&AnnouncerPayload{ProjectName: "user1/project3", Version: "v1.0.0"}))
+	assert.Nil(t, repositories.Notify(&AnnouncerPayload{ProjectName: "user1/project1", Version: "v1.0.0"}))
+	assert.Nil(t, repositories.Notify(&AnnouncerPayload{ProjectName: "user1/project2", Version: "v1.0.0"}))
+	assert.Len(t, recorder.payloads, 5)
+	assert.False(t, recorder.payloads[3].IsUrgent())
+	assert.True(t, recorder.payloads[4].IsUrgent())
+
+	immediate := NewPolicyAnnouncer(recorder, Policy{
+		Routes: []Route{{Security: true, Immediate: true}},
+	})
+	assert.Nil(t, immediate.Notify(securityPayload))
+	assert.Nil(t, immediate.Notify(&AnnouncerPayload{ProjectName: "user1/project1", Version: "v1.0.0"}))
+	assert.Len(t, recorder.payloads, 7)
+	assert.True(t, recorder.payloads[5].Immediate)
+	assert.False(t, recorder.payloads[5].IsUrgent())
+	assert.False(t, recorder.payloads[6].Immediate)
+
+	// the original payload must not be modified since it is shared between announcers
+	assert.Empty(t, securityPayload.Recipients)
+	assert.False(t, securityPayload.Immediate)
+}
+
+func TestPolicyAnnouncer_Notify(t *testing.T) {
+	cases := []struct {
+		caseName     string
+		events       []types.EventType
+		securityOnly bool
+		payload      *AnnouncerPayload
+		expectedErr  error
+	}{
+		{
+			"Default policy allows new releases",
+			nil,
+			false,
+			&AnnouncerPayload{ProjectName: "user1/project1", Version: "v1.0.0"},
+			errors.New("injected error"),
+		},
+		{
+			"Default policy filters updated releases",
+			nil,
+			false,
+			&AnnouncerPayload{ProjectName: "user1/project1", Version: "v1.0.0", Event: types.EventUpdated},
+			ErrFiltered,
+		},
+		{
+			"Configured policy allows removed releases",
+			[]types.EventType{types.EventRemoved},
+			false,
+			&AnnouncerPayload{ProjectName: "user1/project1", Version: "v1.0.0", Event: types.EventRemoved},
+			errors.New("injected error"),
+		},
+		{
+			"Security only policy filters regular releases",
+			nil,
+			true,
+			&AnnouncerPayload{ProjectName: "user1/project1", Version: "v1.0.0"},
+			ErrFiltered,
+		},
+		{
+			"Configured policy filters new releases",
+			[]types.EventType{types.EventRemoved},
+			false,
+			&AnnouncerPayload{ProjectName: "user1/project1", Version: "v1.0.0"},
+			ErrFiltered,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		announcer := NewPolicyAnnouncer(&errorAnnouncer{}, Policy{Events: tc.events, SecurityOnly: tc.securityOnly})
+		assert.True(t, announcer.IsEnabled())
+		assert.Equal(t, tc.expectedErr, announcer.Notify(tc.payload))
+	}
+}
+
+func TestPolicy_Subscribes(t *testing.T) {
+	cases := []struct {
+		caseName string
+		policy   Policy
+		payload  *AnnouncerPayload
+		expected bool
+	}{
+		{"No subscriptions", Policy{Name: "slack"}, &AnnouncerPayload{ProjectName: "user1/project1"}, true},
+		{"Subscribed repository", Policy{Name: "slack", Repositories: []string{"user1/project1"}, Tags: []string{"platform"}},
+			&AnnouncerPayload{ProjectName: "user1/project1"}, true},
+		{"Subscribed tag", Policy{Name: "slack", Repositories: []string{"user1/project1"}, Tags: []string{"platform"}},
+			&AnnouncerPayload{ProjectName: "user1/project2", Tags: []string{"backend", "platform"}}, true},
+		{"Not subscribed", Policy{Name: "slack", Tags: []string{"platform"}},
+			&AnnouncerPayload{ProjectName: "user1/project2", Tags: []string{"frontend"}}, false},
+		{"Referenced by repository", Policy{Name: "team-a", Tags: []string{"platform"}},
+			&AnnouncerPayload{ProjectName: "user1/project2", Announcers: []string{"team-a"}}, true},
+		{"Not referenced by repository", Policy{Name: "slack"},
+			&AnnouncerPayload{ProjectName: "user1/project1", Announcers: []string{"team-a"}}, false},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		assert.Equal(t, tc.expected, tc.policy.Subscribes(tc.payload))
+		assert.Equal(t, tc.expected, tc.policy.Allows(tc.payload))
+	}
+}
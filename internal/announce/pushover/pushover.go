@@ -0,0 +1,128 @@
+package pushover
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/notes"
+)
+
+const (
+	defaultApiUrl = "https://api.pushover.net/1/messages.json"
+
+	// message limits of the Pushover API
+	maxMessageLength = 1024
+	maxTitleLength   = 250
+
+	minPriority    = -2
+	urgentPriority = 1
+	// emergency priority 2 is not supported since it requires the receipts to be acknowledged
+	maxPriority = 1
+)
+
+// apiResponse is the response body of the messages API
+type apiResponse struct {
+	Status int      `json:"status"`
+	Errors []string `json:"errors"`
+}
+
+// PushoverAnnouncer is the announcer that pushes the releases to Pushover users or groups
+type PushoverAnnouncer struct {
+	ApiUrl   string
+	AppToken string
+	UserKey  string
+	Priority int
+	client   *http.Client
+}
+
+// NewPushoverAnnouncer creates a new PushoverAnnouncer. userKey is the key of a user or a delivery group, priority
+// is between -2 and 1. Urgent releases are sent with high priority.
+func NewPushoverAnnouncer(apiUrl, appToken, userKey string, priority int) (*PushoverAnnouncer, error) {
+	if appToken == "" || userKey == "" {
+		return nil, errors.New("pushover application token and user key are required")
+	}
+
+	if priority < minPriority || priority > maxPriority {
+		return nil, fmt.Errorf("pushover priority must be between %d and %d", minPriority, maxPriority)
+	}
+
+	if apiUrl == "" {
+		apiUrl = defaultApiUrl
+	}
+
+	return &PushoverAnnouncer{
+		ApiUrl:   apiUrl,
+		AppToken: appToken,
+		UserKey:  userKey,
+		Priority: priority,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Notify pushes the message to the user, routed payloads are pushed to their recipient user keys instead.
+func (p *PushoverAnnouncer) Notify(payload *announce.AnnouncerPayload) error {
+	users := p.UserKey
+	if len(payload.Recipients) > 0 {
+		// the API accepts up to 50 comma separated user keys in a single request
+		users = strings.Join(payload.Recipients, ",")
+	}
+
+	form := p.buildForm(payload)
+	form.Set("user", users)
+
+	resp, err := p.client.PostForm(p.ApiUrl, form)
+	if err != nil {
+		// the request body contains the application token, so the error is not wrapped to avoid leaking it
+		return errors.New("an error occurred while calling pushover api")
+	}
+	defer resp.Body.Close()
+
+	var response apiResponse
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err := json.Unmarshal(respBody, &response); err != nil {
+		return fmt.Errorf("unexpected response from pushover api with status code %d", resp.StatusCode)
+	}
+
+	if response.Status != 1 {
+		return fmt.Errorf("pushover api returned status code %d: %s", resp.StatusCode, strings.Join(response.Errors, ", "))
+	}
+
+	return nil
+}
+
+// IsEnabled checks if the PushoverAnnouncer is enabled.
+func (p *PushoverAnnouncer) IsEnabled() bool {
+	return p.AppToken != "" && p.UserKey != ""
+}
+
+func (p *PushoverAnnouncer) buildForm(payload *announce.AnnouncerPayload) url.Values {
+	message := payload.Summary()
+	if breakingChanges := notes.ToText(payload.BreakingChanges); breakingChanges != "" {
+		message += "\n\nBreaking changes:\n" + breakingChanges
+	}
+
+	priority := p.Priority
+	if payload.IsUrgent() && priority < urgentPriority {
+		priority = urgentPriority
+	}
+
+	form := url.Values{}
+	form.Set("token", p.AppToken)
+	form.Set("title", notes.Truncate(payload.Title(fmt.Sprintf("%s %s", payload.ProjectName, payload.Version)), maxTitleLength))
+	form.Set("message", notes.Truncate(message, maxMessageLength))
+	form.Set("priority", strconv.Itoa(priority))
+	if payload.URL != "" {
+		form.Set("url", payload.URL)
+		form.Set("url_title", "Open release")
+	}
+
+	return form
+}
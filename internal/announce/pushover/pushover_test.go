@@ -0,0 +1,127 @@
+//go:build unit
+
+package pushover
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/stretchr/testify/assert"
+)
+
+func newFakeApi(t *testing.T) (*[]url.Values, string) {
+	var forms []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		forms = append(forms, r.PostForm)
+
+		if r.PostForm.Get("user") == "invalid" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"user":"invalid","errors":["user identifier is not a valid user, group, or subscribed user key"],"status":0}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"status":1,"request":"x"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return &forms, server.URL
+}
+
+func TestNewPushoverAnnouncer(t *testing.T) {
+	cases := []struct {
+		caseName   string
+		appToken   string
+		userKey    string
+		priority   int
+		shouldPass bool
+	}{
+		{"Defaults", "app-token", "user-key", 0, true},
+		{"Lowest priority", "app-token", "user-key", -2, true},
+		{"Missing user key", "app-token", "", 0, false},
+		{"Emergency priority", "app-token", "user-key", 2, false},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		announcer, err := NewPushoverAnnouncer("", tc.appToken, tc.userKey, tc.priority)
+		if !tc.shouldPass {
+			assert.NotNil(t, err)
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.Equal(t, defaultApiUrl, announcer.ApiUrl)
+		assert.True(t, announcer.IsEnabled())
+	}
+}
+
+func TestPushoverAnnouncer_Notify(t *testing.T) {
+	cases := []struct {
+		caseName         string
+		payload          *announce.AnnouncerPayload
+		expectedUser     string
+		expectedPriority string
+		expectedMessage  string
+		shouldPass       bool
+	}{
+		{
+			"Default user",
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", URL: "https://example.com"},
+			"user-key", "-1", "x-project v1.0.0 is out! Check it out at https://example.com", true,
+		},
+		{
+			"Urgent breaking release routed to other users",
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v2.0.0", URL: "https://example.com",
+				BreakingChanges: "<ul><li>Removed <strong>foo</strong></li></ul>", Priority: announce.PriorityHigh, Recipients: []string{"alice", "bob"}},
+			"alice,bob", "1", "x-project v2.0.0 is out! Check it out at https://example.com\n\nBreaking changes:\n- Removed foo", true,
+		},
+		{
+			"Invalid user",
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", URL: "https://example.com", Recipients: []string{"invalid"}},
+			"invalid", "-1", "x-project v1.0.0 is out! Check it out at https://example.com", false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		forms, url := newFakeApi(t)
+		announcer, err := NewPushoverAnnouncer(url, "app-token", "user-key", -1)
+		assert.Nil(t, err)
+
+		err = announcer.Notify(tc.payload)
+		if tc.shouldPass {
+			assert.Nil(t, err)
+		} else {
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), "not a valid user")
+		}
+
+		assert.Len(t, *forms, 1)
+		form := (*forms)[0]
+		assert.Equal(t, "app-token", form.Get("token"))
+		assert.Equal(t, tc.expectedUser, form.Get("user"))
+		assert.Equal(t, tc.payload.ProjectName+" "+tc.payload.Version, form.Get("title"))
+		assert.Equal(t, tc.expectedMessage, form.Get("message"))
+		assert.Equal(t, tc.expectedPriority, form.Get("priority"))
+		assert.Equal(t, "https://example.com", form.Get("url"))
+	}
+}
+
+func TestPushoverAnnouncer_buildFormTruncates(t *testing.T) {
+	announcer, err := NewPushoverAnnouncer("", "app-token", "user-key", 0)
+	assert.Nil(t, err)
+
+	form := announcer.buildForm(&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0",
+		BreakingChanges: "<p>" + strings.Repeat("a ", maxMessageLength) + "</p>"})
+	assert.LessOrEqual(t, utf8.RuneCountInString(form.Get("message")), maxMessageLength)
+	assert.True(t, strings.HasSuffix(form.Get("message"), "…"))
+	assert.Equal(t, "", form.Get("url"))
+}
@@ -0,0 +1,150 @@
+package rocketchat
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/notes"
+)
+
+// maxNotesLength is the maximum length of the release notes attachment
+const maxNotesLength = 4000
+
+// WebhookMessage is the payload of the Rocket.Chat incoming webhooks
+type WebhookMessage struct {
+	Text        string       `json:"text"`
+	Channel     string       `json:"channel,omitempty"`
+	Alias       string       `json:"alias,omitempty"`
+	Avatar      string       `json:"avatar,omitempty"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+// Attachment is the message attachment of Rocket.Chat
+type Attachment struct {
+	Color     string `json:"color,omitempty"`
+	Title     string `json:"title,omitempty"`
+	TitleLink string `json:"title_link,omitempty"`
+	Text      string `json:"text"`
+	Collapsed bool   `json:"collapsed"`
+}
+
+// webhookResponse is the response body of the Rocket.Chat incoming webhooks
+type webhookResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error"`
+}
+
+// RocketChatAnnouncer is the announcer that posts the releases to a Rocket.Chat incoming webhook
+type RocketChatAnnouncer struct {
+	WebhookUrl string
+	Channel    string
+	Username   string
+	IconUrl    string
+	client     *http.Client
+}
+
+// NewRocketChatAnnouncer creates a new RocketChatAnnouncer, channel, username and iconUrl override the defaults of the
+// webhook if set.
+func NewRocketChatAnnouncer(webhookUrl, channel, username, iconUrl string) (*RocketChatAnnouncer, error) {
+	if webhookUrl == "" {
+		return nil, errors.New("rocket.chat webhook url is required")
+	}
+
+	return &RocketChatAnnouncer{
+		WebhookUrl: webhookUrl,
+		Channel:    channel,
+		Username:   username,
+		IconUrl:    iconUrl,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Notify posts the message to the channel, routed payloads are posted to each of their recipient channels instead.
+// Recipients are channels like #releases or users like @alice.
+func (r *RocketChatAnnouncer) Notify(payload *announce.AnnouncerPayload) error {
+	channels := payload.Recipients
+	if len(channels) == 0 {
+		channels = []string{r.Channel}
+	}
+
+	var errs []error
+	for _, channel := range channels {
+		msg := r.buildMessage(payload)
+		msg.Channel = channel
+		if err := r.post(msg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// IsEnabled checks if the RocketChatAnnouncer is enabled.
+func (r *RocketChatAnnouncer) IsEnabled() bool {
+	return r.WebhookUrl != ""
+}
+
+func (r *RocketChatAnnouncer) post(msg *WebhookMessage) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	resp, err := r.client.Post(r.WebhookUrl, "application/json", bytes.NewReader(body))
+	if err != nil {
+		// the webhook url contains the token, so the error is not wrapped to avoid leaking it to the logs
+		return errors.New("an error occurred while calling rocket.chat webhook")
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("rocket.chat webhook returned status code %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	// the failures of the integration scripts are reported with a successful status code
+	var response webhookResponse
+	if err := json.Unmarshal(respBody, &response); err == nil && !response.Success {
+		return fmt.Errorf("rocket.chat webhook returned error: %s", response.Error)
+	}
+
+	return nil
+}
+
+func (r *RocketChatAnnouncer) buildMessage(payload *announce.AnnouncerPayload) *WebhookMessage {
+	msg := &WebhookMessage{
+		Text:   payload.Summary(),
+		Alias:  r.Username,
+		Avatar: r.IconUrl,
+	}
+
+	if payload.IsUrgent() {
+		msg.Text = fmt.Sprintf(":rotating_light: @here %s", msg.Text)
+	}
+
+	if breakingChanges := notes.ToMarkdown(payload.BreakingChanges); breakingChanges != "" {
+		msg.Attachments = append(msg.Attachments, Attachment{
+			Title: ":warning: Breaking changes",
+			Color: "#E74C3C",
+			Text:  notes.Truncate(breakingChanges, maxNotesLength),
+		})
+	}
+
+	if releaseNotes := notes.ToMarkdown(payload.Notes); releaseNotes != "" {
+		msg.Attachments = append(msg.Attachments, Attachment{
+			Title:     "Release notes",
+			TitleLink: payload.URL,
+			Text:      notes.Truncate(releaseNotes, maxNotesLength),
+			Collapsed: true,
+		})
+	}
+
+	return msg
+}
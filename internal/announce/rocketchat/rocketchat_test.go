@@ -0,0 +1,104 @@
+//go:build unit
+
+package rocketchat
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/stretchr/testify/assert"
+)
+
+func newFakeWebhook(t *testing.T, status int, response string) (*[]WebhookMessage, string) {
+	var messages []WebhookMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg WebhookMessage
+		_ = json.NewDecoder(r.Body).Decode(&msg)
+		messages = append(messages, msg)
+
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+
+	return &messages, server.URL
+}
+
+func TestNewRocketChatAnnouncer(t *testing.T) {
+	announcer, err := NewRocketChatAnnouncer("", "", "", "")
+	assert.Nil(t, announcer)
+	assert.NotNil(t, err)
+
+	announcer, err = NewRocketChatAnnouncer("https://chat.example.com/hooks/x/y", "#releases", "", "")
+	assert.Nil(t, err)
+	assert.True(t, announcer.IsEnabled())
+}
+
+func TestRocketChatAnnouncer_Notify(t *testing.T) {
+	cases := []struct {
+		caseName            string
+		status              int
+		response            string
+		payload             *announce.AnnouncerPayload
+		expectedChannels    []string
+		expectedText        string
+		expectedAttachments []string
+		shouldPass          bool
+	}{
+		{
+			"Default channel with notes", http.StatusOK, `{"success":true}`,
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", URL: "https://example.com", Notes: "<p>Fixes <strong>bug</strong></p>"},
+			[]string{"#releases"}, "x-project v1.0.0 is out! Check it out at https://example.com", []string{"Release notes"}, true,
+		},
+		{
+			"Urgent payload routed to other channels", http.StatusOK, `{"success":true}`,
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v2.0.0", URL: "https://example.com", BreakingChanges: "<p>Removed foo</p>",
+				Priority: announce.PriorityHigh, Recipients: []string{"#security", "@alice"}},
+			[]string{"#security", "@alice"}, ":rotating_light: @here x-project v2.0.0 is out! Check it out at https://example.com",
+			[]string{":warning: Breaking changes"}, true,
+		},
+		{
+			"Failed integration script", http.StatusOK, `{"success":false,"error":"invalid-channel"}`,
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", URL: "https://example.com"},
+			[]string{"#releases"}, "x-project v1.0.0 is out! Check it out at https://example.com", nil, false,
+		},
+		{
+			"Failed delivery", http.StatusNotFound, `{"success":false,"error":"Invalid integration id or token provided."}`,
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", URL: "https://example.com"},
+			[]string{"#releases"}, "x-project v1.0.0 is out! Check it out at https://example.com", nil, false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		messages, url := newFakeWebhook(t, tc.status, tc.response)
+		announcer, err := NewRocketChatAnnouncer(url, "#releases", "rss-feed-filterer", "https://example.com/icon.png")
+		assert.Nil(t, err)
+
+		err = announcer.Notify(tc.payload)
+		if tc.shouldPass {
+			assert.Nil(t, err)
+		} else {
+			assert.NotNil(t, err)
+		}
+
+		assert.Len(t, *messages, len(tc.expectedChannels))
+		for i, msg := range *messages {
+			assert.Equal(t, tc.expectedChannels[i], msg.Channel)
+			assert.Equal(t, tc.expectedText, msg.Text)
+			assert.Equal(t, "rss-feed-filterer", msg.Alias)
+			assert.Equal(t, "https://example.com/icon.png", msg.Avatar)
+
+			var titles []string
+			for _, attachment := range msg.Attachments {
+				titles = append(titles, attachment.Title)
+			}
+
+			assert.Equal(t, tc.expectedAttachments, titles)
+		}
+	}
+}
@@ -8,8 +8,12 @@ import (
 	api "github.com/slack-go/slack"
 
 	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/notes"
 )
 
+// maxNotesLength is the maximum length of the release notes attachment, longer attachments are collapsed by Slack
+const maxNotesLength = 3000
+
 type SlackAPI interface {
 	PostWebhook(url string, msg *api.WebhookMessage) error
 }
@@ -47,7 +51,33 @@ func (sa *SlackAnnouncer) Notify(payload *announce.AnnouncerPayload) error {
 		Attachments: []api.Attachment{},
 		Username:    sa.Username,
 		IconURL:     sa.IconUrl,
-		Text:        fmt.Sprintf("%s %s is out! Check it out at %s", payload.ProjectName, payload.Version, payload.URL),
+		Text:        payload.Summary(),
+	}
+
+	// webhooks are bound to a channel, routed payloads can only override it for the legacy webhooks
+	if len(payload.Recipients) > 0 {
+		msg.Channel = payload.Recipients[0]
+	}
+
+	if payload.IsUrgent() {
+		msg.Text = fmt.Sprintf(":rotating_light: <!channel> %s", msg.Text)
+	}
+
+	if breakingChanges := notes.ToSlack(payload.BreakingChanges); breakingChanges != "" {
+		msg.Attachments = append(msg.Attachments, api.Attachment{
+			Title:      ":warning: Breaking changes",
+			Color:      "danger",
+			Text:       notes.Truncate(breakingChanges, maxNotesLength),
+			MarkdownIn: []string{"text"},
+		})
+	}
+
+	if releaseNotes := notes.ToSlack(payload.Notes); releaseNotes != "" {
+		msg.Attachments = append(msg.Attachments, api.Attachment{
+			Title:      "Release notes",
+			Text:       notes.Truncate(releaseNotes, maxNotesLength),
+			MarkdownIn: []string{"text"},
+		})
 	}
 
 	return sa.Service.PostWebhook(sa.WebhookURL, &msg)
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/types"
 
 	api "github.com/slack-go/slack"
 	"github.com/stretchr/testify/assert"
@@ -85,6 +86,64 @@ func TestSlackAnnouncer_Notify(t *testing.T) {
 	}
 }
 
+func TestSlackAnnouncer_NotifyRouted(t *testing.T) {
+	mockSlackAPI := new(MockSlackAPI)
+	announcer := NewSlackAnnouncer("test-webhook-url", "foo", "bar", mockSlackAPI)
+
+	mockSlackAPI.On("PostWebhook", "test-webhook-url", &api.WebhookMessage{
+		Attachments: []api.Attachment{},
+		Username:    "foo",
+		IconURL:     "bar",
+		Channel:     "#security",
+		Text:        ":rotating_light: <!channel> Test Project 1.0.1 is out! Check it out at https://example.com Security advisories: CVE-2023-12345",
+	}).Return(nil)
+
+	err := announcer.Notify(&announce.AnnouncerPayload{
+		ProjectName: "Test Project",
+		Version:     "1.0.1",
+		URL:         "https://example.com",
+		Security:    &types.Security{CVEs: []string{"CVE-2023-12345"}},
+		Priority:    announce.PriorityHigh,
+		Recipients:  []string{"#security"},
+	})
+	assert.Nil(t, err)
+	mockSlackAPI.AssertExpectations(t)
+}
+
+func TestSlackAnnouncer_NotifyWithNotes(t *testing.T) {
+	mockSlackAPI := new(MockSlackAPI)
+	announcer := NewSlackAnnouncer("test-webhook-url", "foo", "bar", mockSlackAPI)
+
+	mockSlackAPI.On("PostWebhook", "test-webhook-url", &api.WebhookMessage{
+		Attachments: []api.Attachment{
+			{
+				Title:      ":warning: Breaking changes",
+				Color:      "danger",
+				Text:       "• BREAKING: drop v1 api",
+				MarkdownIn: []string{"text"},
+			},
+			{
+				Title:      "Release notes",
+				Text:       "• Fix <https://example.com/pull/1|#1>",
+				MarkdownIn: []string{"text"},
+			},
+		},
+		Username: "foo",
+		IconURL:  "bar",
+		Text:     "Test Project 1.0.1 is out! Check it out at https://example.com",
+	}).Return(nil)
+
+	err := announcer.Notify(&announce.AnnouncerPayload{
+		ProjectName:     "Test Project",
+		Version:         "1.0.1",
+		URL:             "https://example.com",
+		Notes:           `<ul><li>Fix <a href="https://example.com/pull/1">#1</a></li></ul>`,
+		BreakingChanges: `<ul><li>BREAKING: drop v1 api</li></ul>`,
+	})
+	assert.Nil(t, err)
+	mockSlackAPI.AssertExpectations(t)
+}
+
 func TestSlackAnnouncer_IsEnabled(t *testing.T) {
 	sa := NewSlackAnnouncer("asdlfkj", "foo", "bar", &SlackService{})
 	assert.True(t, sa.IsEnabled())
@@ -0,0 +1,110 @@
+package sns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/notes"
+)
+
+const (
+	// maxSubjectLength is the limit of the subject used by the email subscriptions
+	maxSubjectLength = 100
+	// maxMessageSize is the limit of the messages, 256KiB less the room for the subject and the message attributes
+	maxMessageSize = 250 * 1024
+)
+
+// SNSClient is the interface that contains Publish function.
+// We can mock this interface for testing purposes.
+type SNSClient interface {
+	Publish(ctx context.Context, params *sns.PublishInput, optFns ...func(*sns.Options)) (*sns.PublishOutput, error)
+}
+
+// SNSAnnouncer is the announcer that publishes the release events to an SNS topic
+type SNSAnnouncer struct {
+	TopicArn string
+	// Format is one of announce.EventFormatJSON and announce.EventFormatCloudEvents
+	Format announce.EventFormat
+	client SNSClient
+}
+
+// NewSNSAnnouncer creates a new SNSAnnouncer. It requires a SNSClient interface. format is one of json and
+// cloudevents, defaults to json.
+func NewSNSAnnouncer(client SNSClient, topicArn string, format announce.EventFormat) (*SNSAnnouncer, error) {
+	if topicArn == "" {
+		return nil, errors.New("sns topic arn is required")
+	}
+
+	switch format {
+	case "":
+		format = announce.EventFormatJSON
+	case announce.EventFormatJSON, announce.EventFormatCloudEvents:
+	default:
+		return nil, fmt.Errorf("unsupported sns format %q", format)
+	}
+
+	return &SNSAnnouncer{
+		TopicArn: topicArn,
+		Format:   format,
+		client:   client,
+	}, nil
+}
+
+// Notify publishes the release event to the topic, routed payloads override it with the topic arns in the recipients.
+// The event, project, level and priority are also sent as message attributes to be used in the subscription filter
+// policies. The messages of the FIFO topics are grouped by the project.
+func (s *SNSAnnouncer) Notify(payload *announce.AnnouncerPayload) error {
+	event, err := announce.EncodeEventWithin(s.Format, payload, maxMessageSize)
+	if err != nil {
+		return err
+	}
+
+	topics := payload.Recipients
+	if len(topics) == 0 {
+		topics = []string{s.TopicArn}
+	}
+
+	var errs []error
+	for _, topic := range topics {
+		input := &sns.PublishInput{
+			TopicArn:          aws.String(topic),
+			Subject:           aws.String(notes.Truncate(fmt.Sprintf("%s %s", payload.ProjectName, payload.Version), maxSubjectLength)),
+			Message:           aws.String(string(event.Body)),
+			MessageAttributes: messageAttributes(payload),
+		}
+
+		if strings.HasSuffix(topic, ".fifo") {
+			input.MessageGroupId = aws.String(payload.ProjectName)
+			input.MessageDeduplicationId = aws.String(announce.DeduplicationId(payload))
+		}
+
+		if _, err := s.client.Publish(context.Background(), input); err != nil {
+			errs = append(errs, fmt.Errorf("topic %s: %w", topic, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// IsEnabled checks if the SNSAnnouncer is enabled.
+func (s *SNSAnnouncer) IsEnabled() bool {
+	return s.TopicArn != ""
+}
+
+// messageAttributes returns the attributes of the release event to be filtered on
+func messageAttributes(payload *announce.AnnouncerPayload) map[string]types.MessageAttributeValue {
+	attributes := announce.EventAttributes(payload)
+	values := make(map[string]types.MessageAttributeValue, len(attributes))
+	for name, value := range attributes {
+		values[name] = types.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(value)}
+	}
+
+	return values
+}
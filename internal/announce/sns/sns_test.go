@@ -0,0 +1,151 @@
+//go:build unit
+
+package sns
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSNSClient struct {
+	mock.Mock
+}
+
+func (m *MockSNSClient) Publish(ctx context.Context, params *sns.PublishInput, optFns ...func(*sns.Options)) (*sns.PublishOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*sns.PublishOutput), args.Error(1)
+}
+
+func TestNewSNSAnnouncer(t *testing.T) {
+	announcer, err := NewSNSAnnouncer(nil, "arn:aws:sns:us-east-1:123456789012:releases", "")
+	assert.Nil(t, err)
+	assert.True(t, announcer.IsEnabled())
+	assert.Equal(t, announce.EventFormatJSON, announcer.Format)
+
+	_, err = NewSNSAnnouncer(nil, "", "")
+	assert.NotNil(t, err)
+
+	_, err = NewSNSAnnouncer(nil, "arn:aws:sns:us-east-1:123456789012:releases", announce.EventFormatCloudEventsBinary)
+	assert.NotNil(t, err)
+}
+
+func TestSNSAnnouncer_Notify(t *testing.T) {
+	cases := []struct {
+		caseName       string
+		topicArn       string
+		payload        *announce.AnnouncerPayload
+		expectedTopics []string
+		expectedGroup  *string
+		injectedErr    error
+	}{
+		{
+			"Standard topic",
+			"arn:aws:sns:us-east-1:123456789012:releases",
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", URL: "https://example.com", Notes: "<p>a<b>b</b></p>"},
+			[]string{"arn:aws:sns:us-east-1:123456789012:releases"},
+			nil,
+			nil,
+		},
+		{
+			"FIFO topic is grouped by the project",
+			"arn:aws:sns:us-east-1:123456789012:releases.fifo",
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", URL: "https://example.com",
+				Security: &types.Security{CVEs: []string{"CVE-2023-1234"}}},
+			[]string{"arn:aws:sns:us-east-1:123456789012:releases.fifo"},
+			aws.String("x-project"),
+			nil,
+		},
+		{
+			"Routed topics",
+			"arn:aws:sns:us-east-1:123456789012:releases",
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", URL: "https://example.com",
+				Recipients: []string{"arn:aws:sns:us-east-1:123456789012:security", "arn:aws:sns:us-east-1:123456789012:platform"}},
+			[]string{"arn:aws:sns:us-east-1:123456789012:security", "arn:aws:sns:us-east-1:123456789012:platform"},
+			nil,
+			nil,
+		},
+		{
+			"Publish error",
+			"arn:aws:sns:us-east-1:123456789012:releases",
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", URL: "https://example.com"},
+			[]string{"arn:aws:sns:us-east-1:123456789012:releases"},
+			nil,
+			errors.New("injected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		client := new(MockSNSClient)
+		client.On("Publish", mock.Anything, mock.Anything, mock.Anything).Return(&sns.PublishOutput{}, tc.injectedErr)
+
+		announcer, err := NewSNSAnnouncer(client, tc.topicArn, "")
+		assert.Nil(t, err)
+
+		err = announcer.Notify(tc.payload)
+		if tc.injectedErr != nil {
+			assert.ErrorIs(t, err, tc.injectedErr)
+		} else {
+			assert.Nil(t, err)
+		}
+
+		assert.Len(t, client.Calls, len(tc.expectedTopics))
+		for i, call := range client.Calls {
+			input := call.Arguments.Get(1).(*sns.PublishInput)
+			assert.Equal(t, tc.expectedTopics[i], aws.ToString(input.TopicArn))
+			assert.Equal(t, "x-project v1.0.0", aws.ToString(input.Subject))
+			assert.Equal(t, tc.expectedGroup, input.MessageGroupId)
+			assert.Equal(t, tc.expectedGroup != nil, input.MessageDeduplicationId != nil)
+			if tc.expectedGroup != nil {
+				assert.Equal(t, announce.DeduplicationId(tc.payload), aws.ToString(input.MessageDeduplicationId))
+			}
+			assert.Equal(t, "x-project", aws.ToString(input.MessageAttributes["project"].StringValue))
+			assert.Equal(t, tc.payload.Level(), aws.ToString(input.MessageAttributes["level"].StringValue))
+
+			var event announce.Event
+			assert.Nil(t, json.Unmarshal([]byte(aws.ToString(input.Message)), &event))
+			assert.Equal(t, announce.NewEvent(tc.payload), &event)
+		}
+	}
+}
+
+func TestSNSAnnouncer_Notify_cloudEvents(t *testing.T) {
+	client := new(MockSNSClient)
+	client.On("Publish", mock.Anything, mock.Anything, mock.Anything).Return(&sns.PublishOutput{}, nil)
+
+	announcer, err := NewSNSAnnouncer(client, "arn:aws:sns:us-east-1:123456789012:releases", announce.EventFormatCloudEvents)
+	assert.Nil(t, err)
+	assert.Nil(t, announcer.Notify(&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", URL: "https://example.com"}))
+
+	var event announce.CloudEvent
+	input := client.Calls[0].Arguments.Get(1).(*sns.PublishInput)
+	assert.Nil(t, json.Unmarshal([]byte(aws.ToString(input.Message)), &event))
+	assert.Equal(t, announce.CloudEventsSpecVersion, event.SpecVersion)
+	assert.Equal(t, announce.CloudEventTypePublished, event.Type)
+	assert.Equal(t, "v1.0.0", event.Data.Version)
+}
+
+func TestSNSAnnouncer_Notify_oversizedNotes(t *testing.T) {
+	client := new(MockSNSClient)
+	client.On("Publish", mock.Anything, mock.Anything, mock.Anything).Return(&sns.PublishOutput{}, nil)
+
+	announcer, err := NewSNSAnnouncer(client, "arn:aws:sns:us-east-1:123456789012:releases", announce.EventFormatCloudEvents)
+	assert.Nil(t, err)
+	assert.Nil(t, announcer.Notify(&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0",
+		URL: "https://example.com", Notes: "<p>" + strings.Repeat("Fixes bug. ", 30000) + "</p>"}))
+
+	input := client.Calls[0].Arguments.Get(1).(*sns.PublishInput)
+	assert.LessOrEqual(t, len(aws.ToString(input.Message)), maxMessageSize)
+	assert.NotContains(t, aws.ToString(input.Message), "Fixes bug")
+}
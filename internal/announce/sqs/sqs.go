@@ -0,0 +1,104 @@
+package sqs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+)
+
+// maxMessageSize is the limit of the messages, 256KiB less the room for the message attributes
+const maxMessageSize = 250 * 1024
+
+// SQSClient is the interface that contains SendMessage function.
+// We can mock this interface for testing purposes.
+type SQSClient interface {
+	SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error)
+}
+
+// SQSAnnouncer is the announcer that sends the release events to an SQS queue
+type SQSAnnouncer struct {
+	QueueUrl string
+	// Format is one of announce.EventFormatJSON and announce.EventFormatCloudEvents
+	Format announce.EventFormat
+	client SQSClient
+}
+
+// NewSQSAnnouncer creates a new SQSAnnouncer. It requires a SQSClient interface. format is one of json and
+// cloudevents, defaults to json.
+func NewSQSAnnouncer(client SQSClient, queueUrl string, format announce.EventFormat) (*SQSAnnouncer, error) {
+	if queueUrl == "" {
+		return nil, errors.New("sqs queue url is required")
+	}
+
+	switch format {
+	case "":
+		format = announce.EventFormatJSON
+	case announce.EventFormatJSON, announce.EventFormatCloudEvents:
+	default:
+		return nil, fmt.Errorf("unsupported sqs format %q", format)
+	}
+
+	return &SQSAnnouncer{
+		QueueUrl: queueUrl,
+		Format:   format,
+		client:   client,
+	}, nil
+}
+
+// Notify sends the release event to the queue, routed payloads override it with the queue urls in the recipients.
+// The event, project, level and priority are also sent as message attributes. The messages of the FIFO queues are
+// grouped by the project, so the events of a project are consumed in order.
+func (s *SQSAnnouncer) Notify(payload *announce.AnnouncerPayload) error {
+	event, err := announce.EncodeEventWithin(s.Format, payload, maxMessageSize)
+	if err != nil {
+		return err
+	}
+
+	queues := payload.Recipients
+	if len(queues) == 0 {
+		queues = []string{s.QueueUrl}
+	}
+
+	var errs []error
+	for _, queue := range queues {
+		input := &sqs.SendMessageInput{
+			QueueUrl:          aws.String(queue),
+			MessageBody:       aws.String(string(event.Body)),
+			MessageAttributes: messageAttributes(payload),
+		}
+
+		if strings.HasSuffix(queue, ".fifo") {
+			input.MessageGroupId = aws.String(payload.ProjectName)
+			input.MessageDeduplicationId = aws.String(announce.DeduplicationId(payload))
+		}
+
+		if _, err := s.client.SendMessage(context.Background(), input); err != nil {
+			errs = append(errs, fmt.Errorf("queue %s: %w", queue, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// IsEnabled checks if the SQSAnnouncer is enabled.
+func (s *SQSAnnouncer) IsEnabled() bool {
+	return s.QueueUrl != ""
+}
+
+// messageAttributes returns the attributes of the release event to be filtered on
+func messageAttributes(payload *announce.AnnouncerPayload) map[string]types.MessageAttributeValue {
+	attributes := announce.EventAttributes(payload)
+	values := make(map[string]types.MessageAttributeValue, len(attributes))
+	for name, value := range attributes {
+		values[name] = types.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(value)}
+	}
+
+	return values
+}
@@ -0,0 +1,148 @@
+//go:build unit
+
+package sqs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSQSClient struct {
+	mock.Mock
+}
+
+func (m *MockSQSClient) SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*sqs.SendMessageOutput), args.Error(1)
+}
+
+func TestNewSQSAnnouncer(t *testing.T) {
+	announcer, err := NewSQSAnnouncer(nil, "https://sqs.us-east-1.amazonaws.com/123456789012/releases", "")
+	assert.Nil(t, err)
+	assert.True(t, announcer.IsEnabled())
+	assert.Equal(t, announce.EventFormatJSON, announcer.Format)
+
+	_, err = NewSQSAnnouncer(nil, "", "")
+	assert.NotNil(t, err)
+
+	_, err = NewSQSAnnouncer(nil, "https://sqs.us-east-1.amazonaws.com/123456789012/releases", "xml")
+	assert.NotNil(t, err)
+}
+
+func TestSQSAnnouncer_Notify(t *testing.T) {
+	cases := []struct {
+		caseName      string
+		queueUrl      string
+		payload       *announce.AnnouncerPayload
+		expectedQueue string
+		expectedGroup *string
+		injectedErr   error
+	}{
+		{
+			"Standard queue",
+			"https://sqs.us-east-1.amazonaws.com/123456789012/releases",
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", URL: "https://example.com"},
+			"https://sqs.us-east-1.amazonaws.com/123456789012/releases",
+			nil,
+			nil,
+		},
+		{
+			"FIFO queue is grouped by the project",
+			"https://sqs.us-east-1.amazonaws.com/123456789012/releases.fifo",
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", URL: "https://example.com",
+				Event: types.EventUpdated, Changes: []string{"url"}},
+			"https://sqs.us-east-1.amazonaws.com/123456789012/releases.fifo",
+			aws.String("x-project"),
+			nil,
+		},
+		{
+			"Routed queue",
+			"https://sqs.us-east-1.amazonaws.com/123456789012/releases",
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", URL: "https://example.com",
+				Recipients: []string{"https://sqs.us-east-1.amazonaws.com/123456789012/security"}},
+			"https://sqs.us-east-1.amazonaws.com/123456789012/security",
+			nil,
+			nil,
+		},
+		{
+			"Send error",
+			"https://sqs.us-east-1.amazonaws.com/123456789012/releases",
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", URL: "https://example.com"},
+			"https://sqs.us-east-1.amazonaws.com/123456789012/releases",
+			nil,
+			errors.New("injected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		client := new(MockSQSClient)
+		client.On("SendMessage", mock.Anything, mock.Anything, mock.Anything).Return(&sqs.SendMessageOutput{}, tc.injectedErr)
+
+		announcer, err := NewSQSAnnouncer(client, tc.queueUrl, "")
+		assert.Nil(t, err)
+
+		err = announcer.Notify(tc.payload)
+		if tc.injectedErr != nil {
+			assert.ErrorIs(t, err, tc.injectedErr)
+		} else {
+			assert.Nil(t, err)
+		}
+
+		assert.Len(t, client.Calls, 1)
+		input := client.Calls[0].Arguments.Get(1).(*sqs.SendMessageInput)
+		assert.Equal(t, tc.expectedQueue, aws.ToString(input.QueueUrl))
+		assert.Equal(t, tc.expectedGroup, input.MessageGroupId)
+		assert.Equal(t, tc.expectedGroup != nil, input.MessageDeduplicationId != nil)
+		if tc.expectedGroup != nil {
+			assert.Equal(t, announce.DeduplicationId(tc.payload), aws.ToString(input.MessageDeduplicationId))
+		}
+		assert.Equal(t, string(tc.payload.GetEvent()), aws.ToString(input.MessageAttributes["event"].StringValue))
+
+		expected, _ := announce.EncodeEvent(announce.EventFormatJSON, tc.payload)
+		assert.Equal(t, string(expected.Body), aws.ToString(input.MessageBody))
+	}
+}
+
+func TestSQSAnnouncer_Notify_cloudEvents(t *testing.T) {
+	client := new(MockSQSClient)
+	client.On("SendMessage", mock.Anything, mock.Anything, mock.Anything).Return(&sqs.SendMessageOutput{}, nil)
+
+	announcer, err := NewSQSAnnouncer(client, "https://sqs.us-east-1.amazonaws.com/123456789012/releases",
+		announce.EventFormatCloudEvents)
+	assert.Nil(t, err)
+	assert.Nil(t, announcer.Notify(&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0",
+		URL: "https://example.com", Event: types.EventRemoved}))
+
+	var event announce.CloudEvent
+	input := client.Calls[0].Arguments.Get(1).(*sqs.SendMessageInput)
+	assert.Nil(t, json.Unmarshal([]byte(aws.ToString(input.MessageBody)), &event))
+	assert.Equal(t, announce.CloudEventsSpecVersion, event.SpecVersion)
+	assert.Equal(t, announce.CloudEventTypeRemoved, event.Type)
+	assert.Equal(t, "x-project", event.Subject)
+}
+
+func TestSQSAnnouncer_Notify_oversizedNotes(t *testing.T) {
+	client := new(MockSQSClient)
+	client.On("SendMessage", mock.Anything, mock.Anything, mock.Anything).Return(&sqs.SendMessageOutput{}, nil)
+
+	announcer, err := NewSQSAnnouncer(client, "https://sqs.us-east-1.amazonaws.com/123456789012/releases", "")
+	assert.Nil(t, err)
+	assert.Nil(t, announcer.Notify(&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0",
+		URL: "https://example.com", Notes: "<p>" + strings.Repeat("Fixes bug. ", 30000) + "</p>"}))
+
+	input := client.Calls[0].Arguments.Get(1).(*sqs.SendMessageInput)
+	assert.LessOrEqual(t, len(aws.ToString(input.MessageBody)), maxMessageSize)
+	assert.NotContains(t, aws.ToString(input.MessageBody), "Fixes bug")
+}
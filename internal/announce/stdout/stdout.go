@@ -0,0 +1,64 @@
+package stdout
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+)
+
+// FormatText prints the one line summaries of the releases, it is the default
+const FormatText = "text"
+
+// StdoutAnnouncer is the announcer that prints the releases to the standard output, the logs are written to the
+// standard error so the output can be piped into the scripts
+type StdoutAnnouncer struct {
+	// Format is one of text, json and cloudevents
+	Format string
+	out    io.Writer
+	mu     sync.Mutex
+}
+
+// NewStdoutAnnouncer creates a new StdoutAnnouncer. format is one of text, json and cloudevents, defaults to text.
+func NewStdoutAnnouncer(format string) (*StdoutAnnouncer, error) {
+	format = strings.ToLower(format)
+	switch format {
+	case "":
+		format = FormatText
+	case FormatText, string(announce.EventFormatJSON), string(announce.EventFormatCloudEvents):
+	default:
+		return nil, fmt.Errorf("unsupported stdout format %q", format)
+	}
+
+	return &StdoutAnnouncer{
+		Format: format,
+		out:    os.Stdout,
+	}, nil
+}
+
+// Notify prints the release as a single line.
+func (s *StdoutAnnouncer) Notify(payload *announce.AnnouncerPayload) error {
+	line := payload.Summary()
+	if s.Format != FormatText {
+		event, err := announce.EncodeEvent(announce.EventFormat(s.Format), payload)
+		if err != nil {
+			return err
+		}
+
+		line = string(event.Body)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := fmt.Fprintln(s.out, line)
+	return err
+}
+
+// IsEnabled checks if the StdoutAnnouncer is enabled.
+func (s *StdoutAnnouncer) IsEnabled() bool {
+	return true
+}
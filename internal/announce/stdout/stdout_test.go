@@ -0,0 +1,49 @@
+//go:build unit
+
+package stdout
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStdoutAnnouncer_Notify(t *testing.T) {
+	cases := []struct {
+		caseName   string
+		format     string
+		expected   []string
+		shouldPass bool
+	}{
+		{"Default text", "", []string{"user1/project1 v1.0.0 is out! Check it out at https://example.com\n"}, true},
+		{"JSON", "JSON", []string{`"schemaVersion":"1"`, `"version":"v1.0.0"`}, true},
+		{"CloudEvents", "cloudevents", []string{`"specversion":"1.0"`, `"type":"release.published"`}, true},
+		{"Binary CloudEvents", "cloudevents-binary", nil, false},
+		{"Unknown", "yaml", nil, false},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		announcer, err := NewStdoutAnnouncer(tc.format)
+		if !tc.shouldPass {
+			assert.NotNil(t, err)
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.True(t, announcer.IsEnabled())
+
+		var out bytes.Buffer
+		announcer.out = &out
+
+		assert.Nil(t, announcer.Notify(&announce.AnnouncerPayload{ProjectName: "user1/project1", Version: "v1.0.0",
+			URL: "https://example.com"}))
+		assert.Equal(t, 1, bytes.Count(out.Bytes(), []byte("\n")))
+		for _, expected := range tc.expected {
+			assert.Contains(t, out.String(), expected)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package teams
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/notes"
+)
+
+const (
+	// maxExcerptLength is the maximum length of the release notes excerpt on the card
+	maxExcerptLength = 1500
+
+	adaptiveCardContentType = "application/vnd.microsoft.card.adaptive"
+	adaptiveCardSchema      = "http://adaptivecards.io/schemas/adaptive-card.json"
+	adaptiveCardVersion     = "1.4"
+)
+
+// message is the envelope accepted by both the incoming webhooks and the Workflows webhooks of Teams
+type message struct {
+	Type        string       `json:"type"`
+	Attachments []attachment `json:"attachments"`
+}
+
+type attachment struct {
+	ContentType string       `json:"contentType"`
+	ContentUrl  *string      `json:"contentUrl"`
+	Content     adaptiveCard `json:"content"`
+}
+
+type adaptiveCard struct {
+	Schema  string           `json:"$schema"`
+	Type    string           `json:"type"`
+	Version string           `json:"version"`
+	Body    []map[string]any `json:"body"`
+	Actions []map[string]any `json:"actions,omitempty"`
+}
+
+type fact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+// TeamsAnnouncer is the announcer that posts Adaptive Cards to Microsoft Teams incoming webhook or Workflows urls
+type TeamsAnnouncer struct {
+	WebhookUrl string
+	client     *http.Client
+}
+
+// NewTeamsAnnouncer creates a new TeamsAnnouncer
+func NewTeamsAnnouncer(webhookUrl string) (*TeamsAnnouncer, error) {
+	if webhookUrl == "" {
+		return nil, errors.New("teams webhook url is required")
+	}
+
+	return &TeamsAnnouncer{
+		WebhookUrl: webhookUrl,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Notify posts the card to the webhook. Webhooks are bound to a channel, so routed payloads override the webhook
+// urls with their recipients.
+func (t *TeamsAnnouncer) Notify(payload *announce.AnnouncerPayload) error {
+	body, err := json.Marshal(buildMessage(payload))
+	if err != nil {
+		return err
+	}
+
+	urls := payload.Recipients
+	if len(urls) == 0 {
+		urls = []string{t.WebhookUrl}
+	}
+
+	var errs []error
+	for _, url := range urls {
+		if err := t.post(url, body); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// IsEnabled checks if the TeamsAnnouncer is enabled.
+func (t *TeamsAnnouncer) IsEnabled() bool {
+	return t.WebhookUrl != ""
+}
+
+func (t *TeamsAnnouncer) post(url string, body []byte) error {
+	resp, err := t.client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		// the webhook url contains the signature, so the error is not wrapped to avoid leaking it to the logs
+		return errors.New("an error occurred while calling teams webhook")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("teams webhook returned status code %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	return nil
+}
+
+func buildMessage(payload *announce.AnnouncerPayload) *message {
+	titleColor := "Default"
+	if payload.IsUrgent() {
+		titleColor = "Attention"
+	}
+
+	facts := []fact{
+		{Title: "Project", Value: payload.ProjectName},
+		{Title: "Version", Value: payload.Version},
+		{Title: "Event", Value: string(payload.GetEvent())},
+	}
+
+	if payload.PublishedAt != nil {
+		facts = append(facts, fact{Title: "Published", Value: payload.PublishedAt.UTC().Format(time.RFC1123)})
+	}
+
+	if len(payload.Changes) > 0 {
+		facts = append(facts, fact{Title: "Changes", Value: strings.Join(payload.Changes, ", ")})
+	}
+
+	if advisories := payload.Advisories(); len(advisories) > 0 {
+		facts = append(facts, fact{Title: "Advisories", Value: strings.Join(advisories, ", ")})
+	}
+
+	body := []map[string]any{
+		{"type": "TextBlock", "text": payload.Summary(), "size": "Large", "weight": "Bolder", "color": titleColor, "wrap": true},
+	}
+
+	// the digests list the releases in their summary, the facts are only meaningful for a single release
+	if !payload.IsDigest() {
+		body = append(body, map[string]any{"type": "FactSet", "facts": facts})
+	}
+
+	if breakingChanges := notes.ToMarkdown(payload.BreakingChanges); breakingChanges != "" {
+		body = append(body,
+			map[string]any{"type": "TextBlock", "text": "⚠️ Breaking changes", "weight": "Bolder", "color": "Warning", "wrap": true},
+			map[string]any{"type": "TextBlock", "text": notes.Truncate(breakingChanges, maxExcerptLength), "wrap": true},
+		)
+	}
+
+	if releaseNotes := notes.ToMarkdown(payload.Notes); releaseNotes != "" {
+		body = append(body,
+			map[string]any{"type": "TextBlock", "text": "Release notes", "weight": "Bolder", "wrap": true, "separator": true},
+			map[string]any{"type": "TextBlock", "text": notes.Truncate(releaseNotes, maxExcerptLength), "wrap": true},
+		)
+	}
+
+	card := adaptiveCard{
+		Schema:  adaptiveCardSchema,
+		Type:    "AdaptiveCard",
+		Version: adaptiveCardVersion,
+		Body:    body,
+	}
+
+	if payload.URL != "" {
+		card.Actions = []map[string]any{{"type": "Action.OpenUrl", "title": "Open release", "url": payload.URL}}
+	}
+
+	return &message{
+		Type:        "message",
+		Attachments: []attachment{{ContentType: adaptiveCardContentType, Content: card}},
+	}
+}
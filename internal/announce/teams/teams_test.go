@@ -0,0 +1,131 @@
+//go:build unit
+
+package teams
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeWebhook records the posted messages and responds with the given status code
+type fakeWebhook struct {
+	mu       sync.Mutex
+	paths    []string
+	messages []map[string]any
+}
+
+func newFakeWebhook(t *testing.T, status int) (*fakeWebhook, string) {
+	webhook := &fakeWebhook{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&msg)
+
+		webhook.mu.Lock()
+		webhook.paths = append(webhook.paths, r.URL.Path)
+		webhook.messages = append(webhook.messages, msg)
+		webhook.mu.Unlock()
+
+		w.WriteHeader(status)
+		if status >= 300 {
+			_, _ = w.Write([]byte("Webhook message delivery failed"))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return webhook, server.URL
+}
+
+func TestNewTeamsAnnouncer(t *testing.T) {
+	announcer, err := NewTeamsAnnouncer("")
+	assert.Nil(t, announcer)
+	assert.NotNil(t, err)
+
+	announcer, err = NewTeamsAnnouncer("https://example.webhook.office.com/webhookb2/x")
+	assert.Nil(t, err)
+	assert.True(t, announcer.IsEnabled())
+}
+
+func TestTeamsAnnouncer_Notify(t *testing.T) {
+	cases := []struct {
+		caseName      string
+		status        int
+		recipients    []string
+		expectedPaths []string
+		shouldPass    bool
+	}{
+		{"Incoming webhook", http.StatusOK, nil, []string{"/default"}, true},
+		{"Workflows webhook", http.StatusAccepted, nil, []string{"/default"}, true},
+		{"Routed to other webhooks", http.StatusOK, []string{"/security", "/oncall"}, []string{"/security", "/oncall"}, true},
+		{"Failed delivery", http.StatusBadRequest, nil, []string{"/default"}, false},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		webhook, url := newFakeWebhook(t, tc.status)
+		announcer, err := NewTeamsAnnouncer(url + "/default")
+		assert.Nil(t, err)
+
+		var recipients []string
+		for _, recipient := range tc.recipients {
+			recipients = append(recipients, url+recipient)
+		}
+
+		err = announcer.Notify(&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", URL: "https://example.com", Recipients: recipients})
+		if tc.shouldPass {
+			assert.Nil(t, err)
+		} else {
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), "delivery failed")
+		}
+
+		assert.Equal(t, tc.expectedPaths, webhook.paths)
+		assert.Equal(t, "message", webhook.messages[0]["type"])
+	}
+}
+
+func TestBuildMessage(t *testing.T) {
+	publishedAt := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+	msg := buildMessage(&announce.AnnouncerPayload{
+		ProjectName:     "x-project",
+		Version:         "v2.0.0",
+		URL:             "https://example.com",
+		PublishedAt:     &publishedAt,
+		Notes:           "<p>Fixes <strong>bug</strong></p>",
+		BreakingChanges: "<ul><li>Removed foo</li></ul>",
+		Security:        &types.Security{CVEs: []string{"CVE-2023-1234"}},
+		Priority:        announce.PriorityHigh,
+	})
+
+	assert.Len(t, msg.Attachments, 1)
+	assert.Equal(t, adaptiveCardContentType, msg.Attachments[0].ContentType)
+
+	card := msg.Attachments[0].Content
+	assert.Equal(t, "AdaptiveCard", card.Type)
+	assert.Equal(t, []map[string]any{{"type": "Action.OpenUrl", "title": "Open release", "url": "https://example.com"}}, card.Actions)
+	assert.Len(t, card.Body, 6)
+	assert.Equal(t, "Attention", card.Body[0]["color"])
+	assert.Equal(t, []fact{
+		{Title: "Project", Value: "x-project"},
+		{Title: "Version", Value: "v2.0.0"},
+		{Title: "Event", Value: "new"},
+		{Title: "Published", Value: "Mon, 01 May 2023 10:00:00 UTC"},
+		{Title: "Advisories", Value: "CVE-2023-1234"},
+	}, card.Body[1]["facts"])
+	assert.Equal(t, "- Removed foo", card.Body[3]["text"])
+	assert.Equal(t, "Fixes **bug**", card.Body[5]["text"])
+
+	msg = buildMessage(&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0"})
+	card = msg.Attachments[0].Content
+	assert.Len(t, card.Body, 2)
+	assert.Nil(t, card.Actions)
+	assert.Equal(t, "Default", card.Body[0]["color"])
+}
@@ -0,0 +1,214 @@
+package telegram
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/notes"
+)
+
+const (
+	// ParseModeHTML formats the messages with the HTML subset supported by Telegram, it is the default
+	ParseModeHTML = "HTML"
+	// ParseModeMarkdownV2 formats the messages with the MarkdownV2 format of Telegram
+	ParseModeMarkdownV2 = "MarkdownV2"
+
+	defaultApiUrl = "https://api.telegram.org"
+	// maxMessageLength is the maximum length of a Telegram message
+	maxMessageLength = 4096
+)
+
+// Chat is a Telegram chat to send the messages to, ThreadId is the topic of the forum supergroups and optional
+type Chat struct {
+	Id       string
+	ThreadId int
+}
+
+// sendMessageRequest is the request body of the sendMessage method of the Bot API
+type sendMessageRequest struct {
+	ChatId                string `json:"chat_id"`
+	MessageThreadId       int    `json:"message_thread_id,omitempty"`
+	Text                  string `json:"text"`
+	ParseMode             string `json:"parse_mode"`
+	DisableWebPagePreview bool   `json:"disable_web_page_preview"`
+}
+
+// apiResponse is the common response body of the Bot API methods
+type apiResponse struct {
+	Ok          bool   `json:"ok"`
+	ErrorCode   int    `json:"error_code"`
+	Description string `json:"description"`
+}
+
+// TelegramAnnouncer is the announcer that sends the messages to the Telegram chats over the Bot API
+type TelegramAnnouncer struct {
+	ApiUrl    string
+	BotToken  string
+	ParseMode string
+	Chats     []Chat
+	client    *http.Client
+}
+
+// NewTelegramAnnouncer creates a new TelegramAnnouncer. apiUrl defaults to the public Bot API and parseMode is one of
+// HTML and MarkdownV2, defaults to HTML.
+func NewTelegramAnnouncer(apiUrl, botToken, parseMode string, chats []Chat) (*TelegramAnnouncer, error) {
+	if botToken == "" || len(chats) == 0 {
+		return nil, errors.New("telegram bot token and at least one chat are required")
+	}
+
+	if apiUrl == "" {
+		apiUrl = defaultApiUrl
+	}
+
+	switch {
+	case parseMode == "" || strings.EqualFold(parseMode, ParseModeHTML):
+		parseMode = ParseModeHTML
+	case strings.EqualFold(parseMode, ParseModeMarkdownV2):
+		parseMode = ParseModeMarkdownV2
+	default:
+		return nil, fmt.Errorf("unknown telegram parse mode %q", parseMode)
+	}
+
+	return &TelegramAnnouncer{
+		ApiUrl:    strings.TrimSuffix(apiUrl, "/"),
+		BotToken:  botToken,
+		ParseMode: parseMode,
+		Chats:     chats,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Notify sends the message to all chats, routed payloads override the chats with "chatId" or "chatId:threadId" items.
+// It tries every chat even if some of them fail and returns the joined errors.
+func (t *TelegramAnnouncer) Notify(payload *announce.AnnouncerPayload) error {
+	text := t.format(payload)
+
+	var errs []error
+	for _, chat := range t.chatsOf(payload) {
+		if err := t.sendMessage(&sendMessageRequest{
+			ChatId:                chat.Id,
+			MessageThreadId:       chat.ThreadId,
+			Text:                  text,
+			ParseMode:             t.ParseMode,
+			DisableWebPagePreview: true,
+		}); err != nil {
+			errs = append(errs, fmt.Errorf("chat %s: %w", chat.Id, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// IsEnabled checks if the TelegramAnnouncer is enabled.
+func (t *TelegramAnnouncer) IsEnabled() bool {
+	return t.BotToken != "" && len(t.Chats) > 0
+}
+
+func (t *TelegramAnnouncer) chatsOf(payload *announce.AnnouncerPayload) []Chat {
+	if len(payload.Recipients) == 0 {
+		return t.Chats
+	}
+
+	var chats []Chat
+	for _, recipient := range payload.Recipients {
+		id, thread, _ := strings.Cut(recipient, ":")
+		threadId, _ := strconv.Atoi(thread)
+		chats = append(chats, Chat{Id: id, ThreadId: threadId})
+	}
+
+	return chats
+}
+
+func (t *TelegramAnnouncer) sendMessage(request *sendMessageRequest) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	resp, err := t.client.Post(fmt.Sprintf("%s/bot%s/sendMessage", t.ApiUrl, t.BotToken), "application/json", bytes.NewReader(body))
+	if err != nil {
+		// the url contains the bot token, so the error is not wrapped to avoid leaking it to the logs
+		return errors.New("an error occurred while calling telegram bot api")
+	}
+	defer resp.Body.Close()
+
+	var response apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("unexpected response from telegram bot api with status code %d", resp.StatusCode)
+	}
+
+	if !response.Ok {
+		return fmt.Errorf("telegram bot api returned error %d: %s", response.ErrorCode, response.Description)
+	}
+
+	return nil
+}
+
+// format creates the message text in the configured parse mode, the release notes are sent as plain text if they do
+// not fit into a single message
+func (t *TelegramAnnouncer) format(payload *announce.AnnouncerPayload) string {
+	escape, convert := html.EscapeString, notes.ToTelegramHTML
+	bold := func(s string) string { return "<b>" + s + "</b>" }
+	if t.ParseMode == ParseModeMarkdownV2 {
+		escape, convert = notes.EscapeTelegramMarkdown, notes.ToTelegramMarkdown
+		bold = func(s string) string { return "*" + s + "*" }
+	}
+
+	text := escape(payload.Summary())
+	if payload.IsUrgent() {
+		text = "🚨 " + text
+	}
+
+	blocks := []struct {
+		title   string
+		content string
+	}{
+		{"⚠️ Breaking changes", payload.BreakingChanges},
+		{"Release notes", payload.Notes},
+	}
+
+	for _, block := range blocks {
+		if strings.TrimSpace(block.content) == "" {
+			continue
+		}
+
+		title := "\n\n" + bold(escape(block.title)) + "\n"
+		remaining := maxMessageLength - utf8.RuneCountInString(text) - utf8.RuneCountInString(title)
+		if remaining <= 0 {
+			break
+		}
+
+		content := convert(block.content)
+		if utf8.RuneCountInString(content) > remaining {
+			content = fitPlain(notes.ToText(block.content), remaining, escape)
+		}
+
+		text += title + content
+	}
+
+	return text
+}
+
+// fitPlain truncates the plain text so that it fits into the limit after escaping
+func fitPlain(text string, limit int, escape func(string) string) string {
+	for size := limit; size > 0; {
+		escaped := escape(notes.Truncate(text, size))
+		overflow := utf8.RuneCountInString(escaped) - limit
+		if overflow <= 0 {
+			return escaped
+		}
+
+		size -= overflow
+	}
+
+	return ""
+}
@@ -0,0 +1,164 @@
+//go:build unit
+
+package telegram
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeBotApi is a minimal Bot API server which records the sendMessage requests
+type fakeBotApi struct {
+	mu       sync.Mutex
+	paths    []string
+	requests []sendMessageRequest
+	failChat string
+}
+
+func newFakeBotApi(t *testing.T, failChat string) (*fakeBotApi, string) {
+	api := &fakeBotApi{failChat: failChat}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request sendMessageRequest
+		_ = json.NewDecoder(r.Body).Decode(&request)
+
+		api.mu.Lock()
+		api.paths = append(api.paths, r.URL.Path)
+		api.requests = append(api.requests, request)
+		api.mu.Unlock()
+
+		if request.ChatId == api.failChat {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: chat not found"}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"ok":true,"result":{}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return api, server.URL
+}
+
+func TestNewTelegramAnnouncer(t *testing.T) {
+	cases := []struct {
+		caseName          string
+		botToken          string
+		parseMode         string
+		chats             []Chat
+		expectedParseMode string
+		shouldPass        bool
+	}{
+		{"Defaults to HTML", "token", "", []Chat{{Id: "1"}}, ParseModeHTML, true},
+		{"MarkdownV2 case insensitive", "token", "markdownv2", []Chat{{Id: "1"}}, ParseModeMarkdownV2, true},
+		{"Unknown parse mode", "token", "Markdown", []Chat{{Id: "1"}}, "", false},
+		{"Missing token", "", "", []Chat{{Id: "1"}}, "", false},
+		{"Missing chats", "token", "", nil, "", false},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		announcer, err := NewTelegramAnnouncer("", tc.botToken, tc.parseMode, tc.chats)
+		if !tc.shouldPass {
+			assert.NotNil(t, err)
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.Equal(t, tc.expectedParseMode, announcer.ParseMode)
+		assert.Equal(t, defaultApiUrl, announcer.ApiUrl)
+		assert.True(t, announcer.IsEnabled())
+	}
+}
+
+func TestTelegramAnnouncer_Notify(t *testing.T) {
+	cases := []struct {
+		caseName        string
+		parseMode       string
+		failChat        string
+		payload         *announce.AnnouncerPayload
+		expectedChats   []string
+		expectedThreads []int
+		expectedText    string
+		shouldPass      bool
+	}{
+		{
+			"HTML with notes and breaking changes", ParseModeHTML, "",
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", URL: "https://example.com",
+				Notes:           "<p>Fixes <code>a&lt;b</code></p>",
+				BreakingChanges: "<ul><li>Removed <strong>foo</strong></li></ul>"},
+			[]string{"-100", "-200"}, []int{0, 42},
+			"x-project v1.0.0 is out! Check it out at https://example.com\n\n<b>⚠️ Breaking changes</b>\n• Removed <b>foo</b>" +
+				"\n\n<b>Release notes</b>\nFixes <code>a&lt;b</code>",
+			true,
+		},
+		{
+			"MarkdownV2 urgent routed payload", ParseModeMarkdownV2, "",
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.1", URL: "https://example.com",
+				Priority: announce.PriorityHigh, Recipients: []string{"-300:7"}},
+			[]string{"-300"}, []int{7},
+			"🚨 x\\-project v1\\.0\\.1 is out\\! Check it out at https://example\\.com",
+			true,
+		},
+		{
+			"Failing chat does not stop the others", ParseModeHTML, "-100",
+			&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", URL: "https://example.com"},
+			[]string{"-100", "-200"}, []int{0, 42},
+			"x-project v1.0.0 is out! Check it out at https://example.com",
+			false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		api, url := newFakeBotApi(t, tc.failChat)
+		announcer, err := NewTelegramAnnouncer(url, "123:abc", tc.parseMode, []Chat{{Id: "-100"}, {Id: "-200", ThreadId: 42}})
+		assert.Nil(t, err)
+
+		err = announcer.Notify(tc.payload)
+		if tc.shouldPass {
+			assert.Nil(t, err)
+		} else {
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), "chat not found")
+			assert.NotContains(t, err.Error(), "123:abc")
+		}
+
+		assert.Len(t, api.requests, len(tc.expectedChats))
+		for i, request := range api.requests {
+			assert.Equal(t, "/bot123:abc/sendMessage", api.paths[i])
+			assert.Equal(t, tc.expectedChats[i], request.ChatId)
+			assert.Equal(t, tc.expectedThreads[i], request.MessageThreadId)
+			assert.Equal(t, tc.parseMode, request.ParseMode)
+			assert.Equal(t, tc.expectedText, request.Text)
+			assert.True(t, request.DisableWebPagePreview)
+		}
+	}
+}
+
+func TestTelegramAnnouncer_formatLongNotes(t *testing.T) {
+	long := "<p>" + strings.Repeat("<b>a.b</b> ", 2000) + "</p>"
+
+	for _, parseMode := range []string{ParseModeHTML, ParseModeMarkdownV2} {
+		t.Logf("starting case %s", parseMode)
+
+		announcer, err := NewTelegramAnnouncer("", "token", parseMode, []Chat{{Id: "1"}})
+		assert.Nil(t, err)
+
+		text := announcer.format(&announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", URL: "https://example.com", Notes: long})
+		assert.LessOrEqual(t, utf8.RuneCountInString(text), maxMessageLength)
+		assert.True(t, strings.HasSuffix(text, "…"))
+		// the notes are sent as plain text, so no formatting entity is cut in half
+		assert.NotContains(t, text, "<b>a")
+		assert.NotContains(t, text, "*a")
+	}
+}
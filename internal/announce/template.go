@@ -0,0 +1,337 @@
+package announce
+
+import (
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/notes"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/types"
+)
+
+// TemplateFuncs are the helper functions available to the user supplied templates of the announcers
+var TemplateFuncs = template.FuncMap{
+	"json":     MarshalJSON,
+	"text":     notes.ToText,
+	"markdown": notes.ToMarkdown,
+	"truncate": func(limit int, text string) string {
+		return notes.Truncate(text, limit)
+	},
+	"join": func(separator string, items []string) string {
+		return strings.Join(items, separator)
+	},
+	"date":   formatDate,
+	"semver": ParseSemver,
+}
+
+// semverRegex matches the semantic versions with an optional v prefix
+var semverRegex = regexp.MustCompile(`^v?(\d+)\.(\d+)\.(\d+)(?:-([0-9A-Za-z.-]+))?(?:\+([0-9A-Za-z.-]+))?$`)
+
+// Semver is the parts of a semantic version, available to the templates with the semver helper function
+type Semver struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string
+	Metadata   string
+}
+
+// ParseSemver splits the semantic version into its parts, it fails if the version is not semantic
+func ParseSemver(version string) (*Semver, error) {
+	matches := semverRegex.FindStringSubmatch(version)
+	if matches == nil {
+		return nil, fmt.Errorf("version %q is not a semantic version", version)
+	}
+
+	parts := make([]int, 3)
+	for i := range parts {
+		part, err := strconv.Atoi(matches[i+1])
+		if err != nil {
+			return nil, fmt.Errorf("version %q is not a semantic version: %w", version, err)
+		}
+
+		parts[i] = part
+	}
+
+	return &Semver{
+		Major:      parts[0],
+		Minor:      parts[1],
+		Patch:      parts[2],
+		Prerelease: matches[4],
+		Metadata:   matches[5],
+	}, nil
+}
+
+// formatDate formats the time with the Go layout in UTC, nil times like the missing publish dates are formatted as
+// the empty string
+func formatDate(layout string, t any) (string, error) {
+	switch value := t.(type) {
+	case nil:
+		return "", nil
+	case *time.Time:
+		if value == nil {
+			return "", nil
+		}
+
+		return value.UTC().Format(layout), nil
+	case time.Time:
+		return value.UTC().Format(layout), nil
+	default:
+		return "", fmt.Errorf("can not format %T as a date", t)
+	}
+}
+
+// MarshalJSON encodes the value without escaping HTML, it is the encoding of the release events sent by the announcers
+func MarshalJSON(v any) (string, error) {
+	var b strings.Builder
+	encoder := json.NewEncoder(&b)
+	// the release notes are HTML, escaping them would make the output harder to read for no benefit
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// TemplateData is the data passed to the user supplied templates of the announcers
+type TemplateData struct {
+	ProjectName     string          `json:"projectName"`
+	Version         string          `json:"version"`
+	URL             string          `json:"url"`
+	PublishedAt     *time.Time      `json:"publishedAt,omitempty"`
+	UpdatedAt       *time.Time      `json:"updatedAt,omitempty"`
+	Event           types.EventType `json:"event"`
+	Changes         []string        `json:"changes,omitempty"`
+	Summary         string          `json:"summary"`
+	Notes           string          `json:"notes,omitempty"`
+	BreakingChanges string          `json:"breakingChanges,omitempty"`
+	Security        *types.Security `json:"security,omitempty"`
+	Priority        Priority        `json:"priority"`
+	// Level is one of security, breaking, high and normal
+	Level string `json:"level"`
+	// Digest is the releases of the digest grouped by project, it is empty if the payload is not a digest
+	Digest []*DigestGroup `json:"digest,omitempty"`
+}
+
+// NewTemplateData creates the template data of the payload
+func NewTemplateData(payload *AnnouncerPayload) *TemplateData {
+	priority := payload.Priority
+	if priority == "" {
+		priority = PriorityNormal
+	}
+
+	return &TemplateData{
+		ProjectName:     payload.ProjectName,
+		Version:         payload.Version,
+		URL:             payload.URL,
+		PublishedAt:     payload.PublishedAt,
+		UpdatedAt:       payload.UpdatedAt,
+		Event:           payload.GetEvent(),
+		Changes:         payload.Changes,
+		Summary:         payload.Summary(),
+		Notes:           payload.Notes,
+		BreakingChanges: payload.BreakingChanges,
+		Security:        payload.Security,
+		Priority:        priority,
+		Level:           payload.Level(),
+		Digest:          digestGroups(payload.Digest),
+	}
+}
+
+// digestGroups creates the template data of the digest releases grouped by project
+func digestGroups(releases []*AnnouncerPayload) []*DigestGroup {
+	var groups []*DigestGroup
+	for _, group := range groupByProject(releases) {
+		digestGroup := &DigestGroup{ProjectName: group[0].ProjectName}
+		for _, release := range group {
+			digestGroup.Releases = append(digestGroup.Releases, NewTemplateData(release))
+		}
+
+		groups = append(groups, digestGroup)
+	}
+
+	return groups
+}
+
+// sampleTemplateData is the data the templates are validated with, all fields are set so the templates referencing
+// unknown fields or calling the helper functions with the wrong arguments fail at the startup
+var sampleTemplateData = func() *TemplateData {
+	publishedAt := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	return NewTemplateData(&AnnouncerPayload{
+		ProjectName:     "user1/project1",
+		Version:         "v1.2.3",
+		URL:             "https://github.com/user1/project1/releases/tag/v1.2.3",
+		PublishedAt:     &publishedAt,
+		UpdatedAt:       &publishedAt,
+		Event:           types.EventUpdated,
+		Changes:         []string{"notes"},
+		Notes:           "<p>release notes</p>",
+		BreakingChanges: "<p>breaking changes</p>",
+		Security:        &types.Security{CVEs: []string{"CVE-2023-12345"}},
+		Priority:        PriorityHigh,
+	})
+}()
+
+// ParseTemplate parses the user supplied template with the TemplateFuncs and validates it by executing it with a
+// sample release, referencing an unknown field fails
+func ParseTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(TemplateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := Render(tmpl, sampleTemplateData); err != nil {
+		return nil, err
+	}
+
+	return tmpl, nil
+}
+
+// ParseHTMLTemplate parses the user supplied html/template with the TemplateFuncs and htmlNotes, which converts the
+// HTML release notes into the sanitized HTML, and validates it like ParseTemplate
+func ParseHTMLTemplate(name, text string) (*htmltemplate.Template, error) {
+	funcs := htmltemplate.FuncMap{
+		"htmlNotes": func(text string) htmltemplate.HTML {
+			// ToHTML escapes the text and keeps only the formatting elements and the safe links
+			return htmltemplate.HTML(notes.ToHTML(text))
+		},
+	}
+
+	for name, fn := range TemplateFuncs {
+		funcs[name] = fn
+	}
+
+	tmpl, err := htmltemplate.New(name).Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tmpl.Execute(io.Discard, sampleTemplateData); err != nil {
+		return nil, err
+	}
+
+	return tmpl, nil
+}
+
+// Render executes the template with the data and returns the output
+func Render(tmpl *template.Template, data *TemplateData) (string, error) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+// Templates customize the messages of an announcer, nil templates keep the default messages
+type Templates struct {
+	// Message replaces the summary of the payloads
+	Message *template.Template
+	// Subject replaces the email subject and the notification titles of the payloads
+	Subject *template.Template
+	// Overrides replace the templates for the releases of their repositories, first matching override wins
+	Overrides []TemplateOverride
+}
+
+// TemplateOverride replaces the templates of an announcer for the releases of the given repositories
+type TemplateOverride struct {
+	Repositories []string
+	Message      *template.Template
+	Subject      *template.Template
+}
+
+// TemplateAnnouncer wraps an Announcer and renders its Templates into the payloads
+type TemplateAnnouncer struct {
+	Announcer
+	Templates
+}
+
+// NewTemplateAnnouncer creates a new TemplateAnnouncer which wraps the given announcer
+func NewTemplateAnnouncer(announcer Announcer, templates Templates) *TemplateAnnouncer {
+	return &TemplateAnnouncer{
+		Announcer: announcer,
+		Templates: templates,
+	}
+}
+
+// Notify renders the templates matching the payload and passes the rendered payload to the wrapped announcer, the
+// message templates of the digests are rendered for each of their releases
+func (t *TemplateAnnouncer) Notify(payload *AnnouncerPayload) error {
+	if !payload.IsDigest() {
+		rendered, err := t.render(payload)
+		if err != nil {
+			return err
+		}
+
+		return t.Announcer.Notify(rendered)
+	}
+
+	digest := *payload
+	digest.Digest = make([]*AnnouncerPayload, 0, len(payload.Digest))
+	for _, release := range payload.Digest {
+		rendered, err := t.render(release)
+		if err != nil {
+			return err
+		}
+
+		digest.Digest = append(digest.Digest, rendered)
+	}
+
+	digest.Message = DigestMessage(payload.Subject, digest.Digest)
+	return t.Announcer.Notify(&digest)
+}
+
+// Unwrap returns the wrapped announcer
+func (t *TemplateAnnouncer) Unwrap() Announcer {
+	return t.Announcer
+}
+
+// render returns a copy of the payload with the templates matching its repository rendered
+func (t *TemplateAnnouncer) render(payload *AnnouncerPayload) (*AnnouncerPayload, error) {
+	message, subject := t.Message, t.Subject
+	for _, override := range t.Overrides {
+		if !contains(override.Repositories, payload.ProjectName) {
+			continue
+		}
+
+		if override.Message != nil {
+			message = override.Message
+		}
+
+		if override.Subject != nil {
+			subject = override.Subject
+		}
+
+		break
+	}
+
+	rendered := *payload
+	data := NewTemplateData(payload)
+	if message != nil {
+		text, err := Render(message, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render the message template: %w", err)
+		}
+
+		rendered.Message = strings.TrimSpace(text)
+	}
+
+	if subject != nil {
+		text, err := Render(subject, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render the subject template: %w", err)
+		}
+
+		// the subjects and the titles are single line
+		rendered.Subject = strings.Join(strings.Fields(text), " ")
+	}
+
+	return &rendered, nil
+}
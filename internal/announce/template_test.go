@@ -0,0 +1,112 @@
+//go:build unit
+
+package announce
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSemver(t *testing.T) {
+	cases := []struct {
+		caseName   string
+		version    string
+		expected   *Semver
+		shouldPass bool
+	}{
+		{"With prefix", "v1.2.3", &Semver{Major: 1, Minor: 2, Patch: 3}, true},
+		{"Without prefix", "10.0.1", &Semver{Major: 10, Minor: 0, Patch: 1}, true},
+		{"Prerelease and metadata", "v2.0.0-rc.1+build.5", &Semver{Major: 2, Prerelease: "rc.1", Metadata: "build.5"}, true},
+		{"Missing patch", "v1.2", nil, false},
+		{"Not a version", "nightly", nil, false},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		semver, err := ParseSemver(tc.version)
+		if !tc.shouldPass {
+			assert.NotNil(t, err)
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.Equal(t, tc.expected, semver)
+	}
+}
+
+func TestParseTemplate(t *testing.T) {
+	publishedAt := time.Date(2023, 10, 1, 12, 0, 0, 0, time.FixedZone("UTC+3", 3*60*60))
+	payload := &AnnouncerPayload{ProjectName: "user1/project1", Version: "v1.4.0-beta.1", URL: "https://example.com",
+		PublishedAt: &publishedAt, Changes: []string{"url", "notes"}, Notes: "<p>Fixes <strong>the bug</strong></p>"}
+
+	cases := []struct {
+		caseName   string
+		template   string
+		expected   string
+		shouldPass bool
+	}{
+		{"Semver parts", "{{ with semver .Version }}{{ .Major }}.{{ .Minor }}/{{ .Prerelease }}{{ end }}", "1.4/beta.1", true},
+		{"Date", `{{ date "2006-01-02 15:04" .PublishedAt }}`, "2023-10-01 09:00", true},
+		{"Missing date", `{{ date "2006-01-02" .UpdatedAt }}`, "", true},
+		{"Truncate and markdown", "{{ markdown .Notes | truncate 12 }}", "Fixes **the…", true},
+		{"Join", `{{ join ", " .Changes }}`, "url, notes", true},
+		{"Level", "{{ .Level }}", LevelNormal, true},
+		{"Syntax error", "{{ .Version ", "", false},
+		{"Unknown field", "{{ .Tag }}", "", false},
+		{"Unknown function", "{{ upper .Version }}", "", false},
+		{"Wrong argument", `{{ date "2006" .Version }}`, "", false},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		tmpl, err := ParseTemplate("message", tc.template)
+		if !tc.shouldPass {
+			assert.NotNil(t, err)
+			continue
+		}
+
+		assert.Nil(t, err)
+		text, err := Render(tmpl, NewTemplateData(payload))
+		assert.Nil(t, err)
+		assert.Equal(t, tc.expected, text)
+	}
+}
+
+func TestTemplateAnnouncer_Notify(t *testing.T) {
+	message, _ := ParseTemplate("message", "{{ .ProjectName }} {{ .Version }} ({{ .Priority }})\n")
+	subject, _ := ParseTemplate("subject", "Release\n{{ .Version }}")
+	override, _ := ParseTemplate("overrides[0].message", "{{ .Version }} of the platform")
+
+	recorder := &recordingAnnouncer{}
+	announcer := NewTemplateAnnouncer(recorder, Templates{
+		Message:   message,
+		Subject:   subject,
+		Overrides: []TemplateOverride{{Repositories: []string{"user1/platform"}, Message: override}},
+	})
+
+	payload := &AnnouncerPayload{ProjectName: "user1/project1", Version: "v1.0.0", Priority: PriorityHigh}
+	assert.Nil(t, announcer.Notify(payload))
+	assert.Nil(t, announcer.Notify(&AnnouncerPayload{ProjectName: "user1/platform", Version: "v2.0.0",
+		Event: types.EventUpdated}))
+
+	assert.Len(t, recorder.payloads, 2)
+	assert.Equal(t, "user1/project1 v1.0.0 (high)", recorder.payloads[0].Summary())
+	assert.Equal(t, "Release v1.0.0", recorder.payloads[0].Title("default"))
+	assert.Equal(t, "v2.0.0 of the platform", recorder.payloads[1].Summary())
+	assert.Equal(t, "Release v2.0.0", recorder.payloads[1].Title("default"))
+
+	// the payload of the caller is not modified
+	assert.Empty(t, payload.Message)
+	assert.Equal(t, "user1/project1 v1.0.0 is out! Check it out at ", payload.Summary())
+	assert.Equal(t, "default", payload.Title("default"))
+
+	failing, _ := ParseTemplate("message", "{{ (semver .Version).Major }}")
+	announcer = NewTemplateAnnouncer(recorder, Templates{Message: failing})
+	assert.NotNil(t, announcer.Notify(&AnnouncerPayload{ProjectName: "user1/project1", Version: "nightly"}))
+	assert.Len(t, recorder.payloads, 2)
+}
@@ -0,0 +1,172 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+)
+
+const (
+	// SignatureHeader carries the hex encoded HMAC-SHA256 of the body prefixed with "sha256=" if a secret is set
+	SignatureHeader = "X-Signature"
+
+	defaultTimeout = 10 * time.Second
+)
+
+// WebhookAnnouncer is the announcer that sends the releases to any HTTP endpoint with a templated body
+type WebhookAnnouncer struct {
+	Url     string
+	Method  string
+	Headers map[string]string
+	// Format is the encoding of the release event in the body, the body is rendered from the template instead if it
+	// is set
+	Format   announce.EventFormat
+	secret   []byte
+	template *template.Template
+	client   *http.Client
+}
+
+// NewWebhookAnnouncer creates a new WebhookAnnouncer. method defaults to POST, bodyTemplate is a text/template
+// executed with announce.TemplateData, timeout defaults to 10 seconds. The body is signed with HMAC-SHA256 if the
+// secret is set. The body is the release event encoded in the format if bodyTemplate is empty, format defaults to
+// announce.EventFormatJSON and the CloudEvents formats are sent in the structured or binary HTTP mode.
+func NewWebhookAnnouncer(url, method string, headers map[string]string, bodyTemplate, secret string,
+	timeout time.Duration, format announce.EventFormat) (*WebhookAnnouncer, error) {
+	if url == "" {
+		return nil, errors.New("webhook url is required")
+	}
+
+	method = strings.ToUpper(method)
+	switch method {
+	case "":
+		method = http.MethodPost
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+	default:
+		return nil, fmt.Errorf("unsupported webhook method %q", method)
+	}
+
+	switch format {
+	case "":
+		format = announce.EventFormatJSON
+	case announce.EventFormatJSON:
+	case announce.EventFormatCloudEvents, announce.EventFormatCloudEventsBinary:
+		if bodyTemplate != "" {
+			return nil, errors.New("webhook body template can not be used with cloudevents format")
+		}
+	default:
+		return nil, fmt.Errorf("unknown webhook format %q", format)
+	}
+
+	var tmpl *template.Template
+	if bodyTemplate != "" {
+		var err error
+		if tmpl, err = announce.ParseTemplate("webhook", bodyTemplate); err != nil {
+			return nil, fmt.Errorf("invalid webhook body template: %w", err)
+		}
+	}
+
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	return &WebhookAnnouncer{
+		Url:      url,
+		Method:   method,
+		Headers:  headers,
+		Format:   format,
+		secret:   []byte(secret),
+		template: tmpl,
+		client:   &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// Notify renders the body and sends it to the webhook url, routed payloads override the url with their recipients.
+func (w *WebhookAnnouncer) Notify(payload *announce.AnnouncerPayload) error {
+	event, err := w.render(payload)
+	if err != nil {
+		return err
+	}
+
+	urls := payload.Recipients
+	if len(urls) == 0 {
+		urls = []string{w.Url}
+	}
+
+	var errs []error
+	for _, url := range urls {
+		if err := w.send(url, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// IsEnabled checks if the WebhookAnnouncer is enabled.
+func (w *WebhookAnnouncer) IsEnabled() bool {
+	return w.Url != ""
+}
+
+func (w *WebhookAnnouncer) render(payload *announce.AnnouncerPayload) (*announce.EncodedEvent, error) {
+	if w.template == nil {
+		return announce.EncodeEvent(w.Format, payload)
+	}
+
+	var body bytes.Buffer
+	if err := w.template.Execute(&body, announce.NewTemplateData(payload)); err != nil {
+		return nil, fmt.Errorf("failed to render webhook body: %w", err)
+	}
+
+	return &announce.EncodedEvent{ContentType: "application/json", Body: body.Bytes()}, nil
+}
+
+func (w *WebhookAnnouncer) send(url string, event *announce.EncodedEvent) error {
+	req, err := http.NewRequest(w.Method, url, bytes.NewReader(event.Body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", event.ContentType)
+	// the binary mode of the HTTP binding sends the context attributes as the ce- prefixed headers
+	for name, value := range event.Attributes {
+		req.Header.Set("ce-"+name, value)
+	}
+
+	for key, value := range w.Headers {
+		req.Header.Set(key, value)
+	}
+
+	if len(w.secret) > 0 {
+		req.Header.Set(SignatureHeader, Sign(w.secret, event.Body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("an error occurred while calling webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("webhook returned status code %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	return nil
+}
+
+// Sign returns the X-Signature header value of the body, receivers compute it the same way to verify the request
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,211 @@
+//go:build unit
+
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// receivedRequest is a request received by the fake endpoint
+type receivedRequest struct {
+	method string
+	path   string
+	header http.Header
+	body   string
+}
+
+func newFakeEndpoint(t *testing.T, status int, delay time.Duration) (*[]receivedRequest, string) {
+	var requests []receivedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, receivedRequest{method: r.Method, path: r.URL.Path, header: r.Header, body: string(body)})
+
+		time.Sleep(delay)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	return &requests, server.URL
+}
+
+func TestNewWebhookAnnouncer(t *testing.T) {
+	cases := []struct {
+		caseName       string
+		url            string
+		method         string
+		template       string
+		format         announce.EventFormat
+		expectedMethod string
+		shouldPass     bool
+	}{
+		{"Defaults", "https://example.com/hook", "", "", "", http.MethodPost, true},
+		{"Lowercase method", "https://example.com/hook", "put", "", "", http.MethodPut, true},
+		{"CloudEvents", "https://example.com/hook", "", "", announce.EventFormatCloudEventsBinary, http.MethodPost, true},
+		{"Missing url", "", "", "", "", "", false},
+		{"Unsupported method", "https://example.com/hook", "GET", "", "", "", false},
+		{"Invalid template", "https://example.com/hook", "", "{{ .ProjectName ", "", "", false},
+		{"CloudEvents with template", "https://example.com/hook", "", "{{ .Version }}", announce.EventFormatCloudEvents, "", false},
+		{"Unknown format", "https://example.com/hook", "", "", "xml", "", false},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		announcer, err := NewWebhookAnnouncer(tc.url, tc.method, nil, tc.template, "", 0, tc.format)
+		if !tc.shouldPass {
+			assert.NotNil(t, err)
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.Equal(t, tc.expectedMethod, announcer.Method)
+		assert.True(t, announcer.IsEnabled())
+	}
+}
+
+func TestWebhookAnnouncer_Notify(t *testing.T) {
+	publishedAt := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+	payload := &announce.AnnouncerPayload{
+		ProjectName: "x-project",
+		Version:     "v1.0.0",
+		URL:         "https://example.com",
+		PublishedAt: &publishedAt,
+		Notes:       "<p>Fixes <strong>bug</strong></p>",
+		Security:    &types.Security{CVEs: []string{"CVE-2023-1234"}},
+	}
+
+	cases := []struct {
+		caseName        string
+		method          string
+		headers         map[string]string
+		template        string
+		secret          string
+		status          int
+		delay           time.Duration
+		timeout         time.Duration
+		recipients      []string
+		expectedPaths   []string
+		expectedBody    string
+		expectedHeaders map[string]string
+		shouldPass      bool
+	}{
+		{
+			"Default versioned JSON event", "", nil, "", "", http.StatusOK, 0, 0, nil, []string{"/hook"},
+			`{"schemaVersion":"1","projectName":"x-project","version":"v1.0.0","url":"https://example.com","publishedAt":"2023-05-01T10:00:00Z",` +
+				`"event":"new","summary":"x-project v1.0.0 is out! Check it out at https://example.com Security advisories: CVE-2023-1234",` +
+				`"notes":"<p>Fixes <strong>bug</strong></p>","security":{"cves":["CVE-2023-1234"]},"priority":"normal","level":"security"}`,
+			map[string]string{"Content-Type": "application/json", SignatureHeader: ""}, true,
+		},
+		{
+			"Templated signed body with custom headers", "PUT", map[string]string{"authorization": "Bearer token", "content-type": "text/plain"},
+			"{{ .ProjectName }}@{{ .Version }}: {{ text .Notes }}", "secret", http.StatusAccepted, 0, 0, nil, []string{"/hook"},
+			"x-project@v1.0.0: Fixes bug",
+			map[string]string{"Content-Type": "text/plain", "Authorization": "Bearer token"}, true,
+		},
+		{
+			"Routed to other urls", "", nil, "{{ .Version }}", "", http.StatusOK, 0, 0, []string{"/a", "/b"}, []string{"/a", "/b"},
+			"v1.0.0", nil, true,
+		},
+		{
+			"Failing status code", "", nil, "", "", http.StatusInternalServerError, 0, 0, nil, []string{"/hook"}, "", nil, false,
+		},
+		{
+			"Timeout", "", nil, "", "", http.StatusOK, 200 * time.Millisecond, 50 * time.Millisecond, nil, []string{"/hook"}, "", nil, false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		requests, url := newFakeEndpoint(t, tc.status, tc.delay)
+		announcer, err := NewWebhookAnnouncer(url+"/hook", tc.method, tc.headers, tc.template, tc.secret, tc.timeout, "")
+		assert.Nil(t, err)
+
+		routed := *payload
+		for _, recipient := range tc.recipients {
+			routed.Recipients = append(routed.Recipients, url+recipient)
+		}
+
+		err = announcer.Notify(&routed)
+		if !tc.shouldPass {
+			assert.NotNil(t, err)
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.Len(t, *requests, len(tc.expectedPaths))
+		for i, request := range *requests {
+			assert.Equal(t, tc.expectedPaths[i], request.path)
+			assert.Equal(t, announcer.Method, request.method)
+			assert.Equal(t, tc.expectedBody, request.body)
+			for key, value := range tc.expectedHeaders {
+				assert.Equal(t, value, request.header.Get(key))
+			}
+
+			if tc.secret != "" {
+				assert.Equal(t, Sign([]byte(tc.secret), []byte(request.body)), request.header.Get(SignatureHeader))
+			}
+		}
+	}
+}
+
+func TestWebhookAnnouncer_NotifyTemplateError(t *testing.T) {
+	// unknown fields are caught when the template is parsed
+	_, err := NewWebhookAnnouncer("https://example.com/hook", "", nil, "{{ .Unknown }}", "", 0, "")
+	assert.NotNil(t, err)
+
+	announcer, err := NewWebhookAnnouncer("https://example.com/hook", "", nil, "{{ (semver .Version).Major }}", "", 0, "")
+	assert.Nil(t, err)
+
+	err = announcer.Notify(&announce.AnnouncerPayload{ProjectName: "x-project", Version: "nightly"})
+	assert.NotNil(t, err)
+}
+
+func TestWebhookAnnouncer_NotifyCloudEvents(t *testing.T) {
+	payload := &announce.AnnouncerPayload{ProjectName: "x-project", Version: "v1.0.0", URL: "https://example.com",
+		Event: types.EventUpdated, Changes: []string{"url"}}
+
+	requests, url := newFakeEndpoint(t, http.StatusOK, 0)
+	structured, err := NewWebhookAnnouncer(url+"/hook", "", nil, "", "secret", 0, announce.EventFormatCloudEvents)
+	assert.Nil(t, err)
+	binary, err := NewWebhookAnnouncer(url+"/hook", "", nil, "", "", 0, announce.EventFormatCloudEventsBinary)
+	assert.Nil(t, err)
+
+	assert.Nil(t, structured.Notify(payload))
+	assert.Nil(t, binary.Notify(payload))
+	assert.Len(t, *requests, 2)
+
+	// structured mode sends the whole event as the body
+	var event announce.CloudEvent
+	assert.Equal(t, announce.CloudEventsContentType, (*requests)[0].header.Get("Content-Type"))
+	assert.Empty(t, (*requests)[0].header.Get("ce-id"))
+	assert.Nil(t, json.Unmarshal([]byte((*requests)[0].body), &event))
+	assert.Equal(t, announce.CloudEventTypeUpdated, event.Type)
+	assert.Equal(t, []string{"url"}, event.Data.Changes)
+	assert.Equal(t, Sign([]byte("secret"), []byte((*requests)[0].body)), (*requests)[0].header.Get(SignatureHeader))
+
+	// binary mode sends the data as the body and the context attributes as the headers
+	var data announce.CloudEventData
+	assert.Equal(t, "application/json", (*requests)[1].header.Get("Content-Type"))
+	assert.Equal(t, announce.CloudEventsSpecVersion, (*requests)[1].header.Get("ce-specversion"))
+	assert.Equal(t, announce.CloudEventTypeUpdated, (*requests)[1].header.Get("ce-type"))
+	assert.Equal(t, event.Id, (*requests)[1].header.Get("ce-id"))
+	assert.Equal(t, "x-project", (*requests)[1].header.Get("ce-subject"))
+	assert.Nil(t, json.Unmarshal([]byte((*requests)[1].body), &data))
+	assert.Equal(t, *event.Data, data)
+}
+
+func TestSign(t *testing.T) {
+	// https://en.wikipedia.org/wiki/HMAC#Examples
+	assert.Equal(t, "sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8",
+		Sign([]byte("key"), []byte("The quick brown fox jumps over the lazy dog")))
+}
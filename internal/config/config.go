@@ -1,6 +1,8 @@
 package config
 
 import (
+	"sort"
+
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -33,13 +35,41 @@ func ReadConfig(cmd *cobra.Command, path string) (conf *Config, err error) {
 		return nil, errors.Wrap(err, "an error occurred while setting credentials with env variables for storage service")
 	}
 
-	// email access credentials can also be set from env variables so we check them here
-	if err := conf.Email.SetAccessCredentialsFromEnv(conf.Email.Provider); err != nil {
-		return nil, errors.Wrap(err, "an error occurred while setting credentials with env variables for email service")
+	// the announcers in the list are enabled unless they are disabled explicitly
+	for i := range conf.Announcers {
+		if conf.Announcers[i].Settings == nil {
+			conf.Announcers[i].Settings = make(map[string]any)
+		}
+
+		if _, ok := conf.Announcers[i].Settings["enabled"]; !ok {
+			conf.Announcers[i].Settings["enabled"] = true
+		}
 	}
 
+	// the announcer block of the previous versions has a single announcer of each type keyed by the type
+	conf.Announcers = append(legacyAnnouncers(viper.GetStringMap("announcer")), conf.Announcers...)
+
 	// set default values for config
 	conf.Global.SetDefaults()
 
 	return conf, nil
 }
+
+// legacyAnnouncers converts the announcer block into the announcer entries, ordered by type
+func legacyAnnouncers(block map[string]any) []AnnouncerEntry {
+	kinds := make([]string, 0, len(block))
+	for kind := range block {
+		kinds = append(kinds, kind)
+	}
+
+	sort.Strings(kinds)
+
+	var entries []AnnouncerEntry
+	for _, kind := range kinds {
+		if settings, ok := block[kind].(map[string]any); ok {
+			entries = append(entries, AnnouncerEntry{Type: kind, Settings: settings, legacy: true})
+		}
+	}
+
+	return entries
+}
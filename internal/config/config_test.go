@@ -74,3 +74,35 @@ func TestReadConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestReadConfig_Announcers(t *testing.T) {
+	c, err := ReadConfig(&cobra.Command{}, "../../test/config_announcers.yaml")
+	assert.Nil(t, err)
+
+	// the announcer block comes first, then the list
+	assert.Len(t, c.Announcers, 4)
+	assert.Equal(t, []string{"slack", "slack", "slack", "email"},
+		[]string{c.Announcers[0].Type, c.Announcers[1].Type, c.Announcers[2].Type, c.Announcers[3].Type})
+
+	var names []string
+	for _, entry := range c.Announcers {
+		common, err := entry.Common()
+		assert.Nil(t, err)
+		names = append(names, common.Name)
+
+		// the entries in the list are enabled unless they are disabled explicitly
+		assert.Equal(t, entry.Type == "slack", common.Enabled)
+	}
+
+	assert.Equal(t, []string{"", "team-a", "team-b", "releases-list"}, names)
+
+	var slack Slack
+	assert.Nil(t, c.Announcers[1].Decode(&slack))
+	assert.Equal(t, "asdfasdfasdf", slack.WebhookUrl)
+	assert.Equal(t, []string{"team-a"}, slack.Policy.Tags)
+
+	var email Email
+	assert.Nil(t, c.Announcers[3].Decode(&email))
+	assert.Equal(t, "smtp", email.Sender)
+	assert.Equal(t, []string{"team-b"}, c.Repositories[0].Announcers)
+}
@@ -2,7 +2,9 @@ package config
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
 )
 
@@ -14,9 +16,10 @@ const (
 type Config struct {
 	Repositories []Repository `yaml:"repositories"`
 	Storage      `yaml:"storage"`
-	Announcer    `yaml:"announcer"`
-	Type         string `yaml:"type"`
-	Global       `yaml:"global"`
+	// Announcers are the announcer instances, the announcer block of the previous versions is converted into them
+	Announcers []AnnouncerEntry `yaml:"announcers"`
+	Type       string           `yaml:"type"`
+	Global     `yaml:"global"`
 }
 
 // Global struct represents the global config
@@ -24,6 +27,10 @@ type Global struct {
 	OneShot           bool `yaml:"oneShot"`
 	Verbose           bool `yaml:"verbose"`
 	MaxConcurrentJobs int  `yaml:"maxConcurrentJobs"`
+	// GithubToken is used to fetch the release notes from the GitHub API when the feed does not contain them
+	GithubToken string `yaml:"githubToken"`
+	// GithubApiUrl is the base url of the GitHub API, defaults to https://api.github.com
+	GithubApiUrl string `yaml:"githubApiUrl"`
 }
 
 func (g *Global) SetDefaults() {
@@ -36,23 +43,92 @@ type Repository struct {
 	Description          string `yaml:"description"`
 	Url                  string `yaml:"url"`
 	CheckIntervalMinutes int    `yaml:"checkIntervalMinutes"`
+	// MinAge is the duration a release must have existed for before it is announced, releases are kept
+	// as pending until then and dropped if they disappear from the feed in the meantime
+	MinAge time.Duration `yaml:"minAge"`
+	// Announcers restricts the releases to the announcers with the given names, the subscriptions of the announcers
+	// decide if it is empty
+	Announcers []string `yaml:"announcers"`
+	// Tags are matched against the tags the announcers subscribe to
+	Tags []string `yaml:"tags"`
 }
 
-type Announcer struct {
-	Slack `yaml:"slack"`
-	Email `yaml:"email"`
+// AnnouncerEntry struct represents an announcer in the announcers list, its settings are the fields of the config of
+// its type, e.g. webhookUrl of a slack announcer
+type AnnouncerEntry struct {
+	// Type is the type of the announcer in lowercase, like slack, email or rocketchat
+	Type     string         `yaml:"type"`
+	Settings map[string]any `yaml:",inline" mapstructure:",remain"`
+	// legacy is set for the entries converted from the announcer block, which ignored the unknown fields
+	legacy bool
+}
+
+// Decode decodes the settings of the entry into the config of its type the same way as the config file, the unknown
+// fields fail unless the entry is converted from the announcer block
+func (e AnnouncerEntry) Decode(target any) error {
+	if err := e.decode(target, !e.legacy); err != nil {
+		return err
+	}
+
+	// ses access credentials of the email announcer in the announcer block can also be set from env variables, they
+	// are not applied to the entries since each of them can have its own ses sender
+	if email, ok := target.(*Email); ok && e.legacy {
+		return email.Ses.SetAccessCredentialsFromEnv(email.Provider)
+	}
+
+	return nil
+}
+
+// Common returns the fields of the entry shared by all announcer types
+func (e AnnouncerEntry) Common() (common AnnouncerCommon, err error) {
+	err = e.decode(&common, false)
+	return common, err
+}
+
+func (e AnnouncerEntry) decode(target any, strict bool) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToSliceHookFunc(","),
+		),
+		ErrorUnused: strict,
+		Result:      target,
+	})
+	if err != nil {
+		return err
+	}
+
+	return decoder.Decode(e.Settings)
+}
+
+// AnnouncerCommon struct represents the fields shared by the configs of all announcer types
+type AnnouncerCommon struct {
+	Enabled   bool   `yaml:"enabled"`
+	Name      string `yaml:"name"`
+	Policy    `yaml:"policy"`
+	Templates `yaml:"templates"`
 }
 
 type Email struct {
 	Provider string   `yaml:"provider"`
 	Enabled  bool     `yaml:"enabled"`
+	Name     string   `yaml:"name"`
 	From     string   `yaml:"from"`
 	To       []string `yaml:"to"`
-	Type     string   `yaml:"type"`
-	Cc       []string `yaml:"cc"`
-	Bcc      []string `yaml:"bcc"`
-	Ses      `yaml:"ses"`
-	Smtp     `yaml:"smtp"`
+	// Sender is one of ses and smtp
+	Sender string `yaml:"sender"`
+	// Type is the name of Sender in the announcer block of the previous versions
+	Type string   `yaml:"type"`
+	Cc   []string `yaml:"cc"`
+	Bcc  []string `yaml:"bcc"`
+	// TextTemplate is the text/template of the plain text body, defaults to the summary and the release notes
+	TextTemplate string `yaml:"textTemplate"`
+	// HtmlTemplate is the html/template of the HTML body, defaults to a responsive layout of the release
+	HtmlTemplate string `yaml:"htmlTemplate"`
+	Ses          `yaml:"ses"`
+	Smtp         `yaml:"smtp"`
+	Policy       `yaml:"policy"`
+	Templates    `yaml:"templates"`
 }
 
 type Ses struct {
@@ -66,13 +142,419 @@ type Smtp struct {
 	Port     int    `yaml:"port"`
 	Username string `yaml:"username"`
 	Password string `yaml:"password"`
+	// Security is one of starttls, tls and none. Defaults to starttls.
+	Security string `yaml:"security"`
+	// Auth is the authentication mechanism, one of plain and login. Defaults to plain.
+	Auth string `yaml:"auth"`
 }
 
 type Slack struct {
 	Enabled    bool   `yaml:"enabled"`
+	Name       string `yaml:"name"`
 	WebhookUrl string `yaml:"webhookUrl"`
 	Username   string `yaml:"username"`
 	IconUrl    string `yaml:"iconUrl"`
+	Policy     `yaml:"policy"`
+	Templates  `yaml:"templates"`
+}
+
+type Telegram struct {
+	Enabled  bool   `yaml:"enabled"`
+	Name     string `yaml:"name"`
+	BotToken string `yaml:"botToken"`
+	// ParseMode is one of HTML and MarkdownV2. Defaults to HTML.
+	ParseMode string `yaml:"parseMode"`
+	// ApiUrl is the base url of the Bot API, defaults to https://api.telegram.org
+	ApiUrl    string         `yaml:"apiUrl"`
+	Chats     []TelegramChat `yaml:"chats"`
+	Policy    `yaml:"policy"`
+	Templates `yaml:"templates"`
+}
+
+// TelegramChat struct represents a Telegram chat, ThreadId is the topic of the forum supergroups
+type TelegramChat struct {
+	Id       string `yaml:"id"`
+	ThreadId int    `yaml:"threadId"`
+}
+
+type Discord struct {
+	Enabled    bool   `yaml:"enabled"`
+	Name       string `yaml:"name"`
+	WebhookUrl string `yaml:"webhookUrl"`
+	Username   string `yaml:"username"`
+	AvatarUrl  string `yaml:"avatarUrl"`
+	Policy     `yaml:"policy"`
+	Templates  `yaml:"templates"`
+}
+
+type Teams struct {
+	Enabled bool   `yaml:"enabled"`
+	Name    string `yaml:"name"`
+	// WebhookUrl is the incoming webhook or the Workflows webhook url of the channel
+	WebhookUrl string `yaml:"webhookUrl"`
+	Policy     `yaml:"policy"`
+	Templates  `yaml:"templates"`
+}
+
+type Webhook struct {
+	Enabled bool   `yaml:"enabled"`
+	Name    string `yaml:"name"`
+	Url     string `yaml:"url"`
+	// Method is one of POST, PUT and PATCH. Defaults to POST.
+	Method  string            `yaml:"method"`
+	Headers map[string]string `yaml:"headers"`
+	// Template is the text/template of the request body, defaults to the release event encoded in Format
+	Template string `yaml:"template"`
+	// Secret signs the body with HMAC-SHA256 in the X-Signature header if set
+	Secret string `yaml:"secret"`
+	// Timeout of the requests, defaults to 10s
+	Timeout time.Duration `yaml:"timeout"`
+	// Format is one of json, cloudevents (structured) and cloudevents-binary. Defaults to json. It can not be one of
+	// the CloudEvents formats if Template is set.
+	Format    string `yaml:"format"`
+	Policy    `yaml:"policy"`
+	Templates `yaml:"templates"`
+}
+
+type Mattermost struct {
+	Enabled    bool   `yaml:"enabled"`
+	Name       string `yaml:"name"`
+	WebhookUrl string `yaml:"webhookUrl"`
+	// Channel overrides the default channel of the webhook
+	Channel   string `yaml:"channel"`
+	Username  string `yaml:"username"`
+	IconUrl   string `yaml:"iconUrl"`
+	Policy    `yaml:"policy"`
+	Templates `yaml:"templates"`
+}
+
+type RocketChat struct {
+	Enabled    bool   `yaml:"enabled"`
+	Name       string `yaml:"name"`
+	WebhookUrl string `yaml:"webhookUrl"`
+	// Channel overrides the default channel of the webhook, like #releases or @alice
+	Channel   string `yaml:"channel"`
+	Username  string `yaml:"username"`
+	IconUrl   string `yaml:"iconUrl"`
+	Policy    `yaml:"policy"`
+	Templates `yaml:"templates"`
+}
+
+type Matrix struct {
+	Enabled       bool   `yaml:"enabled"`
+	Name          string `yaml:"name"`
+	HomeserverUrl string `yaml:"homeserverUrl"`
+	// AccessToken is the access token of the bot user which joined the room
+	AccessToken string `yaml:"accessToken"`
+	RoomId      string `yaml:"roomId"`
+	Policy      `yaml:"policy"`
+	Templates   `yaml:"templates"`
+}
+
+type Ntfy struct {
+	Enabled bool   `yaml:"enabled"`
+	Name    string `yaml:"name"`
+	// ServerUrl defaults to https://ntfy.sh
+	ServerUrl string `yaml:"serverUrl"`
+	Topic     string `yaml:"topic"`
+	// Token is the access token of the protected topics
+	Token string `yaml:"token"`
+	// Priority is between 1 and 5, defaults to 3. Urgent releases are sent with priority 5.
+	Priority  int      `yaml:"priority"`
+	Tags      []string `yaml:"tags"`
+	Policy    `yaml:"policy"`
+	Templates `yaml:"templates"`
+}
+
+type Gotify struct {
+	Enabled   bool   `yaml:"enabled"`
+	Name      string `yaml:"name"`
+	ServerUrl string `yaml:"serverUrl"`
+	AppToken  string `yaml:"appToken"`
+	// Priority is between 1 and 10, defaults to 5. Urgent releases are sent with priority 8 at least.
+	Priority  int `yaml:"priority"`
+	Policy    `yaml:"policy"`
+	Templates `yaml:"templates"`
+}
+
+type Pushover struct {
+	Enabled  bool   `yaml:"enabled"`
+	Name     string `yaml:"name"`
+	AppToken string `yaml:"appToken"`
+	// UserKey is the key of a user or a delivery group
+	UserKey string `yaml:"userKey"`
+	// Priority is between -2 and 1, defaults to 0. Urgent releases are sent with priority 1.
+	Priority  int `yaml:"priority"`
+	Policy    `yaml:"policy"`
+	Templates `yaml:"templates"`
+}
+
+// Issue struct represents the config of the announcer which opens an issue for each release in a tracking repository
+type Issue struct {
+	Enabled bool   `yaml:"enabled"`
+	Name    string `yaml:"name"`
+	// Provider is one of github and gitlab
+	Provider string `yaml:"provider"`
+	// ApiUrl defaults to https://api.github.com for github and https://gitlab.com for gitlab
+	ApiUrl string `yaml:"apiUrl"`
+	// Token defaults to global.githubToken for github
+	Token string `yaml:"token"`
+	// Repository is owner/repo for github and the project path or id for gitlab
+	Repository string   `yaml:"repository"`
+	Labels     []string `yaml:"labels"`
+	Assignees  []string `yaml:"assignees"`
+	// Template is the text/template of the issue body, defaults to the summary and the release notes in Markdown
+	Template  string `yaml:"template"`
+	Policy    `yaml:"policy"`
+	Templates `yaml:"templates"`
+}
+
+type Jira struct {
+	Enabled bool   `yaml:"enabled"`
+	Name    string `yaml:"name"`
+	BaseUrl string `yaml:"baseUrl"`
+	// Username is the email of the user on Jira Cloud, Token is sent as a personal access token if it is empty
+	Username   string `yaml:"username"`
+	Token      string `yaml:"token"`
+	ProjectKey string `yaml:"projectKey"`
+	// IssueType defaults to Task
+	IssueType  string   `yaml:"issueType"`
+	Components []string `yaml:"components"`
+	Labels     []string `yaml:"labels"`
+	// Priorities maps the change levels security, breaking, high and normal to the Jira priority names
+	Priorities map[string]string `yaml:"priorities"`
+	// Summary and Description are the text/templates of the issue fields
+	Summary     string `yaml:"summary"`
+	Description string `yaml:"description"`
+	// CustomFields maps the custom field ids to their text/templates
+	CustomFields map[string]string `yaml:"customFields"`
+	Policy       `yaml:"policy"`
+	Templates    `yaml:"templates"`
+}
+
+type PagerDuty struct {
+	Enabled bool   `yaml:"enabled"`
+	Name    string `yaml:"name"`
+	// ApiUrl defaults to the public Events API v2
+	ApiUrl     string `yaml:"apiUrl"`
+	RoutingKey string `yaml:"routingKey"`
+	// Source defaults to rss-feed-filterer
+	Source string `yaml:"source"`
+	// Severities maps the change levels security, breaking, high and normal to the PagerDuty severities
+	Severities map[string]string `yaml:"severities"`
+	Policy     `yaml:"policy"`
+	Templates  `yaml:"templates"`
+}
+
+type Opsgenie struct {
+	Enabled bool   `yaml:"enabled"`
+	Name    string `yaml:"name"`
+	// ApiUrl defaults to the US instance
+	ApiUrl string `yaml:"apiUrl"`
+	ApiKey string `yaml:"apiKey"`
+	// Priorities maps the change levels security, breaking, high and normal to the alert priorities P1 to P5
+	Priorities map[string]string `yaml:"priorities"`
+	Tags       []string          `yaml:"tags"`
+	// Teams are the responders of the alerts
+	Teams     []string `yaml:"teams"`
+	Policy    `yaml:"policy"`
+	Templates `yaml:"templates"`
+}
+
+// Sns, Sqs and EventBridge use the default credential chain of the aws sdk if AccessKey is empty
+type Sns struct {
+	Enabled   bool   `yaml:"enabled"`
+	Name      string `yaml:"name"`
+	Region    string `yaml:"region"`
+	AccessKey string `yaml:"accessKey"`
+	SecretKey string `yaml:"secretKey"`
+	// TopicArn is the topic to publish to, the messages of the FIFO topics are grouped by the project
+	TopicArn string `yaml:"topicArn"`
+	// Format is one of json and cloudevents. Defaults to json.
+	Format    string `yaml:"format"`
+	Policy    `yaml:"policy"`
+	Templates `yaml:"templates"`
+}
+
+type Sqs struct {
+	Enabled   bool   `yaml:"enabled"`
+	Name      string `yaml:"name"`
+	Region    string `yaml:"region"`
+	AccessKey string `yaml:"accessKey"`
+	SecretKey string `yaml:"secretKey"`
+	// QueueUrl is the queue to send to, the messages of the FIFO queues are grouped by the project
+	QueueUrl string `yaml:"queueUrl"`
+	// Format is one of json and cloudevents. Defaults to json.
+	Format    string `yaml:"format"`
+	Policy    `yaml:"policy"`
+	Templates `yaml:"templates"`
+}
+
+type EventBridge struct {
+	Enabled   bool   `yaml:"enabled"`
+	Name      string `yaml:"name"`
+	Region    string `yaml:"region"`
+	AccessKey string `yaml:"accessKey"`
+	SecretKey string `yaml:"secretKey"`
+	// EventBusName is the name or the arn of the bus, defaults to the default bus of the account
+	EventBusName string `yaml:"eventBusName"`
+	// Source is the source of the events, defaults to rss-feed-filterer
+	Source string `yaml:"source"`
+	// Format is one of json and cloudevents. Defaults to json.
+	Format    string `yaml:"format"`
+	Policy    `yaml:"policy"`
+	Templates `yaml:"templates"`
+}
+
+// Kafka, Nats and Redis publish the versioned release events keyed by the project name
+type Kafka struct {
+	Enabled bool     `yaml:"enabled"`
+	Name    string   `yaml:"name"`
+	Brokers []string `yaml:"brokers"`
+	Topic   string   `yaml:"topic"`
+	// Format is one of json, cloudevents and cloudevents-binary. Defaults to json.
+	Format string `yaml:"format"`
+	Tls    bool   `yaml:"tls"`
+	// SaslMechanism is one of plain, scram-sha-256 and scram-sha-512, SASL is disabled if it is empty
+	SaslMechanism string `yaml:"saslMechanism"`
+	Username      string `yaml:"username"`
+	Password      string `yaml:"password"`
+	Policy        `yaml:"policy"`
+	Templates     `yaml:"templates"`
+}
+
+type Nats struct {
+	Enabled bool   `yaml:"enabled"`
+	Name    string `yaml:"name"`
+	Url     string `yaml:"url"`
+	Subject string `yaml:"subject"`
+	// Format is one of json, cloudevents and cloudevents-binary. Defaults to json.
+	Format string `yaml:"format"`
+	Token  string `yaml:"token"`
+	// Username and Password are used if Token and CredentialsFile are empty
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	// CredentialsFile is the path of the .creds file of the user, it takes precedence over the other credentials
+	CredentialsFile string `yaml:"credentialsFile"`
+	Policy          `yaml:"policy"`
+	Templates       `yaml:"templates"`
+}
+
+type Redis struct {
+	Enabled  bool   `yaml:"enabled"`
+	Name     string `yaml:"name"`
+	Addr     string `yaml:"addr"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	Db       int    `yaml:"db"`
+	Tls      bool   `yaml:"tls"`
+	Stream   string `yaml:"stream"`
+	// Format is one of json, cloudevents and cloudevents-binary. Defaults to json.
+	Format string `yaml:"format"`
+	// MaxLen caps the length of the stream approximately, the stream is not trimmed if it is 0
+	MaxLen    int64 `yaml:"maxLen"`
+	Policy    `yaml:"policy"`
+	Templates `yaml:"templates"`
+}
+
+// File, Stdout and Exec are the local announcers for piping the results into the scripts and cron jobs
+type File struct {
+	Enabled bool   `yaml:"enabled"`
+	Name    string `yaml:"name"`
+	Path    string `yaml:"path"`
+	// Format is one of json and cloudevents. Defaults to json.
+	Format string `yaml:"format"`
+	// MaxSizeMb is the size the file is rotated at, the file is not rotated if it is 0
+	MaxSizeMb int64 `yaml:"maxSizeMb"`
+	// MaxBackups is the number of the rotated files to keep
+	MaxBackups int `yaml:"maxBackups"`
+	Policy     `yaml:"policy"`
+	Templates  `yaml:"templates"`
+}
+
+type Stdout struct {
+	Enabled bool   `yaml:"enabled"`
+	Name    string `yaml:"name"`
+	// Format is one of text, json and cloudevents. Defaults to text.
+	Format    string `yaml:"format"`
+	Policy    `yaml:"policy"`
+	Templates `yaml:"templates"`
+}
+
+type Exec struct {
+	Enabled bool   `yaml:"enabled"`
+	Name    string `yaml:"name"`
+	// Command is the executable and its arguments, it is not run in a shell
+	Command []string `yaml:"command"`
+	// Format is the format of the event written to the standard input, one of json and cloudevents. Defaults to json.
+	Format string `yaml:"format"`
+	// Timeout of the command, defaults to 30s
+	Timeout   time.Duration `yaml:"timeout"`
+	Policy    `yaml:"policy"`
+	Templates `yaml:"templates"`
+}
+
+// Templates struct represents the message templates of an announcer, the templates are text/templates executed with
+// the release fields and the helper functions listed in the README
+type Templates struct {
+	// Message replaces the one line summary of the releases
+	Message string `yaml:"message"`
+	// Subject replaces the email subject and the notification titles
+	Subject string `yaml:"subject"`
+	// Overrides replace the templates for the releases of the given repositories, first matching override wins
+	Overrides []TemplateOverride `yaml:"overrides"`
+}
+
+type TemplateOverride struct {
+	Repositories []string `yaml:"repositories"`
+	Message      string   `yaml:"message"`
+	Subject      string   `yaml:"subject"`
+}
+
+// Policy struct represents the delivery rules of an announcer
+type Policy struct {
+	// Events are the event types to be announced, one of new, updated and removed. Defaults to new.
+	Events []string `yaml:"events"`
+	// SecurityOnly restricts the announcer to the security related releases
+	SecurityOnly bool `yaml:"securityOnly"`
+	// HasBreakingChanges restricts the announcer to the releases with breaking changes in their notes
+	HasBreakingChanges bool `yaml:"hasBreakingChanges"`
+	// Repositories restricts the announcer to the releases of the given repository names
+	Repositories []string `yaml:"repositories"`
+	// Tags restricts the announcer to the releases of the repositories with any of the given tags, the releases of
+	// Repositories are also announced if both are set
+	Tags []string `yaml:"tags"`
+	// Routes override the priority and recipients of the matching releases, first matching route wins
+	Routes []Route `yaml:"routes"`
+	// Digest batches the releases into a single summary which is sent on a schedule
+	Digest `yaml:"digest"`
+}
+
+// Digest struct represents the schedule of the release digests of an announcer
+type Digest struct {
+	// Schedule is one of hourly, daily and weekly, the digest is disabled if it is empty
+	Schedule string `yaml:"schedule"`
+	// At is the time of the day in HH:MM format for the daily and weekly digests. Defaults to 09:00.
+	At string `yaml:"at"`
+	// Weekday is the day of the weekly digests. Defaults to monday.
+	Weekday string `yaml:"weekday"`
+	// Timezone is the IANA time zone name of the schedule. Defaults to UTC.
+	Timezone string `yaml:"timezone"`
+}
+
+// Route struct represents a routing rule of an announcer
+type Route struct {
+	// Security matches only the security related releases
+	Security bool `yaml:"security"`
+	// HasBreakingChanges matches only the releases with breaking changes in their notes
+	HasBreakingChanges bool `yaml:"hasBreakingChanges"`
+	// Repositories matches only the releases of the given repository names
+	Repositories []string `yaml:"repositories"`
+	// Priority is one of normal and high
+	Priority   string   `yaml:"priority"`
+	Recipients []string `yaml:"recipients"`
+	// Immediate sends the matching releases right away instead of adding them to the digest
+	Immediate bool `yaml:"immediate"`
 }
 
 type Storage struct {
@@ -5,6 +5,7 @@ package config
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -33,3 +34,58 @@ func TestStorage_SetAccessCredentialsFromEnv(t *testing.T) {
 		t.Errorf("expected %s, got %s", "testAccessKey", storage.S3.AccessKey)
 	}
 }
+
+func TestAnnouncerEntry_Decode(t *testing.T) {
+	settings := map[string]any{"enabled": true, "command": "notify-send", "timeout": "10s", "unknownField": "value"}
+
+	var exec Exec
+	err := AnnouncerEntry{Type: "exec", Settings: settings}.Decode(&exec)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "unknownField")
+
+	// the announcer block ignored the unknown fields
+	err = AnnouncerEntry{Type: "exec", Settings: settings, legacy: true}.Decode(&exec)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"notify-send"}, exec.Command)
+	assert.Equal(t, time.Second*10, exec.Timeout)
+}
+
+func TestAnnouncerEntry_Decode_sesCredentialsFromEnv(t *testing.T) {
+	t.Setenv("EMAIL_SES_ACCESS_KEY", "envAccessKey")
+	t.Setenv("EMAIL_SES_SECRET_KEY", "envSecretKey")
+
+	cases := []struct {
+		caseName          string
+		entry             AnnouncerEntry
+		expectedAccessKey string
+		expectedSecretKey string
+	}{
+		{
+			"First ses entry",
+			AnnouncerEntry{Type: "email", Settings: map[string]any{"provider": "ses", "sender": "ses",
+				"ses": map[string]any{"region": "us-east-1", "accessKey": "usAccessKey", "secretKey": "usSecretKey"}}},
+			"usAccessKey", "usSecretKey",
+		},
+		{
+			"Second ses entry",
+			AnnouncerEntry{Type: "email", Settings: map[string]any{"provider": "ses", "sender": "ses",
+				"ses": map[string]any{"region": "eu-west-1"}}},
+			"", "",
+		},
+		{
+			"Announcer block",
+			AnnouncerEntry{Type: "email", Settings: map[string]any{"provider": "ses", "type": "ses",
+				"ses": map[string]any{"region": "us-east-1", "accessKey": "blockAccessKey"}}, legacy: true},
+			"envAccessKey", "envSecretKey",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		var email Email
+		assert.Nil(t, tc.entry.Decode(&email))
+		assert.Equal(t, tc.expectedAccessKey, email.AccessKey)
+		assert.Equal(t, tc.expectedSecretKey, email.SecretKey)
+	}
+}
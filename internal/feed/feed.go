@@ -3,13 +3,17 @@ package feed
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"sync"
+	"time"
 
 	"github.com/mmcdole/gofeed"
+	"github.com/rs/zerolog"
 
 	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
 	"github.com/bilalcaliskan/rss-feed-filterer/internal/config"
 	"github.com/bilalcaliskan/rss-feed-filterer/internal/logging"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/notes"
 	"github.com/bilalcaliskan/rss-feed-filterer/internal/storage/aws"
 )
 
@@ -17,8 +21,14 @@ const (
 	maxRetries         = 3
 	defaultSemverRegex = `/(v?\d+\.\d+\.\d+)$`
 	releaseFileKey     = "releases.json"
+	pendingFileKey     = "pending_releases.json"
+	// digestFlushInterval is how often the release digests are checked if they are due
+	digestFlushInterval = time.Minute
 )
 
+// releaseTagRegex extracts the tag of a release from its link, e.g. https://github.com/user1/project1/releases/tag/v1.0.0
+var releaseTagRegex = regexp.MustCompile(`/releases/tag/([^/?#]+)`)
+
 // Filter function filters the feed and uploads the filtered feed to the bucket if there is a new release
 func Filter(ctx context.Context, cfg *config.Config, client aws.S3ClientAPI, announcers []announce.Announcer) error {
 	logger := logging.GetLogger()
@@ -35,6 +45,26 @@ func Filter(ctx context.Context, cfg *config.Config, client aws.S3ClientAPI, ann
 
 	var wg sync.WaitGroup
 
+	// send the release digests which are due while the repositories are being checked periodically
+	done := make(chan struct{})
+	if !cfg.OneShot {
+		go func() {
+			ticker := time.NewTicker(digestFlushInterval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-done:
+					return
+				case now := <-ticker.C:
+					flushDigests(announcers, now, logger)
+				}
+			}
+		}()
+	}
+
 	// iterate over repositories and start a goroutine for each repository to check for new releases
 	for _, repo := range cfg.Repositories {
 		// Send an empty struct to the semaphore. This operation will block if the semaphore is full.
@@ -45,6 +75,7 @@ func Filter(ctx context.Context, cfg *config.Config, client aws.S3ClientAPI, ann
 			defer wg.Done()
 
 			checker := NewReleaseChecker(client, repo, semaphore, gofeed.NewParser(), cfg.BucketName, logging.GetLogger(), announcers)
+			checker.notesFetcher = notes.NewGithubFetcher(cfg.GithubApiUrl, cfg.GithubToken)
 
 			projectName, err := checker.extractProjectName()
 			if err != nil {
@@ -69,6 +100,24 @@ func Filter(ctx context.Context, cfg *config.Config, client aws.S3ClientAPI, ann
 
 	// Wait for all operations to complete.
 	wg.Wait()
+	close(done)
+
+	flushDigests(announcers, time.Now(), logger)
+	for _, announcer := range announcers {
+		if err := announce.Close(announcer); err != nil {
+			logger.Warn().Err(err).Msg("failed to close the announcer")
+		}
+	}
+
 	logger.Info().Msg("all goroutines are finished their works, shutting down...")
 	return nil
 }
+
+// flushDigests sends the release digests of the announcers which are due at the given time
+func flushDigests(announcers []announce.Announcer, now time.Time, logger zerolog.Logger) {
+	for _, announcer := range announcers {
+		if err := announce.Flush(announcer, now); err != nil {
+			logger.Error().Err(err).Msg("failed to send the release digest")
+		}
+	}
+}
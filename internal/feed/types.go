@@ -2,8 +2,10 @@ package feed
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/url"
+	"path"
 	"regexp"
 	"strings"
 	"time"
@@ -12,6 +14,8 @@ import (
 
 	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce"
 	"github.com/bilalcaliskan/rss-feed-filterer/internal/config"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/notes"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/security"
 	"github.com/bilalcaliskan/rss-feed-filterer/internal/storage/aws"
 	"github.com/bilalcaliskan/rss-feed-filterer/internal/types"
 	"github.com/mmcdole/gofeed"
@@ -32,6 +36,8 @@ type ReleaseChecker struct {
 	config.Repository
 	announcers []announce.Announcer
 	sem        chan struct{}
+	// notesFetcher fetches the release notes which are not included in the feed, it is optional
+	notesFetcher notes.Fetcher
 }
 
 // NewReleaseChecker creates a new ReleaseChecker instance
@@ -116,6 +122,7 @@ func (r *ReleaseChecker) checkFeed(projectName string, repo config.Repository) {
 		fetchedReleases := r.getReleasesFromFeed(projectName, feed.Items)
 
 		var allReleases []types.Release
+		var waiting []pendingRelease
 		if aws.IsObjectExists(r.S3ClientAPI, r.bucketName, fmt.Sprintf("%s/%s", projectName, releaseFileKey)) {
 			previousReleases, err := aws.GetReleases(r.S3ClientAPI, r.bucketName, fmt.Sprintf("%s/%s", projectName, releaseFileKey))
 			if err != nil {
@@ -123,16 +130,28 @@ func (r *ReleaseChecker) checkFeed(projectName string, repo config.Repository) {
 				continue
 			}
 
-			diff := r.getDiff(fetchedReleases, previousReleases)
-			if len(diff) == 0 {
-				r.logger.Info().Msg("no new releases found, nothing to do")
+			events, pending, err := r.holdBackPending(projectName, fetchedReleases, r.getDiff(fetchedReleases, previousReleases))
+			if err != nil {
+				r.logger.Warn().Err(err).Msg("an error occurred while processing pending releases")
+				continue
+			}
+
+			waiting = pending
+
+			if len(events) == 0 {
+				r.logger.Info().Msg("no changed releases found, nothing to do")
+				if err := r.putPending(projectName, waiting); err != nil {
+					r.logger.Warn().Err(err).Msg("an error occurred while putting pending releases into bucket")
+				}
+
 				return
 			}
 
-			r.logger.Info().Int("count", len(diff)).Msg("successfully fetched diffs")
-			r.sendNotification(diff)
+			r.logger.Info().Int("count", len(events)).Msg("successfully fetched diffs")
+			r.fillNotes(projectName, events)
+			r.sendNotification(events)
 
-			allReleases = append(diff, previousReleases...)
+			allReleases = applyEvents(previousReleases, events)
 		} else {
 			r.logger.Info().Msg("releases does not exists on bucket, adding from scratch")
 			allReleases = fetchedReleases
@@ -146,31 +165,175 @@ func (r *ReleaseChecker) checkFeed(projectName string, repo config.Repository) {
 
 		r.logger.Info().Int("count", len(allReleases)).Msg("successfully put all releases into bucket")
 
+		// the pending releases are put after the releases, the matured ones stay pending if the releases are not stored
+		if err := r.putPending(projectName, waiting); err != nil {
+			r.logger.Warn().Err(err).Msg("an error occurred while putting pending releases into bucket")
+		}
+
 		break
 	}
 }
 
-func (r *ReleaseChecker) sendNotification(releases []types.Release) {
+func (r *ReleaseChecker) sendNotification(events []types.ReleaseEvent) {
 	if len(r.announcers) == 0 {
 		return
 	}
 
-	for _, v := range releases {
+	for _, v := range events {
+		payload := &announce.AnnouncerPayload{
+			ProjectName:     v.ProjectName,
+			Version:         v.Version,
+			URL:             v.Url,
+			PublishedAt:     v.PublishedAt,
+			UpdatedAt:       v.UpdatedAt,
+			Event:           v.Type,
+			Changes:         v.Changes,
+			Notes:           v.Notes,
+			BreakingChanges: notes.BreakingChanges(v.Notes),
+			Security:        v.Security,
+			Tags:            r.Tags,
+			Announcers:      r.Repository.Announcers,
+		}
+
 		for _, a := range r.announcers {
-			if err := a.Notify(&announce.AnnouncerPayload{
-				ProjectName: v.ProjectName,
-				Version:     v.Version,
-				URL:         v.Url,
-			}); err != nil {
+			if err := a.Notify(payload); err != nil {
+				if errors.Is(err, announce.ErrFiltered) {
+					r.logger.Debug().Str("version", v.Version).Str("event", string(v.Type)).Msg("announce filtered out by policy, skipping")
+					continue
+				}
+
 				r.logger.Warn().Err(err).Msg("an error occurred while sending announce, skipping")
 				continue
 			}
 
-			r.logger.Info().Str("version", v.Version).Msg("successfully sent announce")
+			r.logger.Info().Str("version", v.Version).Str("event", string(v.Type)).Msg("successfully sent announce")
 		}
 	}
 }
 
+// fillNotes fetches the missing release notes of the new and updated releases with the notes fetcher
+func (r *ReleaseChecker) fillNotes(projectName string, events []types.ReleaseEvent) {
+	if r.notesFetcher == nil {
+		return
+	}
+
+	for i, event := range events {
+		if event.Notes != "" || event.Type == types.EventRemoved {
+			continue
+		}
+
+		releaseNotes, err := r.notesFetcher.Fetch(projectName, path.Base(event.Url))
+		if err != nil {
+			r.logger.Warn().Err(err).Str("version", event.Version).Msg("an error occurred while fetching release notes, skipping")
+			continue
+		}
+
+		events[i].Notes = releaseNotes
+		if event.Security == nil {
+			events[i].Security = security.Detect(releaseNotes)
+		}
+	}
+}
+
+// notesOf returns the release notes of the feed item, GitHub puts them into the content of the entries
+func notesOf(item *gofeed.Item) string {
+	if item.Content != "" {
+		return item.Content
+	}
+
+	return item.Description
+}
+
+// pendingRelease is a release held back until it is older than MinAge, FirstSeenAt is its age if the feed does not
+// contain its publish time
+type pendingRelease struct {
+	types.Release
+	FirstSeenAt *time.Time `json:"firstSeenAt,omitempty"`
+}
+
+// holdBackPending returns the events whose releases are old enough to be announced and the new releases younger than
+// MinAge that are still pending. Pending releases that disappeared from the feed in the meantime are dropped. The
+// pending releases are not put into the bucket here, see putPending.
+func (r *ReleaseChecker) holdBackPending(projectName string, fetchedReleases []types.Release,
+	events []types.ReleaseEvent) ([]types.ReleaseEvent, []pendingRelease, error) {
+	if r.MinAge <= 0 {
+		return events, nil, nil
+	}
+
+	key := fmt.Sprintf("%s/%s", projectName, pendingFileKey)
+
+	var pending []pendingRelease
+	if aws.IsObjectExists(r.S3ClientAPI, r.bucketName, key) {
+		if err := aws.GetObject(r.S3ClientAPI, r.bucketName, key, &pending); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	now := time.Now()
+	var result []types.ReleaseEvent
+	for _, event := range events {
+		if event.Type != types.EventNew {
+			result = append(result, event)
+			continue
+		}
+
+		if !containsPending(pending, event.Release) {
+			pending = append(pending, pendingRelease{Release: event.Release, FirstSeenAt: &now})
+		}
+	}
+
+	var waiting []pendingRelease
+	for _, item := range pending {
+		current, ok := findVersion(fetchedReleases, item.Release)
+		if !ok {
+			r.logger.Warn().Str("version", item.Version).Msg("pending release disappeared from the feed, dropping it")
+			continue
+		}
+
+		// the pending releases of the previous versions have no first seen time, they are counted from now on
+		if item.FirstSeenAt == nil {
+			item.FirstSeenAt = &now
+		}
+
+		seenAt := item.FirstSeenAt
+		if current.PublishedAt != nil {
+			seenAt = current.PublishedAt
+		}
+
+		if now.Sub(*seenAt) < r.MinAge {
+			r.logger.Info().Str("version", current.Version).Dur("minAge", r.MinAge).Msg("release is not old enough yet, keeping it as pending")
+			waiting = append(waiting, pendingRelease{Release: current, FirstSeenAt: item.FirstSeenAt})
+			continue
+		}
+
+		result = append(result, types.ReleaseEvent{Release: current, Type: types.EventNew})
+	}
+
+	return result, waiting, nil
+}
+
+// putPending puts the pending releases into the bucket. It must be called after the releases are put, otherwise the
+// matured releases are dropped from the pending releases before they are stored and announced as new again.
+func (r *ReleaseChecker) putPending(projectName string, waiting []pendingRelease) error {
+	if r.MinAge <= 0 {
+		return nil
+	}
+
+	return aws.PutObject(r.S3ClientAPI, r.bucketName, fmt.Sprintf("%s/%s", projectName, pendingFileKey), &waiting)
+}
+
+// versionOf returns the tag of the release in the link of the entry, the titles of the releases are often renamed
+// by the maintainers so the title is only used if the link has no tag
+func versionOf(item *gofeed.Item) string {
+	if matches := releaseTagRegex.FindStringSubmatch(item.Link); len(matches) > 1 {
+		if tag, err := url.PathUnescape(matches[1]); err == nil && tag != "" {
+			return tag
+		}
+	}
+
+	return item.Title
+}
+
 func (r *ReleaseChecker) getReleasesFromFeed(projectName string, items []*gofeed.Item) []types.Release {
 	var releases []types.Release
 	for _, item := range items {
@@ -179,10 +342,12 @@ func (r *ReleaseChecker) getReleasesFromFeed(projectName string, items []*gofeed
 		if len(matches) > 0 {
 			releases = append(releases, types.Release{
 				ProjectName: projectName,
-				Version:     item.Title,
+				Version:     versionOf(item),
 				PublishedAt: item.PublishedParsed,
 				UpdatedAt:   item.UpdatedParsed,
 				Url:         item.Link,
+				Notes:       notesOf(item),
+				Security:    security.Detect(item.Title, item.Description, item.Content),
 			})
 		}
 	}
@@ -190,16 +355,83 @@ func (r *ReleaseChecker) getReleasesFromFeed(projectName string, items []*gofeed
 	return releases
 }
 
-func (r *ReleaseChecker) getDiff(fetchedReleases []types.Release, previousReleases []types.Release) (diff []types.Release) {
+// getDiff compares the fetched releases with the previous ones by project and version and classifies them as new,
+// updated or removed. Since the feed only contains the latest releases, a previous release is considered as removed
+// only if it is not older than the oldest fetched release.
+func (r *ReleaseChecker) getDiff(fetchedReleases []types.Release, previousReleases []types.Release) (diff []types.ReleaseEvent) {
 	for _, item := range fetchedReleases {
-		if !r.contains(previousReleases, item) {
-			diff = append(diff, item)
+		previous, ok := findVersion(previousReleases, item)
+		if !ok {
+			diff = append(diff, types.ReleaseEvent{Release: item, Type: types.EventNew})
+			continue
+		}
+
+		if changes := previous.ChangedFields(item); len(changes) > 0 {
+			diff = append(diff, types.ReleaseEvent{Release: item, Type: types.EventUpdated, Changes: changes})
 		}
 	}
 
+	oldest := oldestPublishedAt(fetchedReleases)
+	for _, item := range previousReleases {
+		if _, ok := findVersion(fetchedReleases, item); ok {
+			continue
+		}
+
+		if oldest == nil || item.PublishedAt == nil || item.PublishedAt.Before(*oldest) {
+			continue
+		}
+
+		diff = append(diff, types.ReleaseEvent{Release: item, Type: types.EventRemoved})
+	}
+
 	return diff
 }
 
+// applyEvents applies the events to the previous releases and returns the releases to be stored
+func applyEvents(previousReleases []types.Release, events []types.ReleaseEvent) []types.Release {
+	var releases []types.Release
+	for _, event := range events {
+		if event.Type == types.EventNew {
+			releases = append(releases, event.Release)
+		}
+	}
+
+	for _, item := range previousReleases {
+		event, ok := findEvent(events, item)
+		switch {
+		case !ok:
+			releases = append(releases, item)
+		case event.Type == types.EventUpdated:
+			releases = append(releases, event.Release)
+		}
+	}
+
+	return releases
+}
+
+// findEvent returns the event which points to the same version with the given release
+func findEvent(events []types.ReleaseEvent, release types.Release) (types.ReleaseEvent, bool) {
+	for _, event := range events {
+		if event.IsSameVersion(release) {
+			return event, true
+		}
+	}
+
+	return types.ReleaseEvent{}, false
+}
+
+// oldestPublishedAt returns the publish time of the oldest release, nil if none of them has a publish time
+func oldestPublishedAt(releases []types.Release) *time.Time {
+	var oldest *time.Time
+	for _, item := range releases {
+		if item.PublishedAt != nil && (oldest == nil || item.PublishedAt.Before(*oldest)) {
+			oldest = item.PublishedAt
+		}
+	}
+
+	return oldest
+}
+
 func (r *ReleaseChecker) extractProjectName() (string, error) {
 	u, err := url.Parse(r.Url)
 	if err != nil {
@@ -220,18 +452,24 @@ func (r *ReleaseChecker) extractProjectName() (string, error) {
 	return fmt.Sprintf("%s/%s", parts[1], parts[2]), nil
 }
 
-func (r *ReleaseChecker) contains(releases []types.Release, release types.Release) bool {
-	for _, item := range releases {
-		if release.ProjectName == item.ProjectName &&
-			release.Version == item.Version &&
-			release.Url == item.Url &&
-			(release.PublishedAt == nil && item.PublishedAt == nil ||
-				release.PublishedAt != nil && item.PublishedAt != nil && release.PublishedAt.Equal(*item.PublishedAt)) &&
-			(release.UpdatedAt == nil && item.UpdatedAt == nil ||
-				release.UpdatedAt != nil && item.UpdatedAt != nil && release.UpdatedAt.Equal(*item.UpdatedAt)) {
+// containsPending checks if the release is already pending
+func containsPending(pending []pendingRelease, release types.Release) bool {
+	for _, item := range pending {
+		if item.IsSameVersion(release) {
 			return true
 		}
 	}
 
 	return false
 }
+
+// findVersion returns the release in releases which points to the same version with the given release
+func findVersion(releases []types.Release, release types.Release) (types.Release, bool) {
+	for _, item := range releases {
+		if item.IsSameVersion(release) {
+			return item, true
+		}
+	}
+
+	return types.Release{}, false
+}
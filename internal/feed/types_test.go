@@ -3,15 +3,18 @@
 package feed
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"os"
 	"strings"
 	"testing"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/bilalcaliskan/rss-feed-filterer/internal/announce/slack"
 	api "github.com/slack-go/slack"
 	"github.com/stretchr/testify/assert"
@@ -21,6 +24,7 @@ import (
 	"github.com/bilalcaliskan/rss-feed-filterer/internal/config"
 	"github.com/bilalcaliskan/rss-feed-filterer/internal/logging"
 	"github.com/bilalcaliskan/rss-feed-filterer/internal/storage/aws"
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/types"
 	"github.com/mmcdole/gofeed"
 	"github.com/stretchr/testify/mock"
 )
@@ -328,7 +332,7 @@ func TestReleaseChecker_CheckGithubReleases(t *testing.T) {
 			false,
 			10,
 			func(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
-				return nil, &types.NoSuchKey{}
+				return nil, &s3types.NoSuchKey{}
 			},
 			func(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
 				content, err := os.ReadFile("../../test/releases.json")
@@ -358,7 +362,7 @@ func TestReleaseChecker_CheckGithubReleases(t *testing.T) {
 			false,
 			10,
 			func(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
-				return nil, &types.NoSuchKey{}
+				return nil, &s3types.NoSuchKey{}
 			},
 			func(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
 				content, err := os.ReadFile("../../test/releases.json")
@@ -433,3 +437,430 @@ func getTimeFromString(str string) *time.Time {
 
 	return &t
 }
+
+func TestReleaseChecker_holdBackPending(t *testing.T) {
+	now := time.Now()
+	young := now.Add(-1 * time.Hour)
+	old := now.Add(-48 * time.Hour)
+
+	fetched := []types.Release{
+		{ProjectName: "user1/project1", Version: "v1.0.2", PublishedAt: &young},
+		{ProjectName: "user1/project1", Version: "v1.0.1", PublishedAt: &old},
+	}
+
+	cases := []struct {
+		caseName        string
+		minAge          time.Duration
+		diff            []types.ReleaseEvent
+		pending         []types.Release
+		getErr          error
+		expectedReady   []string
+		expectedPending []string
+		shouldPass      bool
+	}{
+		{
+			"Gate disabled",
+			0,
+			newEvents(fetched),
+			nil,
+			nil,
+			[]string{"v1.0.2", "v1.0.1"},
+			nil,
+			true,
+		},
+		{
+			"Young release is kept as pending",
+			24 * time.Hour,
+			newEvents(fetched),
+			nil,
+			nil,
+			[]string{"v1.0.1"},
+			[]string{"v1.0.2"},
+			true,
+		},
+		{
+			"Yanked pending release is dropped",
+			24 * time.Hour,
+			nil,
+			[]types.Release{{ProjectName: "user1/project1", Version: "v1.0.3", PublishedAt: &young}},
+			nil,
+			nil,
+			nil,
+			true,
+		},
+		{
+			"Pending release becomes ready",
+			24 * time.Hour,
+			nil,
+			[]types.Release{{ProjectName: "user1/project1", Version: "v1.0.1", PublishedAt: &old}},
+			nil,
+			[]string{"v1.0.1"},
+			nil,
+			true,
+		},
+		{
+			"Failure caused by get error",
+			24 * time.Hour,
+			newEvents(fetched),
+			[]types.Release{},
+			errors.New("injected error"),
+			nil,
+			nil,
+			false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		mockS3 := new(aws.MockS3Client)
+		mockS3.HeadObjectAPI = func(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+			if tc.pending == nil {
+				return nil, &s3types.NoSuchKey{}
+			}
+
+			return &s3.HeadObjectOutput{}, nil
+		}
+		mockS3.GetObjectAPI = func(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+			if tc.getErr != nil {
+				return nil, tc.getErr
+			}
+
+			content, _ := json.Marshal(tc.pending)
+			return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(content))}, nil
+		}
+		mockS3.PutObjectAPI = func(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+			t.Errorf("unexpected put of %s", *params.Key)
+			return &s3.PutObjectOutput{}, nil
+		}
+
+		repo := config.Repository{Name: "project1", Url: "https://github.com/user1/project1", MinAge: tc.minAge}
+		rc := NewReleaseChecker(mockS3, repo, make(chan struct{}, 1), new(MockParser), "thisisdummybucket", logging.GetLogger(), nil)
+
+		ready, waiting, err := rc.holdBackPending("user1/project1", fetched, tc.diff)
+		if !tc.shouldPass {
+			assert.NotNil(t, err)
+			continue
+		}
+
+		assert.Nil(t, err)
+		var readyReleases, pendingReleases []types.Release
+		for _, event := range ready {
+			readyReleases = append(readyReleases, event.Release)
+		}
+
+		for _, item := range waiting {
+			pendingReleases = append(pendingReleases, item.Release)
+		}
+
+		assert.Equal(t, tc.expectedReady, versionsOf(readyReleases))
+		assert.Equal(t, tc.expectedPending, versionsOf(pendingReleases))
+	}
+}
+
+func TestReleaseChecker_putPending(t *testing.T) {
+	firstSeenAt := time.Now()
+	waiting := []pendingRelease{{Release: types.Release{ProjectName: "user1/project1", Version: "v1.0.2"}, FirstSeenAt: &firstSeenAt}}
+
+	cases := []struct {
+		caseName        string
+		minAge          time.Duration
+		putErr          error
+		expectedPending []string
+		shouldPass      bool
+	}{
+		{"Gate disabled", 0, nil, nil, true},
+		{"Pending releases are put", 24 * time.Hour, nil, []string{"v1.0.2"}, true},
+		{"Failure caused by put error", 24 * time.Hour, errors.New("injected error"), nil, false},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		var putReleases []types.Release
+		mockS3 := new(aws.MockS3Client)
+		mockS3.PutObjectAPI = func(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+			assert.Equal(t, "user1/project1/pending_releases.json", *params.Key)
+			assert.Nil(t, json.NewDecoder(params.Body).Decode(&putReleases))
+			return &s3.PutObjectOutput{}, tc.putErr
+		}
+
+		repo := config.Repository{Name: "project1", Url: "https://github.com/user1/project1", MinAge: tc.minAge}
+		rc := NewReleaseChecker(mockS3, repo, make(chan struct{}, 1), new(MockParser), "thisisdummybucket", logging.GetLogger(), nil)
+
+		err := rc.putPending("user1/project1", waiting)
+		if !tc.shouldPass {
+			assert.NotNil(t, err)
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.Equal(t, tc.expectedPending, versionsOf(putReleases))
+	}
+}
+
+func TestReleaseChecker_checkFeed_pendingAfterReleases(t *testing.T) {
+	publishedAt := getTimeFromString("2023-08-04T12:21:41Z")
+	feed := &gofeed.Feed{Items: []*gofeed.Item{
+		{Title: "v1.0.2", Link: "https://github.com/user1/project1/releases/tag/v1.0.2", PublishedParsed: publishedAt, UpdatedParsed: publishedAt},
+		{Title: "v1.0.1", Link: "https://github.com/user1/project1/releases/tag/v1.0.1", PublishedParsed: publishedAt, UpdatedParsed: publishedAt},
+	}}
+
+	cases := []struct {
+		caseName     string
+		putErr       error
+		expectedPuts []string
+	}{
+		{"Pending releases are put after the releases", nil, []string{"releases.json", "pending_releases.json"}},
+		{"Matured releases stay pending if the releases are not put", errors.New("injected error"),
+			[]string{"releases.json", "releases.json", "releases.json"}},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		var puts []string
+		mockS3 := new(aws.MockS3Client)
+		mockS3.HeadObjectAPI = func(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+			return &s3.HeadObjectOutput{}, nil
+		}
+		mockS3.GetObjectAPI = func(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+			content := []byte("[]")
+			if *params.Key == "user1/project1/"+pendingFileKey {
+				content = []byte(`[{"projectName":"user1/project1","version":"v1.0.2","publishedAt":"2023-08-04T12:21:41Z"}]`)
+			}
+
+			return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(content))}, nil
+		}
+		mockS3.PutObjectAPI = func(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+			puts = append(puts, strings.TrimPrefix(*params.Key, "user1/project1/"))
+			if *params.Key == "user1/project1/"+releaseFileKey {
+				return &s3.PutObjectOutput{}, tc.putErr
+			}
+
+			return &s3.PutObjectOutput{}, nil
+		}
+
+		parser := new(MockParser)
+		parser.On("ParseURL", mock.AnythingOfType("string")).Return(feed, nil)
+
+		repo := config.Repository{Name: "project1", Url: "https://github.com/user1/project1", MinAge: 24 * time.Hour}
+		rc := NewReleaseChecker(mockS3, repo, make(chan struct{}, 1), parser, "thisisdummybucket", logging.GetLogger(), nil)
+		rc.checkFeed("user1/project1", repo)
+
+		assert.Equal(t, tc.expectedPuts, puts)
+	}
+}
+
+// versionsOf returns the versions of given releases
+func versionsOf(releases []types.Release) []string {
+	var versions []string
+	for _, release := range releases {
+		versions = append(versions, release.Version)
+	}
+
+	return versions
+}
+
+// newEvents wraps the releases as new release events
+func newEvents(releases []types.Release) []types.ReleaseEvent {
+	var events []types.ReleaseEvent
+	for _, release := range releases {
+		events = append(events, types.ReleaseEvent{Release: release, Type: types.EventNew})
+	}
+
+	return events
+}
+
+func TestReleaseChecker_getDiff(t *testing.T) {
+	older := getTimeFromString("2023-08-01T12:21:41Z")
+	first := getTimeFromString("2023-08-04T12:21:41Z")
+	second := getTimeFromString("2023-08-05T12:21:41Z")
+	third := getTimeFromString("2023-08-06T12:21:41Z")
+
+	previous := []types.Release{
+		{ProjectName: "user1/project1", Version: "v0.9.0", PublishedAt: older, UpdatedAt: older},
+		{ProjectName: "user1/project1", Version: "v1.0.0", PublishedAt: first, UpdatedAt: first},
+		{ProjectName: "user1/project1", Version: "v1.0.1", PublishedAt: second, UpdatedAt: second},
+	}
+
+	fetched := []types.Release{
+		{ProjectName: "user1/project1", Version: "v1.0.2", PublishedAt: third, UpdatedAt: third},
+		{ProjectName: "user1/project1", Version: "v1.0.0", PublishedAt: first, UpdatedAt: third},
+	}
+
+	rc := NewReleaseChecker(nil, config.Repository{}, nil, nil, "thisisdummybucket", logging.GetLogger(), nil)
+	diff := rc.getDiff(fetched, previous)
+
+	assert.Equal(t, []types.ReleaseEvent{
+		{Release: fetched[0], Type: types.EventNew},
+		{Release: fetched[1], Type: types.EventUpdated, Changes: []string{"updatedAt"}},
+		{Release: previous[2], Type: types.EventRemoved},
+	}, diff)
+
+	assert.Empty(t, rc.getDiff(previous, previous))
+
+	assert.Equal(t, []types.Release{fetched[0], previous[0], fetched[1]}, applyEvents(previous, diff))
+}
+
+func TestReleaseChecker_getDiff_notes(t *testing.T) {
+	publishedAt := getTimeFromString("2023-08-04T12:21:41Z")
+	release := func(notes string) []types.Release {
+		return []types.Release{{ProjectName: "user1/project1", Version: "v1.0.0", PublishedAt: publishedAt,
+			UpdatedAt: publishedAt, Notes: notes}}
+	}
+
+	cases := []struct {
+		caseName        string
+		previousNotes   string
+		fetchedNotes    string
+		expectedChanges []string
+	}{
+		{"Same notes", "<p>Fixes bug</p>", "<p>Fixes bug</p>", nil},
+		{"Edited notes", "<p>Fixes bug</p>", "<p>Fixes bug and adds flag</p>", []string{"notes"}},
+		{"Release stored without notes", "", "<p>Fixes bug</p>", nil},
+		{"Notes fetched from the api are not in the feed", "<p>Fixes bug</p>", "", nil},
+	}
+
+	rc := NewReleaseChecker(nil, config.Repository{}, nil, nil, "thisisdummybucket", logging.GetLogger(), nil)
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		var changes []string
+		for _, event := range rc.getDiff(release(tc.fetchedNotes), release(tc.previousNotes)) {
+			assert.Equal(t, types.EventUpdated, event.Type)
+			changes = append(changes, event.Changes...)
+		}
+
+		assert.Equal(t, tc.expectedChanges, changes)
+	}
+}
+
+type fakeNotesFetcher struct{}
+
+func (f *fakeNotesFetcher) Fetch(projectName, tag string) (string, error) {
+	if tag == "v1.0.1" {
+		return "", errors.New("injected error")
+	}
+
+	return fmt.Sprintf("<p>%s %s fixes CVE-2023-12345</p>", projectName, tag), nil
+}
+
+func TestReleaseChecker_fillNotes(t *testing.T) {
+	events := []types.ReleaseEvent{
+		{Release: types.Release{Version: "v1.0.0", Url: "https://github.com/user1/project1/releases/tag/v1.0.0"}, Type: types.EventNew},
+		{Release: types.Release{Version: "v1.0.1", Url: "https://github.com/user1/project1/releases/tag/v1.0.1"}, Type: types.EventNew},
+		{Release: types.Release{Version: "v1.0.2", Url: "https://github.com/user1/project1/releases/tag/v1.0.2", Notes: "<p>feed notes</p>"}, Type: types.EventUpdated},
+		{Release: types.Release{Version: "v1.0.3", Url: "https://github.com/user1/project1/releases/tag/v1.0.3"}, Type: types.EventRemoved},
+	}
+
+	rc := NewReleaseChecker(nil, config.Repository{}, nil, nil, "thisisdummybucket", logging.GetLogger(), nil)
+	rc.notesFetcher = &fakeNotesFetcher{}
+	rc.fillNotes("user1/project1", events)
+
+	assert.Equal(t, "<p>user1/project1 v1.0.0 fixes CVE-2023-12345</p>", events[0].Notes)
+	assert.Equal(t, &types.Security{CVEs: []string{"CVE-2023-12345"}}, events[0].Security)
+	assert.Equal(t, "", events[1].Notes)
+	assert.Equal(t, "<p>feed notes</p>", events[2].Notes)
+	assert.Equal(t, "", events[3].Notes)
+}
+
+func TestReleaseChecker_getReleasesFromFeed(t *testing.T) {
+	rc := NewReleaseChecker(nil, config.Repository{}, nil, nil, "thisisdummybucket", logging.GetLogger(), nil)
+	releases := rc.getReleasesFromFeed("user1/project1", []*gofeed.Item{
+		{
+			Title:   "v1.0.0",
+			Link:    "https://github.com/user1/project1/releases/tag/v1.0.0",
+			Content: "<p>Fixes a security issue</p>",
+		},
+		{
+			Title: "not a release",
+			Link:  "https://github.com/user1/project1",
+		},
+	})
+
+	assert.Len(t, releases, 1)
+	assert.Equal(t, "v1.0.0", releases[0].Version)
+	assert.Equal(t, "<p>Fixes a security issue</p>", releases[0].Notes)
+	assert.Equal(t, &types.Security{Keywords: []string{"security"}}, releases[0].Security)
+}
+
+func TestReleaseChecker_getDiff_renamedTitle(t *testing.T) {
+	publishedAt := getTimeFromString("2023-08-04T12:21:41Z")
+	link := "https://github.com/user1/project1/releases/tag/v1.0.0"
+	item := func(title string) []*gofeed.Item {
+		return []*gofeed.Item{{Title: title, Link: link, PublishedParsed: publishedAt, UpdatedParsed: publishedAt}}
+	}
+
+	rc := NewReleaseChecker(nil, config.Repository{}, nil, nil, "thisisdummybucket", logging.GetLogger(), nil)
+	previous := rc.getReleasesFromFeed("user1/project1", item("v1.0.0"))
+	renamed := rc.getReleasesFromFeed("user1/project1", item("v1.0.0 - The big release"))
+
+	assert.Equal(t, "v1.0.0", renamed[0].Version)
+	assert.Empty(t, rc.getDiff(renamed, previous))
+
+	// the releases stored with their titles as the versions are matched by their links
+	stored := []types.Release{{ProjectName: "user1/project1", Version: "Release 1.0.0", PublishedAt: publishedAt,
+		UpdatedAt: publishedAt, Url: link}}
+	assert.Empty(t, rc.getDiff(renamed, stored))
+
+	// the title is the version if the link has no tag
+	assert.Equal(t, "v2.0.0", versionOf(&gofeed.Item{Title: "v2.0.0", Link: "https://example.com/download/v2.0.0"}))
+}
+
+func TestReleaseChecker_holdBackPending_withoutPublishedAt(t *testing.T) {
+	now := time.Now()
+	young := now.Add(-1 * time.Hour)
+	old := now.Add(-48 * time.Hour)
+	fetched := []types.Release{{ProjectName: "user1/project1", Version: "v1.0.0"}}
+
+	cases := []struct {
+		caseName        string
+		diff            []types.ReleaseEvent
+		pending         []pendingRelease
+		expectedReady   []string
+		expectedPending []string
+	}{
+		{"New release is kept as pending", newEvents(fetched), nil, nil, []string{"v1.0.0"}},
+		{"Recently seen release is kept as pending", nil, []pendingRelease{{Release: fetched[0], FirstSeenAt: &young}}, nil, []string{"v1.0.0"}},
+		{"Release seen long ago becomes ready", nil, []pendingRelease{{Release: fetched[0], FirstSeenAt: &old}}, []string{"v1.0.0"}, nil},
+		{"Pending release of the previous versions is kept", nil, []pendingRelease{{Release: fetched[0]}}, nil, []string{"v1.0.0"}},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+
+		mockS3 := new(aws.MockS3Client)
+		mockS3.HeadObjectAPI = func(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+			if tc.pending == nil {
+				return nil, &s3types.NoSuchKey{}
+			}
+
+			return &s3.HeadObjectOutput{}, nil
+		}
+		mockS3.GetObjectAPI = func(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+			content, _ := json.Marshal(tc.pending)
+			return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(content))}, nil
+		}
+
+		repo := config.Repository{Name: "project1", Url: "https://github.com/user1/project1", MinAge: 24 * time.Hour}
+		rc := NewReleaseChecker(mockS3, repo, make(chan struct{}, 1), new(MockParser), "thisisdummybucket", logging.GetLogger(), nil)
+
+		ready, waiting, err := rc.holdBackPending("user1/project1", fetched, tc.diff)
+		assert.Nil(t, err)
+
+		var readyReleases, pendingReleases []types.Release
+		for _, event := range ready {
+			readyReleases = append(readyReleases, event.Release)
+		}
+
+		for _, item := range waiting {
+			// the first seen time is stored with the pending releases
+			assert.NotNil(t, item.FirstSeenAt)
+			pendingReleases = append(pendingReleases, item.Release)
+		}
+
+		assert.Equal(t, tc.expectedReady, versionsOf(readyReleases))
+		assert.Equal(t, tc.expectedPending, versionsOf(pendingReleases))
+	}
+}
@@ -12,7 +12,7 @@ var (
 )
 
 func init() {
-	consoleWriter := zerolog.ConsoleWriter{Out: os.Stdout}
+	consoleWriter := zerolog.ConsoleWriter{Out: os.Stderr}
 	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
 	logger = zerolog.New(consoleWriter).With().Timestamp().Logger().Level(Level)
 }
@@ -0,0 +1,124 @@
+package notes
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	// breakingSectionRegex matches the titles of the sections which contain breaking changes
+	breakingSectionRegex = regexp.MustCompile(`(?i)\b(breaking|deprecations?|deprecated|upgrade notes?|upgrading notes?)\b`)
+	// negatedBreakingRegex matches the negated mentions like "Non-breaking changes" and "No breaking changes", they are
+	// removed from the titles before matching them with breakingSectionRegex
+	negatedBreakingRegex = regexp.MustCompile(`(?i)\b(non|no)[\s-]+breaking\b`)
+	// breakingItemRegex matches the list items which are marked as breaking change
+	breakingItemRegex = regexp.MustCompile(`^\W*BREAKING\b`)
+)
+
+// isBreakingSection checks if the title is the title of a breaking changes section
+func isBreakingSection(title string) bool {
+	return breakingSectionRegex.MatchString(negatedBreakingRegex.ReplaceAllString(title, ""))
+}
+
+// pseudoHeadingLevel is the level of the paragraphs which only contain a bold text and act like a heading
+const pseudoHeadingLevel = 7
+
+// BreakingChanges extracts the sections like "Breaking Changes", "Deprecations" and "Upgrade notes" and the list
+// items marked as "BREAKING" from the HTML release notes. It returns them as HTML, empty if there is none.
+func BreakingChanges(notes string) string {
+	if strings.TrimSpace(notes) == "" {
+		return ""
+	}
+
+	nodes, err := html.ParseFragment(strings.NewReader(notes), &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body})
+	if err != nil {
+		return ""
+	}
+
+	var sections, items []*html.Node
+	sectionLevel := 0
+	for _, node := range nodes {
+		level := headingLevel(node)
+		if sectionLevel > 0 && level > 0 && level <= sectionLevel {
+			sectionLevel = 0
+		}
+
+		if sectionLevel == 0 && level > 0 && isBreakingSection(textContent(node)) {
+			sectionLevel = level
+		}
+
+		if sectionLevel > 0 {
+			sections = append(sections, node)
+			continue
+		}
+
+		items = append(items, breakingItems(node)...)
+	}
+
+	var buf bytes.Buffer
+	for _, node := range sections {
+		_ = html.Render(&buf, node)
+	}
+
+	if len(items) > 0 {
+		buf.WriteString("<ul>")
+		for _, item := range items {
+			_ = html.Render(&buf, item)
+		}
+		buf.WriteString("</ul>")
+	}
+
+	return buf.String()
+}
+
+// headingLevel returns the level of the heading node, 0 if the node is not a heading
+func headingLevel(node *html.Node) int {
+	switch node.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		return int(node.Data[1] - '0')
+	case atom.P:
+		if child := soleElement(node); child != nil && (child.DataAtom == atom.Strong || child.DataAtom == atom.B) {
+			return pseudoHeadingLevel
+		}
+	}
+
+	return 0
+}
+
+// breakingItems returns the list items under the node which start with "BREAKING"
+func breakingItems(node *html.Node) []*html.Node {
+	if node.DataAtom == atom.Li {
+		if breakingItemRegex.MatchString(strings.TrimSpace(textContent(node))) {
+			return []*html.Node{node}
+		}
+
+		return nil
+	}
+
+	var items []*html.Node
+	for c := node.FirstChild; c != nil; c = c.NextSibling {
+		items = append(items, breakingItems(c)...)
+	}
+
+	return items
+}
+
+// soleElement returns the only element child of the node, text children other than colons are not allowed
+func soleElement(node *html.Node) *html.Node {
+	var element *html.Node
+	for c := node.FirstChild; c != nil; c = c.NextSibling {
+		switch {
+		case c.Type == html.ElementNode && element == nil:
+			element = c
+		case c.Type == html.TextNode && strings.Trim(c.Data, ": \t\r\n") == "":
+		default:
+			return nil
+		}
+	}
+
+	return element
+}
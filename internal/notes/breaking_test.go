@@ -0,0 +1,65 @@
+//go:build unit
+
+package notes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreakingChanges(t *testing.T) {
+	cases := []struct {
+		caseName string
+		notes    string
+		expected string
+	}{
+		{
+			"No breaking changes",
+			`<h2>What's Changed</h2><ul><li>Fix bug</li></ul>`,
+			"",
+		},
+		{
+			"Empty notes",
+			"",
+			"",
+		},
+		{
+			"Heading sections",
+			`<h2>Breaking Changes</h2><ul><li>Drop go 1.20</li></ul><h3>Details</h3><p>more</p>` +
+				`<h2>Features</h2><ul><li>New flag</li></ul><h2>Deprecations</h2><p>Old flag is deprecated</p>`,
+			`<h2>Breaking Changes</h2><ul><li>Drop go 1.20</li></ul><h3>Details</h3><p>more</p>` +
+				`<h2>Deprecations</h2><p>Old flag is deprecated</p>`,
+		},
+		{
+			"Non-breaking changes section",
+			`<h2>Non-breaking changes</h2><ul><li>New flag</li></ul>`,
+			"",
+		},
+		{
+			"No breaking changes section",
+			`<h2>No breaking changes</h2><p>This release is a drop-in replacement</p>`,
+			"",
+		},
+		{
+			"Breaking and non-breaking changes section",
+			`<h2>Breaking and non-breaking changes</h2><ul><li>Drop go 1.20</li></ul>`,
+			`<h2>Breaking and non-breaking changes</h2><ul><li>Drop go 1.20</li></ul>`,
+		},
+		{
+			"Bold paragraph sections",
+			`<p><strong>Upgrade notes:</strong></p><ul><li>Run migrations</li></ul><p><strong>Features</strong></p><ul><li>New flag</li></ul>`,
+			`<p><strong>Upgrade notes:</strong></p><ul><li>Run migrations</li></ul>`,
+		},
+		{
+			"Marked list items",
+			`<h2>Changes</h2><ul><li><strong>BREAKING</strong>: rename config key</li><li>Fix bug</li><li>BREAKING CHANGE: drop v1 api</li></ul>`,
+			`<ul><li><strong>BREAKING</strong>: rename config key</li><li>BREAKING CHANGE: drop v1 api</li></ul>`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+		assert.Equal(t, tc.expected, BreakingChanges(tc.notes))
+	}
+}
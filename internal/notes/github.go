@@ -0,0 +1,70 @@
+package notes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const defaultGithubApiUrl = "https://api.github.com"
+
+// Fetcher fetches the release notes of a release from an external source
+type Fetcher interface {
+	Fetch(projectName, tag string) (string, error)
+}
+
+// GithubFetcher fetches the release notes as HTML from the GitHub releases API
+type GithubFetcher struct {
+	client  *http.Client
+	baseUrl string
+	token   string
+}
+
+// NewGithubFetcher creates a new GithubFetcher, baseUrl defaults to the public GitHub API and token is optional
+func NewGithubFetcher(baseUrl, token string) *GithubFetcher {
+	if baseUrl == "" {
+		baseUrl = defaultGithubApiUrl
+	}
+
+	return &GithubFetcher{
+		client:  &http.Client{Timeout: 10 * time.Second},
+		baseUrl: strings.TrimSuffix(baseUrl, "/"),
+		token:   token,
+	}
+}
+
+// Fetch returns the rendered release notes of the release with given tag, projectName is in owner/repo format
+func (g *GithubFetcher) Fetch(projectName, tag string) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/repos/%s/releases/tags/%s", g.baseUrl, projectName, url.PathEscape(tag)), nil)
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Accept", "application/vnd.github.html+json")
+	if g.token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.token)
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code %d while fetching release notes", resp.StatusCode)
+	}
+
+	var release struct {
+		BodyHtml string `json:"body_html"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return "", err
+	}
+
+	return release.BodyHtml, nil
+}
@@ -0,0 +1,38 @@
+//go:build unit
+
+package notes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGithubFetcher_Fetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/user1/project1/releases/tags/v1.0.0" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		assert.Equal(t, "application/vnd.github.html+json", r.Header.Get("Accept"))
+		assert.Equal(t, "Bearer dummytoken", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"tag_name": "v1.0.0", "body_html": "<p>Bug fixes</p>"}`))
+	}))
+	defer server.Close()
+
+	fetcher := NewGithubFetcher(server.URL+"/", "dummytoken")
+
+	notes, err := fetcher.Fetch("user1/project1", "v1.0.0")
+	assert.Nil(t, err)
+	assert.Equal(t, "<p>Bug fixes</p>", notes)
+
+	_, err = fetcher.Fetch("user1/project1", "v9.9.9")
+	assert.NotNil(t, err)
+}
+
+func TestNewGithubFetcher(t *testing.T) {
+	assert.Equal(t, defaultGithubApiUrl, NewGithubFetcher("", "").baseUrl)
+}
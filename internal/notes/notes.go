@@ -0,0 +1,394 @@
+package notes
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	spaceRegex   = regexp.MustCompile(`[ \t\r\n]+`)
+	newlineRegex = regexp.MustCompile(`\n{3,}`)
+	// safeLinkRegex matches the link schemes kept in the HTML output
+	safeLinkRegex = regexp.MustCompile(`(?i)^(https?|mailto):`)
+)
+
+// dialect describes how the formatting elements of the release notes are rendered for a channel. The text passed
+// to bold, italic, link and heading is already escaped while code and pre receive the raw text.
+type dialect struct {
+	bullet  string
+	escape  func(string) string
+	bold    func(text string) string
+	italic  func(text string) string
+	code    func(raw string) string
+	pre     func(raw string) string
+	link    func(text, href string) string
+	heading func(text string) string
+}
+
+var (
+	textDialect = dialect{
+		bullet: "- ",
+		escape: identity,
+		bold:   identity,
+		italic: identity,
+		code:   identity,
+		pre:    identity,
+		link: func(text, href string) string {
+			if text == href || text == "" {
+				return href
+			}
+
+			return text + " (" + href + ")"
+		},
+		heading: strings.ToUpper,
+	}
+	markdownDialect = dialect{
+		bullet: "- ",
+		escape: strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`).Replace,
+		bold:   enclose("**", "**"),
+		italic: enclose("_", "_"),
+		code:   enclose("`", "`"),
+		pre:    enclose("```\n", "\n```"),
+		link: func(text, href string) string {
+			return "[" + text + "](" + href + ")"
+		},
+		heading: enclose("**", "**"),
+	}
+	slackDialect = dialect{
+		bullet: "• ",
+		escape: slackEscaper.Replace,
+		bold:   enclose("*", "*"),
+		italic: enclose("_", "_"),
+		code: func(raw string) string {
+			return "`" + slackEscaper.Replace(raw) + "`"
+		},
+		pre: func(raw string) string {
+			return "```\n" + slackEscaper.Replace(raw) + "\n```"
+		},
+		link: func(text, href string) string {
+			return "<" + href + "|" + text + ">"
+		},
+		heading: enclose("*", "*"),
+	}
+	telegramHTMLDialect = dialect{
+		bullet: "• ",
+		escape: html.EscapeString,
+		bold:   enclose("<b>", "</b>"),
+		italic: enclose("<i>", "</i>"),
+		code: func(raw string) string {
+			return "<code>" + html.EscapeString(raw) + "</code>"
+		},
+		pre: func(raw string) string {
+			return "<pre>" + html.EscapeString(raw) + "</pre>"
+		},
+		link: func(text, href string) string {
+			return `<a href="` + html.EscapeString(href) + `">` + text + "</a>"
+		},
+		heading: enclose("<b>", "</b>"),
+	}
+	htmlDialect = dialect{
+		bullet: "• ",
+		escape: html.EscapeString,
+		bold:   enclose("<strong>", "</strong>"),
+		italic: enclose("<em>", "</em>"),
+		code: func(raw string) string {
+			return "<code>" + html.EscapeString(raw) + "</code>"
+		},
+		pre: func(raw string) string {
+			return "<pre>" + html.EscapeString(raw) + "</pre>"
+		},
+		link: func(text, href string) string {
+			// the other schemes like javascript: are dropped since the output is trusted by the HTML templates
+			if !safeLinkRegex.MatchString(href) {
+				return text
+			}
+
+			return `<a href="` + html.EscapeString(href) + `">` + text + "</a>"
+		},
+		heading: enclose("<strong>", "</strong>"),
+	}
+	telegramMarkdownDialect = dialect{
+		bullet: "• ",
+		escape: EscapeTelegramMarkdown,
+		bold:   enclose("*", "*"),
+		italic: enclose("_", "_"),
+		code: func(raw string) string {
+			return "`" + telegramCodeEscaper.Replace(raw) + "`"
+		},
+		pre: func(raw string) string {
+			return "```\n" + telegramCodeEscaper.Replace(raw) + "\n```"
+		},
+		link: func(text, href string) string {
+			return "[" + text + "](" + telegramLinkEscaper.Replace(href) + ")"
+		},
+		heading: enclose("*", "*"),
+	}
+
+	slackEscaper        = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	telegramCodeEscaper = strings.NewReplacer(`\`, `\\`, "`", "\\`")
+	telegramLinkEscaper = strings.NewReplacer(`\`, `\\`, ")", `\)`)
+	telegramEscaper     = regexp.MustCompile("([_*\\[\\]()~`>#+\\-=|{}.!\\\\])")
+)
+
+func identity(s string) string {
+	return s
+}
+
+func enclose(prefix, suffix string) func(string) string {
+	return func(s string) string {
+		return prefix + s + suffix
+	}
+}
+
+// EscapeTelegramMarkdown escapes the reserved characters of the Telegram MarkdownV2 format
+func EscapeTelegramMarkdown(text string) string {
+	return telegramEscaper.ReplaceAllString(text, `\$1`)
+}
+
+// ToText converts the HTML release notes into plain text
+func ToText(notes string) string {
+	return render(notes, textDialect)
+}
+
+// ToMarkdown converts the HTML release notes into Markdown
+func ToMarkdown(notes string) string {
+	return render(notes, markdownDialect)
+}
+
+// ToSlack converts the HTML release notes into Slack mrkdwn format
+func ToSlack(notes string) string {
+	return render(notes, slackDialect)
+}
+
+// ToHTML converts the HTML release notes into the sanitized HTML with only the formatting elements and the http, https
+// and mailto links, the line breaks are kept as newlines so it should be displayed with the pre-wrap white space
+func ToHTML(notes string) string {
+	return render(notes, htmlDialect)
+}
+
+// ToTelegramHTML converts the HTML release notes into the HTML subset supported by Telegram
+func ToTelegramHTML(notes string) string {
+	return render(notes, telegramHTMLDialect)
+}
+
+// ToTelegramMarkdown converts the HTML release notes into Telegram MarkdownV2 format
+func ToTelegramMarkdown(notes string) string {
+	return render(notes, telegramMarkdownDialect)
+}
+
+// Truncate shortens the text to the given number of characters, an ellipsis is appended if it is truncated
+func Truncate(text string, limit int) string {
+	if limit <= 0 || utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+
+	runes := []rune(text)
+	return strings.TrimSpace(string(runes[:limit-1])) + "…"
+}
+
+// TruncateBytes shortens the text to the given number of bytes without splitting a character, an ellipsis is appended
+// if it is truncated and counted in the limit
+func TruncateBytes(text string, limit int) string {
+	const ellipsis = "…"
+	if limit <= len(ellipsis) || len(text) <= limit {
+		return text
+	}
+
+	end := limit - len(ellipsis)
+	for end > 0 && !utf8.RuneStart(text[end]) {
+		end--
+	}
+
+	return strings.TrimSpace(text[:end]) + ellipsis
+}
+
+func render(notes string, d dialect) string {
+	if strings.TrimSpace(notes) == "" {
+		return ""
+	}
+
+	nodes, err := html.ParseFragment(strings.NewReader(notes), &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body})
+	if err != nil {
+		return strings.TrimSpace(notes)
+	}
+
+	r := &renderer{dialect: d}
+	for _, node := range nodes {
+		r.walk(node, 0)
+	}
+
+	lines := strings.Split(r.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+
+	return strings.TrimSpace(newlineRegex.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+type renderer struct {
+	strings.Builder
+	dialect
+}
+
+// block starts a new paragraph unless the output is already at the beginning of one
+func (r *renderer) block() {
+	out := r.String()
+	if out == "" || strings.HasSuffix(out, "\n\n") {
+		return
+	}
+
+	if strings.HasSuffix(out, "\n") {
+		r.WriteString("\n")
+		return
+	}
+
+	r.WriteString("\n\n")
+}
+
+// line starts a new line unless the output is already at the beginning of one
+func (r *renderer) line() {
+	out := r.String()
+	if out != "" && !strings.HasSuffix(out, "\n") {
+		r.WriteString("\n")
+	}
+}
+
+// inline renders the children of the node as a single line of text
+func (r *renderer) inline(node *html.Node) string {
+	child := &renderer{dialect: r.dialect}
+	for c := node.FirstChild; c != nil; c = c.NextSibling {
+		child.walk(c, 0)
+	}
+
+	return strings.TrimSpace(spaceRegex.ReplaceAllString(child.String(), " "))
+}
+
+func (r *renderer) walk(node *html.Node, depth int) {
+	switch node.Type {
+	case html.TextNode:
+		text := spaceRegex.ReplaceAllString(node.Data, " ")
+		if out := r.String(); out == "" || strings.HasSuffix(out, "\n") || strings.HasSuffix(out, " ") {
+			text = strings.TrimLeft(text, " ")
+		}
+
+		r.WriteString(r.escape(text))
+		return
+	case html.ElementNode:
+	default:
+		for c := node.FirstChild; c != nil; c = c.NextSibling {
+			r.walk(c, depth)
+		}
+
+		return
+	}
+
+	switch node.DataAtom {
+	case atom.Script, atom.Style:
+		return
+	case atom.Br:
+		r.WriteString("\n")
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		r.block()
+		r.WriteString(r.heading(r.inline(node)))
+		r.block()
+	case atom.P, atom.Div, atom.Blockquote, atom.Table:
+		r.block()
+		r.children(node, depth)
+		r.block()
+	case atom.Ul, atom.Ol:
+		if depth == 0 {
+			r.block()
+		}
+
+		index := 0
+		for c := node.FirstChild; c != nil; c = c.NextSibling {
+			if c.DataAtom != atom.Li {
+				continue
+			}
+
+			index++
+			r.line()
+			r.WriteString(strings.Repeat("  ", depth))
+			if node.DataAtom == atom.Ol {
+				r.WriteString(r.escape(strconv.Itoa(index) + ". "))
+			} else {
+				r.WriteString(r.bullet)
+			}
+
+			r.children(c, depth+1)
+		}
+
+		if depth == 0 {
+			r.block()
+		}
+	case atom.Tr:
+		r.line()
+		r.children(node, depth)
+	case atom.Pre:
+		r.block()
+		r.WriteString(r.pre(strings.TrimRight(textContent(node), "\n")))
+		r.block()
+	case atom.Strong, atom.B:
+		r.wrap(node, r.bold)
+	case atom.Em, atom.I:
+		r.wrap(node, r.italic)
+	case atom.Code:
+		r.WriteString(r.code(textContent(node)))
+	case atom.A:
+		text := r.inline(node)
+		href := attr(node, "href")
+		if href == "" || strings.HasPrefix(href, "#") {
+			r.WriteString(text)
+			return
+		}
+
+		r.WriteString(r.link(text, href))
+	case atom.Img:
+		r.WriteString(r.escape(attr(node, "alt")))
+	default:
+		r.children(node, depth)
+	}
+}
+
+func (r *renderer) children(node *html.Node, depth int) {
+	for c := node.FirstChild; c != nil; c = c.NextSibling {
+		r.walk(c, depth)
+	}
+}
+
+func (r *renderer) wrap(node *html.Node, format func(string) string) {
+	text := r.inline(node)
+	if text == "" {
+		return
+	}
+
+	r.WriteString(format(text))
+}
+
+func textContent(node *html.Node) string {
+	if node.Type == html.TextNode {
+		return node.Data
+	}
+
+	var sb strings.Builder
+	for c := node.FirstChild; c != nil; c = c.NextSibling {
+		sb.WriteString(textContent(c))
+	}
+
+	return sb.String()
+}
+
+func attr(node *html.Node, key string) string {
+	for _, a := range node.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+
+	return ""
+}
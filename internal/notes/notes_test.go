@@ -0,0 +1,74 @@
+//go:build unit
+
+package notes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sampleNotes = `<h2>What's Changed</h2>
+<ul>
+<li>Fix <code>foo_bar</code> in <a href="https://github.com/x/y/pull/1">#1</a> by <strong>@alice</strong></li>
+<li>Nested
+<ul><li>child *one*</li></ul>
+</li>
+</ul>
+<p>Some <em>text</em> &amp; more<br>new line</p>
+<pre><code>go install x@v1
+</code></pre>`
+
+func TestToText(t *testing.T) {
+	expected := "WHAT'S CHANGED\n\n- Fix foo_bar in #1 (https://github.com/x/y/pull/1) by @alice\n- Nested\n  - child *one*\n\n" +
+		"Some text & more\nnew line\n\ngo install x@v1"
+	assert.Equal(t, expected, ToText(sampleNotes))
+	assert.Equal(t, "", ToText("  "))
+}
+
+func TestToMarkdown(t *testing.T) {
+	expected := "**What's Changed**\n\n- Fix `foo_bar` in [#1](https://github.com/x/y/pull/1) by **@alice**\n- Nested\n  - child \\*one\\*\n\n" +
+		"Some _text_ & more\nnew line\n\n```\ngo install x@v1\n```"
+	assert.Equal(t, expected, ToMarkdown(sampleNotes))
+}
+
+func TestToSlack(t *testing.T) {
+	expected := "*What's Changed*\n\n• Fix `foo_bar` in <https://github.com/x/y/pull/1|#1> by *@alice*\n• Nested\n  • child *one*\n\n" +
+		"Some _text_ &amp; more\nnew line\n\n```\ngo install x@v1\n```"
+	assert.Equal(t, expected, ToSlack(sampleNotes))
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "hello world", Truncate("hello world", 20))
+	assert.Equal(t, "hello…", Truncate("hello world", 7))
+	assert.Equal(t, "héll…", Truncate("héllo wörld", 5))
+	assert.Equal(t, "hello world", Truncate("hello world", 0))
+}
+
+func TestTruncateBytes(t *testing.T) {
+	assert.Equal(t, "hello world", TruncateBytes("hello world", 20))
+	assert.Equal(t, "hello…", TruncateBytes("hello world", 9))
+	// ö is 2 bytes, it is not split
+	assert.Equal(t, "héllo w…", TruncateBytes("héllo wörld", 12))
+	assert.Equal(t, "hello world", TruncateBytes("hello world", 0))
+}
+
+func TestToHTML(t *testing.T) {
+	expected := "<strong>What&#39;s Changed</strong>\n\n• Fix <code>foo_bar</code> in <a href=\"https://github.com/x/y/pull/1\">#1</a> by " +
+		"<strong>@alice</strong>\n• Nested\n  • child *one*\n\nSome <em>text</em> &amp; more\nnew line\n\n<pre>go install x@v1</pre>"
+	assert.Equal(t, expected, ToHTML(sampleNotes))
+	assert.Equal(t, "click &lt;me&gt;", ToHTML(`<script>alert(1)</script><a href="javascript:alert(1)">click &lt;me&gt;</a>`))
+}
+
+func TestToTelegramHTML(t *testing.T) {
+	expected := "<b>What&#39;s Changed</b>\n\n• Fix <code>foo_bar</code> in <a href=\"https://github.com/x/y/pull/1\">#1</a> by <b>@alice</b>\n" +
+		"• Nested\n  • child *one*\n\nSome <i>text</i> &amp; more\nnew line\n\n<pre>go install x@v1</pre>"
+	assert.Equal(t, expected, ToTelegramHTML(sampleNotes))
+}
+
+func TestToTelegramMarkdown(t *testing.T) {
+	expected := "*What's Changed*\n\n• Fix `foo_bar` in [\\#1](https://github.com/x/y/pull/1) by *@alice*\n" +
+		"• Nested\n  • child \\*one\\*\n\nSome _text_ & more\nnew line\n\n```\ngo install x@v1\n```"
+	assert.Equal(t, expected, ToTelegramMarkdown(sampleNotes))
+	assert.Equal(t, "1\\. v1\\.0\\.0 \\(beta\\)\\!", EscapeTelegramMarkdown("1. v1.0.0 (beta)!"))
+}
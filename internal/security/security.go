@@ -0,0 +1,56 @@
+package security
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/types"
+)
+
+var (
+	cveRegex     = regexp.MustCompile(`(?i)\bCVE-\d{4}-\d{4,}\b`)
+	ghsaRegex    = regexp.MustCompile(`(?i)\bGHSA(?:-[23456789cfghjmpqrvwx]{4}){3}\b`)
+	keywordRegex = regexp.MustCompile(`(?i)\b(security|vulnerabilit(?:y|ies)|vulnerable)\b`)
+)
+
+// Detect scans the given texts, like release title and notes, for CVE identifiers, GHSA identifiers and security
+// related keywords. It returns nil if nothing security related is found.
+func Detect(texts ...string) *types.Security {
+	var result types.Security
+	for _, text := range texts {
+		result.CVEs = appendUnique(result.CVEs, cveRegex.FindAllString(text, -1), strings.ToUpper)
+		result.GHSAs = appendUnique(result.GHSAs, ghsaRegex.FindAllString(text, -1), normalizeGHSA)
+		result.Keywords = appendUnique(result.Keywords, keywordRegex.FindAllString(text, -1), strings.ToLower)
+	}
+
+	if len(result.CVEs) == 0 && len(result.GHSAs) == 0 && len(result.Keywords) == 0 {
+		return nil
+	}
+
+	return &result
+}
+
+// normalizeGHSA converts the GHSA identifier into its canonical form like GHSA-xxxx-xxxx-xxxx
+func normalizeGHSA(id string) string {
+	return "GHSA" + strings.ToLower(id[4:])
+}
+
+func appendUnique(items, candidates []string, normalize func(string) string) []string {
+	for _, candidate := range candidates {
+		candidate = normalize(candidate)
+
+		exists := false
+		for _, item := range items {
+			if item == candidate {
+				exists = true
+				break
+			}
+		}
+
+		if !exists {
+			items = append(items, candidate)
+		}
+	}
+
+	return items
+}
@@ -0,0 +1,48 @@
+//go:build unit
+
+package security
+
+import (
+	"testing"
+
+	"github.com/bilalcaliskan/rss-feed-filterer/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetect(t *testing.T) {
+	cases := []struct {
+		caseName string
+		texts    []string
+		expected *types.Security
+	}{
+		{
+			"No security findings",
+			[]string{"v1.0.0", "<p>Bug fixes and performance improvements</p>"},
+			nil,
+		},
+		{
+			"CVE, GHSA and keywords",
+			[]string{
+				"v1.0.1 Security release",
+				"<p>Fixes cve-2023-12345 (GHSA-jfh8-c2jp-5v3q) and CVE-2023-12345, see the Vulnerability report</p>",
+			},
+			&types.Security{
+				CVEs:     []string{"CVE-2023-12345"},
+				GHSAs:    []string{"GHSA-jfh8-c2jp-5v3q"},
+				Keywords: []string{"security", "vulnerability"},
+			},
+		},
+		{
+			"Only keywords",
+			[]string{"v1.0.2", "This release fixes multiple vulnerabilities"},
+			&types.Security{
+				Keywords: []string{"vulnerabilities"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Logf("starting case %s", tc.caseName)
+		assert.Equal(t, tc.expected, Detect(tc.texts...))
+	}
+}
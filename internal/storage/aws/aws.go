@@ -16,12 +16,15 @@ import (
 	internaltypes "github.com/bilalcaliskan/rss-feed-filterer/internal/types"
 )
 
+// CreateConfig creates the aws config of the region, the default credential chain is used if accessKey is empty
 func CreateConfig(accessKey, secretKey, region string) (aws.Config, error) {
-	appCreds := aws.NewCredentialsCache(credentials.NewStaticCredentialsProvider(accessKey, secretKey, ""))
-	return config.LoadDefaultConfig(context.Background(),
-		config.WithRegion(region),
-		config.WithCredentialsProvider(appCreds),
-	)
+	opts := []func(*config.LoadOptions) error{config.WithRegion(region)}
+	if accessKey != "" {
+		appCreds := aws.NewCredentialsCache(credentials.NewStaticCredentialsProvider(accessKey, secretKey, ""))
+		opts = append(opts, config.WithCredentialsProvider(appCreds))
+	}
+
+	return config.LoadDefaultConfig(context.Background(), opts...)
 }
 
 func CreateClient(accessKey, secretKey, region string) (*s3.Client, error) {
@@ -98,7 +101,27 @@ func IsBucketExists(client S3ClientAPI, bucketName string) bool {
 }
 
 func PutReleases(client S3ClientAPI, bucketName, key string, releases []internaltypes.Release) error {
-	data, err := json.MarshalIndent(&releases, "", "    ")
+	return PutObject(client, bucketName, key, &releases)
+}
+
+// GetObject decodes the JSON object in the bucket into v
+func GetObject(client S3ClientAPI, bucketName, key string, v any) error {
+	getResult, err := client.GetObject(context.Background(), &s3.GetObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(key),
+	})
+
+	if err != nil {
+		return err
+	}
+	defer getResult.Body.Close()
+
+	return json.NewDecoder(getResult.Body).Decode(v)
+}
+
+// PutObject encodes v as JSON and puts it into the bucket
+func PutObject(client S3ClientAPI, bucketName, key string, v any) error {
+	data, err := json.MarshalIndent(v, "", "    ")
 	if err != nil {
 		return err
 	}
@@ -2,10 +2,75 @@ package types
 
 import "time"
 
+// EventType is the kind of change detected on a release between two checks
+type EventType string
+
+const (
+	// EventNew means that the release was not seen before
+	EventNew EventType = "new"
+	// EventUpdated means that the release was seen before but some of its fields changed
+	EventUpdated EventType = "updated"
+	// EventRemoved means that the release was seen before but disappeared from the feed
+	EventRemoved EventType = "removed"
+)
+
 type Release struct {
 	ProjectName string     `json:"projectName"`
 	Version     string     `json:"version"`
 	PublishedAt *time.Time `json:"publishedAt"`
 	UpdatedAt   *time.Time `json:"updatedAt"`
 	Url         string     `json:"url"`
+	// Notes is the release notes in HTML format
+	Notes string `json:"notes,omitempty"`
+	// Security holds the security findings of the release, it is nil if the release is not security related
+	Security *Security `json:"security,omitempty"`
+}
+
+// Security holds the security related findings detected in the title and notes of a release
+type Security struct {
+	CVEs     []string `json:"cves,omitempty"`
+	GHSAs    []string `json:"ghsas,omitempty"`
+	Keywords []string `json:"keywords,omitempty"`
+}
+
+// ReleaseEvent is a change detected on a release, Changes contains the names of the changed fields for updated releases
+type ReleaseEvent struct {
+	Release
+	Type    EventType
+	Changes []string
+}
+
+// IsSameVersion checks if both releases point to the same version of the same project, the releases with the same
+// link are also the same since the versions were the titles of the releases in the previous versions
+func (r Release) IsSameVersion(other Release) bool {
+	return r.ProjectName == other.ProjectName && (r.Version == other.Version || r.Url != "" && r.Url == other.Url)
+}
+
+// ChangedFields returns the names of the fields which differ between two versions of the same release. The notes are
+// only compared if both have them, the releases stored by the previous versions have no notes and the notes fetched
+// from the api are not in the feed. The titles are not compared on purpose, they are often renamed by the maintainers
+// and the version is taken from the link.
+func (r Release) ChangedFields(other Release) []string {
+	var changes []string
+	if r.Url != other.Url {
+		changes = append(changes, "url")
+	}
+
+	if !isSameTime(r.PublishedAt, other.PublishedAt) {
+		changes = append(changes, "publishedAt")
+	}
+
+	if !isSameTime(r.UpdatedAt, other.UpdatedAt) {
+		changes = append(changes, "updatedAt")
+	}
+
+	if r.Notes != "" && other.Notes != "" && r.Notes != other.Notes {
+		changes = append(changes, "notes")
+	}
+
+	return changes
+}
+
+func isSameTime(first, second *time.Time) bool {
+	return first == nil && second == nil || first != nil && second != nil && first.Equal(*second)
 }